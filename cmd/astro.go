@@ -0,0 +1,114 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// Most days astro calculates at once.
+const maxAstroDays = 366
+
+// astroCmd represents the astro command.
+var (
+	astroCmd = &cobra.Command{
+		Use:   "astro",
+		Short: "Shows the times of the sun and the moon",
+		Long: `Shows twilight, sunrise, solar noon, sunset, the golden and blue hours and the
+rise, set and phase of the moon for any days. The times are calculated locally,
+only the location of a zip code is looked up once and then cached, so it works
+offline and far beyond the forecast.
+
+The golden hour is when the sun is between -4° and 6°, the blue hour when it is
+between -6° and -4°.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loc, err := astroLocation()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now().In(loc)
+
+			from := now
+			if astroFrom != "" {
+				from, err = time.ParseInLocation("2006-01-02", astroFrom, loc)
+				if err != nil {
+					return fmt.Errorf("invalid date %q, expected e.g. 2023-05-01", astroFrom)
+				}
+			}
+
+			if astroDays < 1 || astroDays > maxAstroDays {
+				return fmt.Errorf("--days must be between 1 and %d", maxAstroDays)
+			}
+
+			l, err := locateCached(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			a := report.NewAstro(zip, l, from, astroDays)
+
+			if output == "json" {
+				return printer.PrintJSON(a)
+			}
+
+			printer.PrintAstro(a)
+
+			return nil
+		},
+	}
+	astroFrom string
+	astroDays int
+	astroTZ   string
+)
+
+func init() {
+	rootCmd.AddCommand(astroCmd)
+
+	astroCmd.Flags().StringVar(&astroFrom, "from", "", "First day, e.g. 2023-05-01 (default today)")
+	astroCmd.Flags().IntVar(&astroDays, "days", 1, "Number of days")
+	astroCmd.Flags().StringVar(&astroTZ, "tz", "",
+		"Time zone of the times (default Europe/Zurich for zip codes, the local one for coordinates)")
+}
+
+// Returns the time zone of the times.
+func astroLocation() (*time.Location, error) {
+	name := astroTZ
+
+	switch {
+	case name == "" && zip != "":
+		return weather.Zurich, nil
+	case name == "":
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %w", name, err)
+	}
+
+	return loc, nil
+}
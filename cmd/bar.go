@@ -0,0 +1,62 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/darox/sunly/internal/bar"
+	"github.com/spf13/cobra"
+)
+
+// barCmd represents the bar command.
+var (
+	barCmd = &cobra.Command{
+		Use:   "bar",
+		Short: "Prints the weather for a status bar",
+		Long: `Prints the weather in the format a status bar expects, with the forecast as
+tooltip and a class or color based on the condition and the warning level.
+
+Supported formats: ` + strings.Join(bar.Formats, ", "),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w, locationName, err := lookup(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			s, err := bar.Render(barFormat, bar.NewStatus(locationName, w, activeTheme))
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(s)
+
+			return nil
+		},
+	}
+	barFormat string
+)
+
+func init() {
+	rootCmd.AddCommand(barCmd)
+
+	barCmd.Flags().StringVar(&barFormat, "format", bar.FormatWaybar, "Status bar format")
+}
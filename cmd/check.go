@@ -0,0 +1,136 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command.
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks the alert rules of the config file",
+	Long: `Checks the alert rules of the config file against the forecast and prints the
+rules whose condition is met. Exits with code 2 if any rule triggered, so it
+can be used in scripts and cron jobs.
+
+Rules without a zip code use the one given by --zip.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := activeConfig
+
+		if len(c.Rules) == 0 {
+			return errors.New("no rules defined in the config file")
+		}
+
+		err := validateRules(c.Rules)
+		if err != nil {
+			return err
+		}
+
+		triggers, err := checkRules(cmd.Context(), activeProvider, c.Rules, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			err = printer.PrintJSON(triggers)
+			if err != nil {
+				return err
+			}
+		} else if len(triggers) > 0 {
+			printer.PrintTriggers(triggers)
+		}
+
+		if len(triggers) > 0 {
+			return &exitError{
+				code: exitCodeTriggered,
+				err:  fmt.Errorf("%d of %d rules triggered", len(triggers), len(c.Rules)),
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
+
+// Checks that the rules are valid and that all of them have a zip code.
+func validateRules(rs []rules.Rule) error {
+	for _, r := range rs {
+		err := r.Validate()
+		if err != nil {
+			return err
+		}
+
+		if ruleZip(r) == "" {
+			return fmt.Errorf("rule %q has no zip code, please provide one with --zip", r.Name)
+		}
+	}
+
+	return nil
+}
+
+// Evaluates the rules, fetching the weather of each zip code only once from
+// the provider.
+func checkRules(ctx context.Context, p weather.Provider, rs []rules.Rule, now time.Time) ([]rules.Trigger, error) {
+	byZip := map[string]*weather.Weather{}
+	triggers := []rules.Trigger{}
+
+	for _, r := range rs {
+		z := ruleZip(r)
+
+		w, ok := byZip[z]
+		if !ok {
+			var err error
+
+			w, _, err = lookupWith(ctx, p, z)
+			if err != nil {
+				return nil, err
+			}
+
+			byZip[z] = w
+		}
+
+		if t := r.Evaluate(z, w, now); t != nil {
+			triggers = append(triggers, *t)
+		}
+	}
+
+	return triggers, nil
+}
+
+// Returns the zip code of the rule, or the one of --zip if it has none.
+func ruleZip(r rules.Rule) string {
+	if r.Zip == "" {
+		return zip
+	}
+
+	return r.Zip
+}
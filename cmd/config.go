@@ -0,0 +1,40 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import "github.com/darox/sunly/internal/config"
+
+// Config loaded by setup before any command runs.
+var activeConfig = &config.Config{}
+
+// Loads the config file given by --config or the default one.
+func loadConfig() (*config.Config, error) {
+	path := cfgFile
+
+	if path == "" {
+		var err error
+
+		path, err = config.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config.Load(path)
+}
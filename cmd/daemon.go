@@ -0,0 +1,374 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/daemon"
+	"github.com/darox/sunly/internal/prompt"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// Types of jobs the daemon can run.
+const (
+	jobRefresh = "refresh"
+	jobCheck   = "check"
+	jobNotify  = "notify"
+	jobWebhook = "webhook"
+	jobRecord  = "record"
+)
+
+// daemonCmd represents the daemon command.
+var (
+	daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: "Runs the jobs of the config file on their schedules",
+		Long: `Runs the jobs of the config file on their schedules in a single long lived
+process. The job types are:
+
+  refresh  fetches the weather of the zip codes and updates the prompt state
+  check    evaluates alert rules and logs the triggered ones
+  notify   evaluates alert rules and sends the triggered ones to the notifiers
+  webhook  posts the current weather of the zip codes as JSON to a URL
+  record   records the current weather of the zip codes in the history
+
+SIGHUP reloads the config file, SIGINT and SIGTERM stop the daemon after the
+running jobs finished.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := newLogger()
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			defer signal.Stop(hup)
+
+			reload := make(chan struct{})
+
+			go func() {
+				for {
+					select {
+					case <-hup:
+						logger.Info("received SIGHUP, reloading the config")
+
+						select {
+						case reload <- struct{}{}:
+						case <-ctx.Done():
+							return
+						}
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+
+			// The config loaded by setup is used on start, every further load
+			// is a reload
+			reloading := false
+
+			d := daemon.New(func() ([]daemon.Job, error) {
+				jobs, err := loadJobs(logger, reloading)
+				reloading = true
+
+				return jobs, err
+			}, logger)
+
+			return d.Run(ctx, reload)
+		},
+	}
+	logFormat string
+	logLevel  string
+)
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&logFormat, "log-format", "text", "Log format (text or json)")
+	daemonCmd.Flags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn or error)")
+	daemonCmd.Flags().StringVar(&historyDir, "history-dir", "", "Directory of the history of record jobs (default is ~/.local/share/sunly/history)")
+}
+
+// Returns the logger configured by the flags, writing to stderr.
+func newLogger() (*slog.Logger, error) {
+	var level slog.Level
+
+	err := level.UnmarshalText([]byte(logLevel))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q", logLevel)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch logFormat {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", logFormat)
+	}
+}
+
+// Creates the jobs of the config and the provider set up by setup. A reload
+// reads the config file again and creates its provider. The jobs get the config
+// and the provider they were built with, so running jobs keep theirs while the
+// next ones are loaded.
+func loadJobs(logger *slog.Logger, reload bool) ([]daemon.Job, error) {
+	c, p := activeConfig, activeProvider
+
+	if reload {
+		var err error
+
+		c, err = loadConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		// The provider may have changed as well
+		p, err = newProvider(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	jobs := []daemon.Job{}
+
+	for _, jc := range c.Jobs {
+		j, err := newJob(c, p, jc, logger.With("job", jc.Name))
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", jc.Name, err)
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	if len(jobs) == 0 {
+		logger.Warn("no jobs defined in the config file")
+	}
+
+	return jobs, nil
+}
+
+// Creates the job described by the config, which fetches the weather from the
+// provider.
+func newJob(c *config.Config, p weather.Provider, jc config.Job, logger *slog.Logger) (daemon.Job, error) {
+	s, err := daemon.ParseSchedule(jc.Schedule)
+	if err != nil {
+		return daemon.Job{}, err
+	}
+
+	j := daemon.Job{Name: jc.Name, Schedule: s}
+
+	switch jc.Type {
+	case jobRefresh:
+		if len(jc.Zips) == 0 {
+			return daemon.Job{}, errors.New("no zip codes given")
+		}
+
+		j.Run = func(ctx context.Context) error {
+			return runRefreshJob(ctx, p, jc.Zips)
+		}
+	case jobRecord:
+		if len(jc.Zips) == 0 {
+			return daemon.Job{}, errors.New("no zip codes given")
+		}
+
+		s, err := historyStore()
+		if err != nil {
+			return daemon.Job{}, err
+		}
+
+		s.Logger = logger
+
+		j.Run = func(ctx context.Context) error {
+			recorded, err := recordWeather(ctx, p, s, jc.Zips)
+			for _, o := range recorded {
+				logger.Info("recorded", "zip", o.Zip, "temperature", o.Temperature, "time", o.Time)
+			}
+
+			return err
+		}
+	case jobCheck:
+		rs, err := selectRules(c.Rules, jc.Rules)
+		if err != nil {
+			return daemon.Job{}, err
+		}
+
+		j.Run = func(ctx context.Context) error {
+			return runCheckJob(ctx, p, rs, logger)
+		}
+	case jobNotify:
+		rs, err := selectRules(c.Rules, jc.Rules)
+		if err != nil {
+			return daemon.Job{}, err
+		}
+
+		j.Run = func(ctx context.Context) error {
+			triggers, err := notifyRules(ctx, p, c.Notifiers, rs, jc.RepeatAfter)
+			for _, t := range triggers {
+				logger.Info("notification sent", "rule", t.Name, "zip", t.Zip, "value", t.Value)
+			}
+
+			return err
+		}
+	case jobWebhook:
+		if len(jc.Zips) == 0 || jc.URL == "" {
+			return daemon.Job{}, errors.New("zip codes and url are required")
+		}
+
+		client := &http.Client{Timeout: 5 * time.Second}
+
+		j.Run = func(ctx context.Context) error {
+			return runWebhookJob(ctx, client, p, jc.URL, jc.Zips)
+		}
+	default:
+		return daemon.Job{}, fmt.Errorf("unknown job type %q", jc.Type)
+	}
+
+	return j, nil
+}
+
+// Returns the rules with the given names, all rules if no names are given.
+func selectRules(all []rules.Rule, names []string) ([]rules.Rule, error) {
+	if len(names) == 0 {
+		if len(all) == 0 {
+			return nil, errors.New("no rules defined in the config file")
+		}
+
+		return all, validateRules(all)
+	}
+
+	selected := []rules.Rule{}
+
+	for _, n := range names {
+		found := false
+
+		for _, r := range all {
+			if r.Name == n {
+				selected = append(selected, r)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown rule %q", n)
+		}
+	}
+
+	return selected, validateRules(selected)
+}
+
+// Logs the triggered rules.
+func runCheckJob(ctx context.Context, p weather.Provider, rs []rules.Rule, logger *slog.Logger) error {
+	triggers, err := checkRules(ctx, p, rs, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, t := range triggers {
+		logger.Warn("rule triggered", "rule", t.Name, "zip", t.Zip, "condition", t.Condition,
+			"time", t.Time, "value", t.Value)
+	}
+
+	return nil
+}
+
+// Fetches the weather of the zip codes and updates their prompt state.
+func runRefreshJob(ctx context.Context, p weather.Provider, zips []string) error {
+	var errs []error
+
+	for _, z := range zips {
+		w, locationName, err := lookupWith(ctx, p, z)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", z, err))
+			continue
+		}
+
+		path, err := promptStatePath(z)
+		if err != nil {
+			return err
+		}
+
+		err = prompt.Save(path, prompt.NewState(z, locationName, w, time.Now()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", z, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Posts the current weather of each zip code to the URL.
+func runWebhookJob(ctx context.Context, client *http.Client, p weather.Provider, url string, zips []string) error {
+	var errs []error
+
+	for _, z := range zips {
+		w, locationName, err := lookupWith(ctx, p, z)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", z, err))
+			continue
+		}
+
+		b, err := json.Marshal(report.NewCurrent(z, locationName, w, time.Now(), weather.DefaultMaxAge))
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := client.Do(req)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: error posting the weather: %w", z, err))
+			continue
+		}
+
+		res.Body.Close()
+
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			errs = append(errs, fmt.Errorf("%s: unexpected status code %d", z, res.StatusCode))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/darox/sunly/internal/config"
+)
+
+func TestReloadKeepsActiveProvider(t *testing.T) {
+	err := setupProvider(&config.Config{Provider: "fake"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	p, c := activeProvider, activeConfig
+
+	path := filepath.Join(t.TempDir(), "sunly.yaml")
+
+	err = os.WriteFile(path, []byte(`provider: openmeteo
+jobs:
+  - name: refresh
+    schedule: "@hourly"
+    type: refresh
+    zips: ["3006"]
+`), 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	cfgFile = path
+	t.Cleanup(func() { cfgFile = "" })
+
+	jobs, err := loadJobs(slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].Name != "refresh" {
+		t.Errorf("Expected the refresh job of the reloaded config, but got %+v", jobs)
+	}
+
+	if activeProvider != p || activeConfig != c {
+		t.Errorf("Expected a reload to leave the provider and the config of running jobs alone")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/dashboard"
+	"github.com/spf13/cobra"
+)
+
+// dashboardCmd represents the dashboard command.
+var (
+	dashboardCmd = &cobra.Command{
+		Use:   "dashboard",
+		Short: "Shows an interactive dashboard with the weather of the saved places",
+		Long: `Shows an interactive dashboard with the weather of the places saved in the
+config file and the one given by --zip. Use the arrow keys or the numbers to
+switch between the places, r to refresh and q to quit.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := activeConfig
+
+			places := c.Places
+			if zip != "" {
+				places = append([]config.Place{{Zip: zip}}, places...)
+			}
+
+			if len(places) == 0 {
+				return errors.New("please provide a zip code or save places in the config file")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			d := dashboard.New(places, lookup, dashboardRefresh)
+
+			return d.Run(ctx, os.Stdin, os.Stdout)
+		},
+	}
+	dashboardRefresh time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+
+	dashboardCmd.Flags().DurationVar(&dashboardRefresh, "refresh", 10*time.Minute, "How often the data is refreshed")
+}
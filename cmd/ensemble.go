@@ -0,0 +1,168 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/ensemble"
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+)
+
+// ensembleSource is a provider taking part in the ensemble.
+type ensembleSource struct {
+	name     string
+	provider weather.Provider
+}
+
+// Returns the selected provider followed by the sources of the config file,
+// or by all other registered providers if the config file has none.
+func ensembleSources(c *config.Config) ([]ensembleSource, error) {
+	primary := selectedProvider(c)
+	sources := []ensembleSource{{name: primary, provider: activeProvider}}
+
+	configured := c.Ensemble
+	if len(configured) == 0 {
+		for _, name := range weather.Providers() {
+			if name != primary {
+				configured = append(configured, config.Source{Provider: name})
+			}
+		}
+	}
+
+	names := map[string]bool{primary: true}
+
+	for _, s := range configured {
+		name := s.Name
+		if name == "" {
+			name = s.Provider
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("duplicate ensemble source %q, give it a name", name)
+		}
+
+		names[name] = true
+
+		pc := s.Config
+		if pc == (weather.Config{}) {
+			pc = c.Providers[s.Provider]
+		}
+
+		p, err := weather.New(s.Provider, pc)
+		if err != nil {
+			return nil, fmt.Errorf("error creating ensemble source %q: %w", name, err)
+		}
+
+		sources = append(sources, ensembleSource{name: name, provider: p})
+	}
+
+	if len(sources) < 2 {
+		return nil, errors.New("the ensemble needs at least one source besides the selected provider")
+	}
+
+	return sources, nil
+}
+
+// Fetches the forecast of all sources concurrently and combines them. Sources
+// that fail are left out and returned with their error, it only fails if all
+// of them do.
+func fetchEnsemble(ctx context.Context, sources []ensembleSource, l weather.Location) (ensemble.Ensemble, map[string]string, error) {
+	members := make([]*weather.Weather, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+
+	for i, s := range sources {
+		wg.Add(1)
+
+		go func(i int, s ensembleSource) {
+			defer wg.Done()
+
+			members[i], errs[i] = s.provider.Weather(ctx, l)
+		}(i, s)
+	}
+
+	wg.Wait()
+
+	var ms []ensemble.Member
+
+	failed := map[string]string{}
+
+	for i, s := range sources {
+		if errs[i] != nil {
+			failed[s.name] = errs[i].Error()
+			continue
+		}
+
+		ms = append(ms, ensemble.Member{Source: s.name, Weather: members[i]})
+	}
+
+	if len(ms) == 0 {
+		msgs := make([]string, 0, len(failed))
+		for name, err := range failed {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", name, err))
+		}
+
+		return ensemble.Ensemble{}, nil, fmt.Errorf("all ensemble sources failed: %s", strings.Join(msgs, "; "))
+	}
+
+	if len(failed) == 0 {
+		failed = nil
+	}
+
+	return ensemble.New(ms), failed, nil
+}
+
+// Renders the ensemble forecast in the selected output format. It returns the
+// latest update of the sources for the watch mode.
+func renderEnsemble(ctx context.Context, zip string) (string, time.Time, error) {
+	sources, err := ensembleSources(activeConfig)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	l, err := locate(ctx, zip)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	e, failed, err := fetchEnsemble(ctx, sources, l)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	r := report.NewEnsemble(zip, l.Name, e, failed, time.Now())
+
+	if output == "json" {
+		s, err := printer.RenderJSON(r)
+
+		return s, e.UpdatedAt, err
+	}
+
+	return printer.RenderEnsemble(r), e.UpdatedAt, nil
+}
@@ -0,0 +1,86 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/darox/sunly/internal/exporter"
+	"github.com/darox/sunly/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// exporterCmd represents the exporter command.
+var (
+	exporterCmd = &cobra.Command{
+		Use:   "exporter",
+		Short: "Serves the weather of one or more locations as Prometheus metrics",
+		Long: `Serves the weather of one or more locations as Prometheus metrics on /metrics.
+Multiple postal codes are separated by commas, e.g. --zip 3006,8001.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zips := splitZips(zip)
+			if len(zips) == 0 {
+				return errors.New("please provide at least one zip code")
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", exporter.New(server.Upstream{Provider: activeProvider}, zips, exporterCacheTTL))
+
+			// Shut down gracefully on Ctrl-C and when being stopped
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics\n", exporterListen)
+
+			return server.Serve(ctx, exporterListen, mux)
+		},
+	}
+	exporterListen   string
+	exporterCacheTTL time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", ":9712", "Address to listen on")
+	exporterCmd.Flags().DurationVar(&exporterCacheTTL, "cache-ttl", 5*time.Minute, "How long fetched data is reused")
+}
+
+// Splits a comma separated list of zip codes.
+func splitZips(s string) []string {
+	zips := []string{}
+
+	for _, z := range strings.Split(s, ",") {
+		z = strings.TrimSpace(z)
+		if z != "" {
+			zips = append(zips, z)
+		}
+	}
+
+	return zips
+}
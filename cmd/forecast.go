@@ -0,0 +1,118 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/watch"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// forecastCmd represents the forecast command.
+var forecastCmd = &cobra.Command{
+	Use:          "forecast",
+	Short:        "Returns the daily forecast of a location by providing a postal code",
+	Long:         `Returns the daily forecast of a location by providing a postal code`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if forecastEnsemble {
+			return runEnsemble(cmd)
+		}
+
+		if watchEnabled {
+			return runWatch(cmd, func(ctx context.Context) (watch.Frame, error) {
+				s, w, err := renderForecast(ctx, zip)
+				if err != nil {
+					return watch.Frame{}, err
+				}
+
+				return watch.Frame{Text: s, UpdatedAt: w.UpdatedAt}, nil
+			})
+		}
+
+		s, _, err := renderForecast(cmd.Context(), zip)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(s)
+
+		return nil
+	},
+}
+
+// Whether to combine the forecasts of several providers.
+var forecastEnsemble bool
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+
+	forecastCmd.Flags().BoolVar(&forecastEnsemble, "ensemble", false,
+		"Compare the forecast of the selected provider with other sources")
+
+	addWatchFlags(forecastCmd)
+	addSparkFlag(forecastCmd)
+}
+
+// Prints the ensemble forecast, once or in watch mode.
+func runEnsemble(cmd *cobra.Command) error {
+	if watchEnabled {
+		return runWatch(cmd, func(ctx context.Context) (watch.Frame, error) {
+			s, updatedAt, err := renderEnsemble(ctx, zip)
+			if err != nil {
+				return watch.Frame{}, err
+			}
+
+			return watch.Frame{Text: s, UpdatedAt: updatedAt}, nil
+		})
+	}
+
+	s, _, err := renderEnsemble(cmd.Context(), zip)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(s)
+
+	return nil
+}
+
+// Renders the daily forecast in the selected output format.
+func renderForecast(ctx context.Context, zip string) (string, *weather.Weather, error) {
+	w, locationName, err := lookup(ctx, zip)
+	if err != nil {
+		return "", nil, err
+	}
+
+	f := report.NewForecast(zip, locationName, w)
+
+	if output == "json" {
+		var s string
+		s, err = printer.RenderJSON(f)
+
+		return s, w, err
+	}
+
+	return printer.RenderForecast(f, w.Hours), w, nil
+}
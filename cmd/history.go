@@ -0,0 +1,117 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command.
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history",
+		Short: "Shows the recorded weather of a location",
+		Long: `Shows the weather recorded by sunly record, either every observation or
+aggregated by day with --daily. Besides table and json, the output can be csv
+to export the history. The history is kept by zip code, so --lat and --lon are
+not supported.
+
+Example:
+  sunly history --zip 3006 --since 7d --daily -o csv`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if zip == "" {
+				return errZipRequired()
+			}
+
+			now := time.Now().In(weather.Zurich)
+
+			since, err := history.ParseSince(historySince, now)
+			if err != nil {
+				return err
+			}
+
+			var until time.Time
+
+			if historyUntil != "" {
+				until, err = history.ParseSince(historyUntil, now)
+				if err != nil {
+					return err
+				}
+			}
+
+			s, err := historyStore()
+			if err != nil {
+				return err
+			}
+
+			obs, err := s.Query(zip, since, until)
+			if err != nil {
+				return err
+			}
+
+			if historyDaily {
+				r := report.NewHistoryDays(zip, obs, weather.Zurich)
+
+				switch output {
+				case "json":
+					return printer.PrintJSON(r)
+				case "csv":
+					return history.WriteDailyCSV(os.Stdout, r.Days)
+				}
+
+				printer.PrintHistoryDays(zip, r.Days)
+
+				return nil
+			}
+
+			r := report.NewHistory(zip, obs)
+
+			switch output {
+			case "json":
+				return printer.PrintJSON(r)
+			case "csv":
+				return history.WriteCSV(os.Stdout, r.Observations)
+			}
+
+			printer.PrintHistory(zip, r.Observations)
+
+			return nil
+		},
+	}
+	historySince string
+	historyUntil string
+	historyDaily bool
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historySince, "since", "7d", "Start of the history, e.g. 7d, 12h or 2023-05-01")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "End of the history, in the same format as --since")
+	historyCmd.Flags().BoolVar(&historyDaily, "daily", false, "Aggregate the history by day")
+	historyCmd.Flags().StringVar(&historyDir, "history-dir", "", "Directory of the history (default is ~/.local/share/sunly/history)")
+}
@@ -0,0 +1,107 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/watch"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// hourlyCmd represents the hourly command.
+var (
+	hourlyCmd = &cobra.Command{
+		Use:          "hourly",
+		Short:        "Returns the hourly forecast of a location by providing a postal code",
+		Long:         `Returns the hourly forecast of a location by providing a postal code`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watchEnabled {
+				return runWatch(cmd, func(ctx context.Context) (watch.Frame, error) {
+					s, w, err := renderHourly(ctx, zip)
+					if err != nil {
+						return watch.Frame{}, err
+					}
+
+					return watch.Frame{Text: s, UpdatedAt: w.UpdatedAt}, nil
+				})
+			}
+
+			s, _, err := renderHourly(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(s)
+
+			return nil
+		},
+	}
+	hours      int
+	chart      bool
+	shadeNight bool
+)
+
+func init() {
+	rootCmd.AddCommand(hourlyCmd)
+
+	hourlyCmd.Flags().IntVar(&hours, "hours", 24, "Number of hours to show, 0 shows all")
+	hourlyCmd.Flags().BoolVar(&chart, "chart", false, "Draw the hours as charts instead of a table")
+	hourlyCmd.Flags().BoolVar(&shadeNight, "night", false, "Shade the hours between sunset and sunrise in the charts")
+	addWatchFlags(hourlyCmd)
+	addDerivedFlag(hourlyCmd)
+}
+
+// Renders the hourly forecast in the selected output format.
+func renderHourly(ctx context.Context, zip string) (string, *weather.Weather, error) {
+	w, locationName, err := lookup(ctx, zip)
+	if err != nil {
+		return "", nil, err
+	}
+
+	h := report.NewHourly(zip, locationName, w, time.Now())
+
+	// Only show the requested number of hours
+	if hours > 0 && len(h.Hours) > hours {
+		h.Hours = h.Hours[:hours]
+	}
+
+	if output == "json" {
+		var s string
+		s, err = printer.RenderJSON(h)
+
+		return s, w, err
+	}
+
+	if chart {
+		o := printer.DefaultChartOptions(terminalWidth())
+		o.ShadeNight = shadeNight
+		o.Sunrise, o.Sunset = w.Sunrise, w.Sunset
+
+		return printer.RenderChart(h, o), w, nil
+	}
+
+	return printer.RenderHourly(h), w, nil
+}
@@ -0,0 +1,97 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/darox/sunly/internal/ics"
+	"github.com/darox/sunly/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// icsCmd represents the ics command.
+var (
+	icsCmd = &cobra.Command{
+		Use:   "ics",
+		Short: "Exports the forecast, sunrise, sunset and warnings as calendar",
+		Long: `Exports the daily forecast as all day events, the sunrise and sunset and the
+weather warnings with alarms as iCalendar.
+
+With --listen the calendars are served as feeds that calendar apps can subscribe
+to on /<zip>.ics. Multiple postal codes are separated by commas, e.g.
+--zip 3006,8001.`,
+		Example:      "sunly ics --zip 3006 > bern.ics\nsunly ics --zip 3006,8001 --listen :8080",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if icsListen != "" {
+				return serveICS()
+			}
+
+			l, err := locate(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			w, err := activeProvider.Weather(cmd.Context(), l)
+			if err != nil {
+				return fmt.Errorf("something went wrong when fetching the weather: %w", err)
+			}
+
+			fmt.Print(ics.Render(l, w, time.Now()))
+
+			return nil
+		},
+	}
+	icsListen   string
+	icsCacheTTL time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(icsCmd)
+
+	icsCmd.Flags().StringVar(&icsListen, "listen", "", "Serve the calendars on this address instead of printing one")
+	icsCmd.Flags().DurationVar(&icsCacheTTL, "cache-ttl", 15*time.Minute, "How long served calendars are reused")
+}
+
+// Serves the calendars of the zip codes until being stopped.
+func serveICS() error {
+	zips := splitZips(zip)
+	if len(zips) == 0 {
+		return errors.New("please provide at least one zip code")
+	}
+
+	feed := ics.NewFeed(server.Upstream{Provider: activeProvider}, zips, icsCacheTTL)
+
+	// Shut down gracefully on Ctrl-C and when being stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, p := range feed.Paths() {
+		fmt.Fprintf(os.Stderr, "Serving calendar on %s%s\n", icsListen, p)
+	}
+
+	return server.Serve(ctx, icsListen, feed)
+}
@@ -0,0 +1,135 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Fetches the weather from the selected provider and the name of the location
+// for the given zip code.
+func lookup(ctx context.Context, zip string) (*weather.Weather, string, error) {
+	return lookupWith(ctx, activeProvider, zip)
+}
+
+// Same as lookup, but fetches the weather from the given provider.
+func lookupWith(ctx context.Context, p weather.Provider, zip string) (*weather.Weather, string, error) {
+	l, err := locate(ctx, zip)
+	if err != nil {
+		return nil, "", err
+	}
+
+	w, err := p.Weather(ctx, l)
+	if err != nil {
+		return nil, "", fmt.Errorf("something went wrong when fetching the weather: %w", err)
+	}
+
+	return w, l.Name, nil
+}
+
+// Returns the location of the zip code, or the one of --lat and --lon if no zip
+// code is given.
+func locate(ctx context.Context, zip string) (weather.Location, error) {
+	flags := rootCmd.PersistentFlags()
+	if zip != "" || !flags.Changed("lat") || !flags.Changed("lon") {
+		return weather.Locate(ctx, zip)
+	}
+
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return weather.Location{}, fmt.Errorf("invalid coordinates %g, %g", latitude, longitude)
+	}
+
+	name := location
+	if name == "" {
+		name = fmt.Sprintf("%.4f, %.4f", latitude, longitude)
+	}
+
+	return weather.Location{Name: name, Latitude: latitude, Longitude: longitude}, nil
+}
+
+// Returns the error of a command that needs a zip code, as it keeps its data by
+// zip code. It tells that coordinates don't work if they were given.
+func errZipRequired() error {
+	flags := rootCmd.PersistentFlags()
+	if flags.Changed("lat") || flags.Changed("lon") {
+		return errors.New("this command keeps its data by zip code, please provide a zip code instead of --lat and --lon")
+	}
+
+	return errors.New("please provide a zip code")
+}
+
+// Same as locate, but remembers the locations of zip codes in the cache
+// directory, so that commands that need nothing else work offline.
+func locateCached(ctx context.Context, zip string) (weather.Location, error) {
+	if zip == "" {
+		return locate(ctx, zip)
+	}
+
+	path, err := locationCachePath()
+	if err != nil {
+		return weather.Location{}, err
+	}
+
+	cache := map[string]weather.Location{}
+
+	// A missing or broken cache is just empty
+	if b, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(b, &cache)
+	}
+
+	if l, ok := cache[zip]; ok {
+		return l, nil
+	}
+
+	l, err := locate(ctx, zip)
+	if err != nil {
+		return weather.Location{}, err
+	}
+
+	cache[zip] = l
+
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return l, nil
+	}
+
+	// Failing to cache only costs a lookup next time
+	if os.MkdirAll(filepath.Dir(path), 0o755) == nil {
+		_ = os.WriteFile(path, b, 0o600)
+	}
+
+	return l, nil
+}
+
+// Returns the path of the file that remembers the locations of zip codes.
+func locationCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding the cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "sunly", "locations.json"), nil
+}
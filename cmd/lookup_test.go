@@ -0,0 +1,36 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrZipRequired(t *testing.T) {
+	if err := errZipRequired(); strings.Contains(err.Error(), "--lat") {
+		t.Errorf("Expected no mention of coordinates without them, but got %q", err)
+	}
+
+	setCoordinates(t, "46.95", "7.45")
+
+	if err := errZipRequired(); !strings.Contains(err.Error(), "--lat") {
+		t.Errorf("Expected the error to mention the coordinates, but got %q", err)
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/darox/sunly/internal/notify"
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/statefile"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// notifyCmd represents the notify command.
+var (
+	notifyCmd = &cobra.Command{
+		Use:   "notify",
+		Short: "Sends notifications for triggered alert rules",
+		Long: `Checks the alert rules and sends the triggered ones to the notifiers of the
+config file. The rules are taken from --when or from the config file.
+
+A rule that keeps triggering is only sent once, until it stopped triggering or
+--repeat-after is over.
+
+Example:
+  sunly notify --zip 3006 --when "rain_next_1h > 1"`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := activeConfig
+
+			rs := c.Rules
+
+			if len(notifyWhen) > 0 {
+				rs = []rules.Rule{}
+
+				for _, w := range notifyWhen {
+					r, err := rules.Parse(w)
+					if err != nil {
+						return err
+					}
+
+					rs = append(rs, r)
+				}
+			}
+
+			if len(rs) == 0 {
+				return errors.New("no rules given, please use --when or define rules in the config file")
+			}
+
+			err := validateRules(rs)
+			if err != nil {
+				return err
+			}
+
+			// Failed deliveries still return the triggers that were sent
+			triggers, sendErr := notifyRules(cmd.Context(), activeProvider, c.Notifiers, rs, notifyRepeatAfter)
+			if triggers == nil {
+				return sendErr
+			}
+
+			if output == "json" {
+				err = printer.PrintJSON(triggers)
+				if err != nil {
+					return err
+				}
+			} else if len(triggers) > 0 {
+				printer.PrintTriggers(triggers)
+			}
+
+			return sendErr
+		},
+	}
+	notifyWhen        []string
+	notifyRepeatAfter time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+
+	notifyCmd.Flags().StringArrayVar(&notifyWhen, "when", nil,
+		"Condition to notify about, e.g. \"rain_next_1h > 1\" (can be repeated)")
+	notifyCmd.Flags().DurationVar(&notifyRepeatAfter, "repeat-after", 0,
+		"Send a rule that keeps triggering again after this duration (0 never repeats)")
+}
+
+// Only one notification run of this process may update the sent notifications
+// at a time, statefile.Lock keeps out the other processes.
+var notifyMu sync.Mutex
+
+// Evaluates the rules and sends the triggers that weren't sent yet to the
+// notifiers. It returns the triggers delivered to at least one notifier, and an
+// error joining all failed deliveries, which are tried again on the next run
+// for the notifiers that failed only.
+func notifyRules(ctx context.Context, p weather.Provider, configs []notify.Config, rs []rules.Rule, repeatAfter time.Duration) ([]rules.Trigger, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("no notifiers defined in the config file")
+	}
+
+	notifiers := map[string]notify.Notifier{}
+	ids := []string{}
+
+	for _, nc := range configs {
+		n, err := notify.New(nc)
+		if err != nil {
+			return nil, err
+		}
+
+		// Notifiers with the same destination are only sent to once
+		if _, ok := notifiers[nc.ID()]; !ok {
+			notifiers[nc.ID()] = n
+			ids = append(ids, nc.ID())
+		}
+	}
+
+	now := time.Now()
+
+	triggers, err := checkRules(ctx, p, rs, now)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := notifySentPath()
+	if err != nil {
+		return nil, err
+	}
+
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+
+	// Other processes, e.g. a cron job next to the daemon, update the file too
+	unlock, err := statefile.Lock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	sent, err := notify.LoadSent(path)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := []string{}
+	for _, r := range rs {
+		checked = append(checked, notify.Key(r.Name, ruleZip(r)))
+	}
+
+	delivered := []rules.Trigger{}
+
+	var errs []error
+
+	for _, d := range sent.Filter(checked, ids, triggers, now, repeatAfter) {
+		ok := false
+
+		for _, id := range d.Notifiers {
+			err = notifiers[id].Notify(ctx, notify.FromTrigger(d.Trigger))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			sent.Record(d.Trigger, id, now)
+			ok = true
+		}
+
+		if ok {
+			delivered = append(delivered, d.Trigger)
+		}
+	}
+
+	err = sent.Save(path)
+	if err != nil {
+		return nil, fmt.Errorf("error saving sent notifications: %w", err)
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// Returns the path of the file that remembers the sent notifications.
+func notifySentPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding the cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "sunly", "notify-sent.json"), nil
+}
@@ -0,0 +1,141 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Theme selected by setupOutput.
+	activeTheme printer.Theme
+
+	spark       bool
+	showDerived bool
+	noColor     bool
+	themeName   string
+)
+
+// Adds the flag to show the sparkline columns of a table. Sparklines are off
+// by default, so that the tables stay the same for scripts that parse them.
+func addSparkFlag(c *cobra.Command) {
+	c.Flags().BoolVar(&spark, "spark", false, "Show sparklines of the next hours")
+}
+
+// Adds the flag to show the feels like temperature and the other derived
+// quantities.
+func addDerivedFlag(c *cobra.Command) {
+	c.Flags().BoolVar(&showDerived, "derived", false,
+		"Show the feels like temperature, wind chill, heat index, humidex and dew point. The last three "+
+			"need the humidity, which MeteoSwiss doesn't provide")
+}
+
+// Loads the config file and sets up the output and the provider.
+func setup(cmd *cobra.Command, args []string) error {
+	// The prompt segment runs on every shell prompt and only reads its state
+	// file, the config is needed by the background refresh only
+	if cmd.Name() == "prompt" && !promptRefresh {
+		return nil
+	}
+
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	activeConfig = c
+
+	err = setupOutput(c)
+	if err != nil {
+		return err
+	}
+
+	return setupProvider(c)
+}
+
+// Configures the printer from the flags and the config file.
+func setupOutput(c *config.Config) error {
+	name := themeName
+	if name == "" {
+		name = c.Theme
+	}
+
+	if name == "" {
+		name = "default"
+	}
+
+	// User defined themes take precedence over the built in ones
+	theme, ok := printer.Themes[name]
+	if t, custom := c.Themes[name]; custom {
+		theme, ok = printerTheme(t), true
+	}
+
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+
+	err := theme.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid theme %q: %w", name, err)
+	}
+
+	activeTheme = theme
+
+	colors := printer.DetectColorDepth(os.Stdout)
+	if noColor {
+		colors = printer.NoColor
+	}
+
+	printer.SetOptions(printer.Options{
+		Sparklines: spark,
+		Derived:    showDerived,
+		// Terminals without UTF-8 get the ASCII variant
+		ASCII:  !printer.IsUTF8Locale(),
+		Colors: colors,
+		Theme:  theme,
+	})
+
+	return nil
+}
+
+// Converts a theme of the config file to the one of the printer.
+func printerTheme(t config.Theme) printer.Theme {
+	stops := func(cs []config.ColorStop) []printer.ColorStop {
+		result := make([]printer.ColorStop, 0, len(cs))
+
+		for _, c := range cs {
+			result = append(result, printer.ColorStop{From: c.From, Style: c.Style})
+		}
+
+		return result
+	}
+
+	return printer.Theme{
+		Header:      t.Header,
+		Stale:       t.Stale,
+		Temperature: stops(t.Temperature),
+		Rain:        stops(t.Rain),
+		Warning:     stops(t.Warning),
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/activity"
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command.
+var (
+	planCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Finds the best times for an activity",
+		Long: `Scores the forecast hours for an activity by the temperature, the precipitation,
+the wind and the daylight and recommends the best time windows.
+
+Built in activities: ` + strings.Join(activity.Names(nil), ", ") + `
+
+Own activities and changes to the built in ones are defined under activities
+in the config file.`,
+		Example:      "sunly plan --activity cycling --zip 3006",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := activity.Lookup(planActivity, activeConfig.Activities)
+			if err != nil {
+				return err
+			}
+
+			l, err := locate(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			w, err := activeProvider.Weather(cmd.Context(), l)
+			if err != nil {
+				return fmt.Errorf("something went wrong when fetching the weather: %w", err)
+			}
+
+			plan := report.NewPlan(zip, l, planActivity, p, w, time.Now(), weather.Zurich)
+
+			if output == "json" {
+				return printer.PrintJSON(plan)
+			}
+
+			printer.PrintPlan(plan, planHourly)
+
+			return nil
+		},
+	}
+	planActivity string
+	planHourly   bool
+)
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringVar(&planActivity, "activity", "cycling", "Activity to plan")
+	planCmd.Flags().BoolVar(&planHourly, "hourly", false, "Show the scores of every hour")
+}
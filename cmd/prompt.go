@@ -0,0 +1,164 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/darox/sunly/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// promptCmd represents the prompt command.
+var (
+	promptCmd = &cobra.Command{
+		Use:   "prompt",
+		Short: "Prints a short weather segment for shell prompts",
+		Long: `Prints a short weather segment for shell prompts. The segment is read from a
+local state file, so it never waits for the network. When the state is older
+than --refresh-after, a refresh is started in the background and the next
+prompt shows the new data.
+
+After a failed refresh the next one waits a minute, doubling with every further
+failure up to an hour.
+
+The placeholders {icon}, {temp} and {location} can be used in --format. The
+state is kept by zip code, so --lat and --lon are not supported.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if zip == "" {
+				return errZipRequired()
+			}
+
+			path, err := promptStatePath(zip)
+			if err != nil {
+				return err
+			}
+
+			if promptRefresh {
+				return refreshPrompt(cmd, path)
+			}
+
+			s, err := prompt.Load(path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			if s != nil {
+				fmt.Println(s.Format(promptFormat))
+			}
+
+			// After failed refreshes, wait a while before trying again
+			stale := s == nil || s.Stale(time.Now(), promptRefreshAfter)
+			if stale && !prompt.BackingOff(path, time.Now()) && prompt.TryLock(path, time.Now()) {
+				err = startPromptRefresh()
+				if err != nil {
+					prompt.Unlock(path)
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+	promptFormat       string
+	promptRefreshAfter time.Duration
+	promptRefresh      bool
+)
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+
+	promptCmd.Flags().StringVar(&promptFormat, "format", "{icon} {temp}°", "Format of the segment")
+	promptCmd.Flags().DurationVar(&promptRefreshAfter, "refresh-after", 10*time.Minute,
+		"Age of the state after which it gets refreshed")
+	promptCmd.Flags().BoolVar(&promptRefresh, "refresh", false, "Fetch the weather and update the state")
+
+	// Only used by the background refresh
+	_ = promptCmd.Flags().MarkHidden("refresh")
+}
+
+// Returns the path of the state file of the zip code in the cache directory.
+func promptStatePath(zip string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding the cache directory: %w", err)
+	}
+
+	return prompt.StatePath(filepath.Join(dir, "sunly"), zip), nil
+}
+
+// Fetches the weather and saves it to the state file.
+func refreshPrompt(cmd *cobra.Command, path string) error {
+	defer prompt.Unlock(path)
+
+	w, locationName, err := lookup(cmd.Context(), zip)
+	if err != nil {
+		_ = prompt.RecordFailure(path, time.Now())
+		return err
+	}
+
+	err = prompt.Save(path, prompt.NewState(zip, locationName, w, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	prompt.ResetFailures(path)
+
+	return nil
+}
+
+// Starts a refresh in a detached process, so the prompt doesn't wait for it.
+func startPromptRefresh() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	c := exec.Command(exe, promptRefreshArgs()...)
+	detach(c)
+
+	err = c.Start()
+	if err != nil {
+		return fmt.Errorf("error starting the refresh: %w", err)
+	}
+
+	return c.Process.Release()
+}
+
+// Returns the arguments of the refresh, which fetches the weather like the
+// prompt would.
+func promptRefreshArgs() []string {
+	args := []string{"prompt", "--refresh", "--zip", zip}
+	if cfgFile != "" {
+		args = append(args, "--config", cfgFile)
+	}
+
+	if providerName != "" {
+		args = append(args, "--provider", providerName)
+	}
+
+	return args
+}
@@ -0,0 +1,35 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPromptRefreshArgs(t *testing.T) {
+	zip, cfgFile, providerName = "3006", "/etc/sunly.yaml", "openmeteo"
+	t.Cleanup(func() { zip, cfgFile, providerName = "", "", "" })
+
+	expected := []string{"prompt", "--refresh", "--zip", "3006", "--config", "/etc/sunly.yaml", "--provider", "openmeteo"}
+
+	if args := promptRefreshArgs(); !slices.Equal(args, expected) {
+		t.Errorf("Expected %v, but got %v", expected, args)
+	}
+}
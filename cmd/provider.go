@@ -0,0 +1,73 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/weather"
+	// Registers the providers
+	_ "github.com/darox/sunly/internal/weather/meteoswiss"
+	_ "github.com/darox/sunly/internal/weather/openmeteo"
+)
+
+var (
+	// Provider selected by setupProvider.
+	activeProvider weather.Provider
+
+	providerName string
+)
+
+// Sets up the provider selected by --provider or the config file.
+func setupProvider(c *config.Config) error {
+	p, err := newProvider(c)
+	if err != nil {
+		return err
+	}
+
+	activeProvider = p
+
+	return nil
+}
+
+// Creates the provider selected by --provider or the config file.
+func newProvider(c *config.Config) (weather.Provider, error) {
+	name := selectedProvider(c)
+
+	p, err := weather.New(name, c.Providers[name])
+	if err != nil {
+		return nil, fmt.Errorf("error creating provider: %w", err)
+	}
+
+	return p, nil
+}
+
+// Returns the name of the provider selected by --provider or the config file.
+func selectedProvider(c *config.Config) string {
+	if providerName != "" {
+		return providerName
+	}
+
+	if c.Provider != "" {
+		return c.Provider
+	}
+
+	return weather.DefaultProvider
+}
@@ -0,0 +1,102 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Provider that returns fixed weather and remembers the location it was asked
+// for.
+type fakeProvider struct {
+	asked *weather.Location
+}
+
+func (p fakeProvider) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	*p.asked = l
+
+	return &weather.Weather{
+		Provider:  "fake",
+		UpdatedAt: time.Now().Add(-10 * time.Minute),
+		Current:   weather.Current{Temperature: 21.5},
+	}, nil
+}
+
+var fakeAsked weather.Location
+
+func init() {
+	weather.Register("fake", func(c weather.Config) (weather.Provider, error) {
+		return fakeProvider{asked: &fakeAsked}, nil
+	})
+}
+
+// Sets the --lat and --lon flags for the test, so no zip code has to be
+// looked up.
+func setCoordinates(t *testing.T, lat string, lon string) {
+	t.Helper()
+
+	flags := rootCmd.PersistentFlags()
+
+	for name, value := range map[string]string{"lat": lat, "lon": lon} {
+		err := flags.Set(name, value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		latitude, longitude = 0, 0
+		flags.Lookup("lat").Changed = false
+		flags.Lookup("lon").Changed = false
+	})
+}
+
+func TestSetupProvider(t *testing.T) {
+	err := setupProvider(&config.Config{Provider: "fake"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	setCoordinates(t, "46.95", "7.45")
+
+	_, r, err := renderCurrentTemperature(context.Background(), "", weather.DefaultMaxAge)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if r.Temperature != 21.5 || r.Freshness.Stale {
+		t.Errorf("Expected the fresh temperature of the fake provider, but got %+v", r)
+	}
+
+	if fakeAsked.Latitude != 46.95 || fakeAsked.Longitude != 7.45 {
+		t.Errorf("Expected the provider to be asked for the coordinates, but got %+v", fakeAsked)
+	}
+}
+
+func TestSetupUnknownProvider(t *testing.T) {
+	err := setupProvider(&config.Config{Provider: "unknown"})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown provider")
+	}
+}
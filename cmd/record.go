@@ -0,0 +1,120 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// recordCmd represents the record command.
+var (
+	recordCmd = &cobra.Command{
+		Use:   "record",
+		Short: "Records the current weather in the local history",
+		Long: `Records the current weather of one or more comma separated zip codes in the
+local history, which can be queried with sunly history. The forecast is
+recorded as well, at most once an hour, to be checked by sunly verify. Run it
+regularly, e.g. from cron or as a record job of sunly daemon. The history is
+kept by zip code, so --lat and --lon are not supported.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zips := splitZips(zip)
+			if len(zips) == 0 {
+				return errZipRequired()
+			}
+
+			s, err := historyStore()
+			if err != nil {
+				return err
+			}
+
+			recorded, err := recordWeather(cmd.Context(), activeProvider, s, zips)
+
+			for _, o := range recorded {
+				fmt.Printf("Recorded %s %s: %.1f °C at %s\n", o.Zip, o.Location, o.Temperature,
+					o.Time.In(weather.Zurich).Format("15:04 02.01.2006"))
+			}
+
+			return err
+		},
+	}
+	historyDir string
+)
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().StringVar(&historyDir, "history-dir", "", "Directory of the history (default is ~/.local/share/sunly/history)")
+}
+
+// Returns the history store in --history-dir or the default directory.
+func historyStore() (*history.Store, error) {
+	if historyDir != "" {
+		return &history.Store{Dir: historyDir}, nil
+	}
+
+	dir, err := history.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &history.Store{Dir: dir}, nil
+}
+
+// Fetches the weather of the zip codes from the provider and appends it and the forecast to the
+// history. It returns the observations that were new.
+func recordWeather(ctx context.Context, p weather.Provider, s *history.Store, zips []string) ([]history.Observation, error) {
+	recorded := []history.Observation{}
+
+	var errs []error
+
+	for _, z := range zips {
+		w, locationName, err := lookupWith(ctx, p, z)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", z, err))
+			continue
+		}
+
+		o := history.NewObservation(z, locationName, w, time.Now())
+
+		added, err := s.Append(o)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", z, err))
+		} else if added {
+			recorded = append(recorded, o)
+		}
+
+		// The forecast is kept as well, so sunly verify can compare it with the
+		// observations later on. Failing to keep it doesn't affect the
+		// observation.
+		_, err = s.AppendSnapshot(history.NewSnapshot(z, w))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: error recording the forecast: %w", z, err))
+		}
+	}
+
+	return recorded, errors.Join(errs...)
+}
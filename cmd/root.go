@@ -19,6 +19,7 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"errors"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -38,9 +39,14 @@ var (
 		// Uncomment the following line if your bare application
 		// has an action associated with it:
 		// Run: func(cmd *cobra.Command, args []string) { },
+		PersistentPreRunE: setup,
 	}
-	zip      string
-	location string
+	zip       string
+	location  string
+	latitude  float64
+	longitude float64
+	output    string
+	cfgFile   string
 )
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -48,7 +54,12 @@ var (
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		var e *exitError
+		if errors.As(err, &e) {
+			os.Exit(e.code)
+		}
+
+		os.Exit(exitCodeError)
 	}
 }
 
@@ -57,10 +68,17 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sunly.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sunly.yaml)")
 
 	rootCmd.PersistentFlags().StringVar(&zip, "zip", "", "Postal code of the location")
 	rootCmd.PersistentFlags().StringVar(&location, "location", "", "Location name")
+	rootCmd.PersistentFlags().Float64Var(&latitude, "lat", 0, "Latitude of the location, used instead of --zip")
+	rootCmd.PersistentFlags().Float64Var(&longitude, "lon", 0, "Longitude of the location, used instead of --zip")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "table", "Output format (table or json)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colors, same as setting NO_COLOR")
+	rootCmd.PersistentFlags().StringVar(&providerName, "provider", "",
+		"Weather provider (default is meteoswiss or the one of the config file)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "Color theme (default, mono or one from the config file)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,68 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/darox/sunly/internal/server"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command.
+var (
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serves the weather data over a REST API",
+		Long: `Serves the weather data over a REST API with the following endpoints:
+
+  /v1/current/{zip}   current temperature
+  /v1/forecast/{zip}  daily forecast
+  /v1/hourly/{zip}    hourly forecast
+  /v1/locations?q=    location search
+  /healthz            health check`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Shut down gracefully on Ctrl-C and when being stopped
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			s := server.New(server.Upstream{Provider: activeProvider}, serveCacheTTL, weather.DefaultMaxAge)
+
+			fmt.Fprintf(os.Stderr, "Listening on %s\n", serveAddr)
+
+			return s.ListenAndServe(ctx, serveAddr)
+		},
+	}
+	serveAddr     string
+	serveCacheTTL time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveCacheTTL, "cache-ttl", 5*time.Minute, "How long fetched data is cached")
+}
@@ -0,0 +1,87 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/solar"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// solarCmd represents the solar command.
+var (
+	solarCmd = &cobra.Command{
+		Use:   "solar",
+		Short: "Estimates the production of a PV system",
+		Long: `Estimates the hourly and daily production of a PV system from the position of
+the sun and the forecast clouds. The production under a clear sky is shown as
+baseline.
+
+Providers with a forecast of the radiation, like Open-Meteo, give the best
+numbers. MeteoSwiss has neither radiation nor cloud cover, its clouds are
+estimated from the weather icons, so take the numbers as a rough guide to plan
+around sunny hours.`,
+		Example:      "sunly solar --zip 3006 --kwp 10 --tilt 30 --azimuth 180",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := solar.Panel{KWp: solarKWp, Tilt: solarTilt, Azimuth: solarAzimuth}
+			if err := p.Validate(); err != nil {
+				return err
+			}
+
+			l, err := locate(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			w, err := activeProvider.Weather(cmd.Context(), l)
+			if err != nil {
+				return fmt.Errorf("something went wrong when fetching the weather: %w", err)
+			}
+
+			s := report.NewSolar(zip, l, p, w, time.Now(), weather.Zurich)
+
+			if output == "json" {
+				return printer.PrintJSON(s)
+			}
+
+			printer.PrintSolar(s, solarHourly)
+
+			return nil
+		},
+	}
+	solarKWp     float64
+	solarTilt    float64
+	solarAzimuth float64
+	solarHourly  bool
+)
+
+func init() {
+	rootCmd.AddCommand(solarCmd)
+
+	solarCmd.Flags().Float64Var(&solarKWp, "kwp", 0, "Peak power of the system in kW")
+	solarCmd.Flags().Float64Var(&solarTilt, "tilt", 30, "Tilt of the panels from horizontal in degrees")
+	solarCmd.Flags().Float64Var(&solarAzimuth, "azimuth", 180, "Direction the panels face in degrees, 180 is south")
+	solarCmd.Flags().BoolVar(&solarHourly, "hourly", false, "Show the production of every hour")
+}
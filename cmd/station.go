@@ -0,0 +1,132 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swissmetnet"
+	"github.com/spf13/cobra"
+)
+
+// stationCmd represents the station command.
+var (
+	stationCmd = &cobra.Command{
+		Use:   "station",
+		Short: "Shows the measurements of the nearest weather station",
+		Long: `Shows the latest 10 minute measurements of the SwissMetNet station nearest to
+the location: temperature, humidity, pressure, wind gusts, sunshine and
+radiation. Unlike the current weather of the other commands, these values are
+measured and not modelled.
+
+Stations that don't measure temperature or humidity are skipped, use --station
+to pick one by its abbreviation.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := renderStation(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(s)
+
+			return nil
+		},
+	}
+	stationAbbr string
+)
+
+func init() {
+	rootCmd.AddCommand(stationCmd)
+
+	stationCmd.Flags().StringVar(&stationAbbr, "station", "", "Abbreviation of the station, e.g. BER")
+}
+
+// Renders the measurement of the station in the selected output format.
+func renderStation(ctx context.Context, zip string) (string, error) {
+	l, err := locate(ctx, zip)
+	if err != nil {
+		return "", err
+	}
+
+	stations, err := swissmetnet.GetStations(ctx)
+	if err != nil {
+		return "", fmt.Errorf("something went wrong when fetching the stations: %w", err)
+	}
+
+	measurements, err := swissmetnet.GetMeasurements(ctx)
+	if err != nil {
+		return "", fmt.Errorf("something went wrong when fetching the measurements: %w", err)
+	}
+
+	n, m, err := nearestStation(stations, measurements, l, stationAbbr)
+	if err != nil {
+		return "", err
+	}
+
+	r := report.Station{Zip: zip, Location: l.Name, Station: n, Measurement: m}
+
+	if output == "json" {
+		return printer.RenderJSON(r)
+	}
+
+	return printer.RenderStation(r), nil
+}
+
+// Returns the station with the abbreviation, or the nearest one measuring
+// temperature or humidity if it is empty.
+func nearestStation(stations []swissmetnet.Station, measurements []swissmetnet.Measurement,
+	l weather.Location, abbr string,
+) (swissmetnet.Nearby, swissmetnet.Measurement, error) {
+	if l.Latitude == 0 && l.Longitude == 0 {
+		return swissmetnet.Nearby{}, swissmetnet.Measurement{}, fmt.Errorf("the location %s has no coordinates", l.Name)
+	}
+
+	for _, n := range swissmetnet.Nearest(stations, l.Latitude, l.Longitude) {
+		if abbr != "" && !strings.EqualFold(n.Abbr, abbr) {
+			continue
+		}
+
+		m, ok := swissmetnet.Find(measurements, n.Abbr)
+
+		if abbr != "" {
+			if !ok {
+				return n, m, fmt.Errorf("the station %s has no current measurements", n.Abbr)
+			}
+
+			return n, m, nil
+		}
+
+		if ok && (m.Temperature != nil || m.Humidity != nil) {
+			return n, m, nil
+		}
+	}
+
+	if abbr != "" {
+		return swissmetnet.Nearby{}, swissmetnet.Measurement{}, fmt.Errorf("unknown station %q", abbr)
+	}
+
+	return swissmetnet.Nearby{}, swissmetnet.Measurement{}, errors.New("no station with current measurements found")
+}
@@ -19,69 +19,94 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/darox/sunly/internal/printer"
-	"github.com/darox/sunly/pkg/swissmeteo"
-	"github.com/darox/sunly/pkg/swisspost"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/watch"
+	"github.com/darox/sunly/internal/weather"
 	"github.com/spf13/cobra"
 )
 
 // tempCmd represents the temp command.
-var tempCmd = &cobra.Command{
-	Use:   "temp",
-	Short: "Returns the temperature of a location by providing a postal code",
-	Long:  `Returns the temperature of a location by providing a postal code`,
-	Run: func(cmd *cobra.Command, args []string) {
-		switch {
-		case rootCmd.PersistentFlags().Lookup("zip") != nil:
-			getCurrentTemperature(zip)
-		//TODO: Add location flag
-		default:
-			fmt.Println("Please provide a zip code")
-		}
-	},
-}
+var (
+	tempCmd = &cobra.Command{
+		Use:          "temp",
+		Short:        "Returns the temperature of a location by providing a postal code",
+		Long:         `Returns the temperature of a location by providing a postal code`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case rootCmd.PersistentFlags().Lookup("zip") != nil && watchEnabled:
+				return runWatch(cmd, func(ctx context.Context) (watch.Frame, error) {
+					s, r, err := renderCurrentTemperature(ctx, zip, maxAge)
+
+					return watch.Frame{Text: s, UpdatedAt: r.Freshness.UpdatedAt}, err
+				})
+			case rootCmd.PersistentFlags().Lookup("zip") != nil:
+				// Only fail on stale data if the user asked for it
+				return getCurrentTemperature(cmd.Context(), zip, maxAge, cmd.Flags().Changed("max-age"))
+			//TODO: Add location flag
+			default:
+				fmt.Println("Please provide a zip code")
+			}
+
+			return nil
+		},
+	}
+	maxAge time.Duration
+)
 
 func init() {
 	rootCmd.AddCommand(tempCmd)
-}
 
-func getCurrentTemperature(zip string) {
-	// Create a new weather object
-	w := swissmeteo.Weather{}
+	tempCmd.Flags().DurationVar(&maxAge, "max-age", weather.DefaultMaxAge,
+		"Maximum age of the data, fails with exit code 3 when exceeded")
+	addWatchFlags(tempCmd)
+	addSparkFlag(tempCmd)
+	addDerivedFlag(tempCmd)
+}
 
-	// Get the current temperature for the given zip code
-	temperature, u, err := w.GetCurrentTemperature(zip)
+func getCurrentTemperature(ctx context.Context, zip string, maxAge time.Duration, failOnStale bool) error {
+	s, r, err := renderCurrentTemperature(ctx, zip, maxAge)
 	if err != nil {
-		fmt.Printf("Something went wrong when fetching the temperature: %s\n", err)
-		return
+		return err
 	}
 
-	// Convert time to a human readable format
-	h := time.Unix(u/1000, 0)
-	updatedAt := h.Format("15:04 02.01.2006")
+	fmt.Println(s)
 
-	// Create a new location object
-	ld := swisspost.LocationData{}
+	if failOnStale && r.Freshness.Stale {
+		return &exitError{
+			code: exitCodeStale,
+			err:  fmt.Errorf("weather data is stale, last updated %s ago", r.Freshness.Age()),
+		}
+	}
 
-	// Get the location data
-	err = ld.GetLocationDataByZip(zip)
+	return nil
+}
 
+// Renders the current temperature in the selected output format.
+func renderCurrentTemperature(ctx context.Context, zip string, maxAge time.Duration) (string, report.Current, error) {
+	w, locationName, err := lookup(ctx, zip)
 	if err != nil {
-		fmt.Printf("Something went wrong when fetching the location: %s\n", err)
-		return
+		return "", report.Current{}, err
 	}
 
-	locationName := ld.Records[0].Fields.Ortbez18
+	// Check how old the data is
+	r := report.NewCurrent(zip, locationName, w, time.Now(), maxAge)
 
-	// Check if the zip code is valid
+	if output == "json" {
+		var s string
+		s, err = printer.RenderJSON(r)
 
-	if !ld.IsZipValid(zip) {
-		fmt.Printf("The zip code %s is not valid\n", zip)
-		return
+		return s, r, err
 	}
 
-	printer.PrintCurrentTemperature(zip, locationName, temperature, updatedAt)
+	// Convert time to a human readable format
+	updatedAt := r.Freshness.UpdatedAt.In(weather.Zurich).Format("15:04 02.01.2006")
+
+	return printer.RenderCurrentTemperature(zip, locationName, r.Temperature, r.Derived, updatedAt,
+		r.Freshness.Stale, w.Hours), r, nil
 }
@@ -0,0 +1,95 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command.
+var (
+	verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Compares recorded forecasts with the observed weather",
+		Long: fmt.Sprintf(`Compares the forecasts recorded by sunly record with the weather that was
+observed later on, by the number of days ahead the forecast was made.
+
+For the hourly temperature and the daily minimum and maximum it shows the bias
+(forecast minus observed), the mean absolute error and the share of forecasts
+within %.0f °C. For rain it shows how often a day with at least %.0f mm was
+forecast correctly. The history is kept by zip code, so --lat and --lon are not
+supported.`, verify.TemperatureTolerance, verify.RainThreshold),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if zip == "" {
+				return errZipRequired()
+			}
+
+			now := time.Now()
+
+			since, err := history.ParseSince(verifySince, now)
+			if err != nil {
+				return err
+			}
+
+			s, err := historyStore()
+			if err != nil {
+				return err
+			}
+
+			snaps, err := s.Snapshots(zip, since, time.Time{})
+			if err != nil {
+				return err
+			}
+
+			obs, err := s.Query(zip, since, time.Time{})
+			if err != nil {
+				return err
+			}
+
+			if len(snaps) == 0 || len(obs) == 0 {
+				return fmt.Errorf("no recorded forecasts or observations of %s, please run sunly record regularly", zip)
+			}
+
+			leads := verify.Verify(snaps, obs, now)
+
+			if output == "json" {
+				return printer.PrintJSON(leads)
+			}
+
+			printer.PrintVerification(zip, leads)
+
+			return nil
+		},
+	}
+	verifySince string
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVar(&verifySince, "since", "30d", "Start of the compared period, e.g. 30d or 2023-05-01")
+	verifyCmd.Flags().StringVar(&historyDir, "history-dir", "", "Directory of the history (default is ~/.local/share/sunly/history)")
+}
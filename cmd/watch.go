@@ -0,0 +1,57 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/darox/sunly/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// The shortest interval allowed, so the API doesn't get hammered.
+const minWatchInterval = time.Minute
+
+var (
+	watchEnabled  bool
+	watchInterval time.Duration
+)
+
+// Adds the flags to redraw the output of a command periodically.
+func addWatchFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&watchEnabled, "watch", false, "Refresh the output whenever new data is published")
+	c.Flags().DurationVar(&watchInterval, "interval", 10*time.Minute, "Refresh interval in watch mode")
+}
+
+// Redraws the frames returned by fetch until Ctrl-C is pressed.
+func runWatch(cmd *cobra.Command, fetch func(ctx context.Context) (watch.Frame, error)) error {
+	if watchInterval < minWatchInterval {
+		return fmt.Errorf("the interval must be at least %s", minWatchInterval)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return watch.Run(ctx, os.Stdout, watchInterval, fetch)
+}
@@ -0,0 +1,119 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/query"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/spf13/cobra"
+)
+
+// whenCmd represents the when command.
+var (
+	whenCmd = &cobra.Command{
+		Use:   "when <condition>",
+		Short: "Finds the time windows that meet a condition",
+		Long: `Finds the time windows in which every forecast hour meets the condition. The
+condition compares metrics and numbers with <, <=, >, >=, == and != and combines
+them with and, or, not and parentheses. Only hourly values are supported, the
+10 minute precipitation of MeteoSwiss is summed up into its hours.
+
+Metrics:
+` + metricsHelp() + `
+
+Exits with code 4 if no window meets the condition, so it can be used in
+scripts.`,
+		Example:      `sunly when --zip 3006 "temp > 15 and rain == 0 and wind < 20" --within 48h --min-duration 2h`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := query.Parse(args[0])
+			if err != nil {
+				return err
+			}
+
+			if whenWithin <= 0 || whenMinDuration < 0 {
+				return fmt.Errorf("--within must be positive and --min-duration must not be negative")
+			}
+
+			l, err := locate(cmd.Context(), zip)
+			if err != nil {
+				return err
+			}
+
+			w, err := activeProvider.Weather(cmd.Context(), l)
+			if err != nil {
+				return fmt.Errorf("something went wrong when fetching the weather: %w", err)
+			}
+
+			r := report.NewWhen(zip, l, q, w, time.Now(), weather.Zurich, whenWithin, whenMinDuration)
+
+			if output == "json" {
+				err = printer.PrintJSON(r)
+				if err != nil {
+					return err
+				}
+			} else if len(r.Windows) > 0 {
+				printer.PrintWhen(r)
+			}
+
+			if len(r.Windows) == 0 {
+				return &exitError{
+					code: exitCodeNoMatch,
+					err:  fmt.Errorf("no window of at least %s within %s meets %q", whenMinDuration, whenWithin, q),
+				}
+			}
+
+			return nil
+		},
+	}
+	whenWithin      time.Duration
+	whenMinDuration time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(whenCmd)
+
+	whenCmd.Flags().DurationVar(&whenWithin, "within", 48*time.Hour, "How far ahead to look")
+	whenCmd.Flags().DurationVar(&whenMinDuration, "min-duration", time.Hour, "Shortest window to show")
+}
+
+// Returns the metrics and their meaning, one per line.
+func metricsHelp() string {
+	names := make([]string, 0, len(query.Metrics))
+	for name := range query.Metrics {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %-9s %s", name, query.Metrics[name]))
+	}
+
+	return strings.Join(lines, "\n")
+}
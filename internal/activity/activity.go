@@ -0,0 +1,315 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package activity scores the forecast hours for outdoor activities and finds
+// the best time windows.
+package activity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/astro"
+)
+
+// How far outside the ideal range the temperature score drops to zero in °C.
+const temperatureTolerance = 10.0
+
+// Number of windows recommended by a plan.
+const Windows = 3
+
+// Profile describes the weather an activity needs.
+type Profile struct {
+	// Ideal range of the temperature in °C.
+	TemperatureMin float64 `yaml:"temperatureMin" json:"temperatureMin"`
+	TemperatureMax float64 `yaml:"temperatureMax" json:"temperatureMax"`
+	// Precipitation in mm/h that ruins the activity.
+	PrecipitationMax float64 `yaml:"precipitationMax" json:"precipitationMax"`
+	// Wind speed in km/h that ruins the activity.
+	WindMax float64 `yaml:"windMax" json:"windMax"`
+	// How long the activity takes, at least an hour.
+	Duration time.Duration `yaml:"duration" json:"duration"`
+	Weights  Weights       `yaml:"weights" json:"weights"`
+}
+
+// Weights are the importance of the factors, zero ignores a factor.
+type Weights struct {
+	Temperature   float64 `yaml:"temperature" json:"temperature"`
+	Precipitation float64 `yaml:"precipitation" json:"precipitation"`
+	Wind          float64 `yaml:"wind" json:"wind"`
+	Daylight      float64 `yaml:"daylight" json:"daylight"`
+}
+
+// Profiles are the built in activities.
+var Profiles = map[string]Profile{
+	"cycling": {
+		TemperatureMin:   12,
+		TemperatureMax:   25,
+		PrecipitationMax: 1,
+		WindMax:          40,
+		Duration:         time.Hour,
+		Weights:          Weights{Temperature: 1, Precipitation: 3, Wind: 2, Daylight: 1},
+	},
+	"hiking": {
+		TemperatureMin:   10,
+		TemperatureMax:   22,
+		PrecipitationMax: 1.5,
+		WindMax:          50,
+		Duration:         4 * time.Hour,
+		Weights:          Weights{Temperature: 1, Precipitation: 3, Wind: 1, Daylight: 3},
+	},
+	"bbq": {
+		TemperatureMin:   18,
+		TemperatureMax:   30,
+		PrecipitationMax: 0.5,
+		WindMax:          30,
+		Duration:         3 * time.Hour,
+		Weights:          Weights{Temperature: 2, Precipitation: 3, Wind: 1, Daylight: 1},
+	},
+	"laundry": {
+		TemperatureMin:   15,
+		TemperatureMax:   35,
+		PrecipitationMax: 0.2,
+		WindMax:          60,
+		Duration:         6 * time.Hour,
+		Weights:          Weights{Temperature: 1, Precipitation: 4, Wind: 0.5, Daylight: 2},
+	},
+}
+
+// Returns the names of the built in and the given profiles, sorted.
+func Names(custom map[string]Profile) []string {
+	names := []string{}
+
+	for name := range Profiles {
+		if _, ok := custom[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	for name := range custom {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Returns the profile of the name, the given ones take precedence over the
+// built in ones.
+func Lookup(name string, custom map[string]Profile) (Profile, error) {
+	p, ok := custom[name]
+	if !ok {
+		p, ok = Profiles[name]
+	}
+
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown activity %q, known are %v", name, Names(custom))
+	}
+
+	if err := p.Validate(); err != nil {
+		return Profile{}, fmt.Errorf("invalid activity %q: %w", name, err)
+	}
+
+	return p, nil
+}
+
+// Validates the profile.
+func (p Profile) Validate() error {
+	w := p.Weights
+
+	switch {
+	case p.TemperatureMin > p.TemperatureMax:
+		return errors.New("temperatureMin must not be above temperatureMax")
+	case p.PrecipitationMax <= 0:
+		return errors.New("precipitationMax must be positive")
+	case p.WindMax <= 0:
+		return errors.New("windMax must be positive")
+	case p.Duration < time.Hour:
+		return errors.New("duration must be at least an hour")
+	case w.Temperature < 0 || w.Precipitation < 0 || w.Wind < 0 || w.Daylight < 0:
+		return errors.New("weights must not be negative")
+	case w.Temperature+w.Precipitation+w.Wind+w.Daylight == 0:
+		return errors.New("at least one weight must be positive")
+	}
+
+	return nil
+}
+
+// Hour is the score of an hour.
+type Hour struct {
+	Time time.Time `json:"time"`
+	// From 0 (impossible) to 100 (perfect).
+	Score int `json:"score"`
+	// Scores of the factors from 0 to 1.
+	Temperature   float64 `json:"temperature"`
+	Precipitation float64 `json:"precipitation"`
+	Wind          float64 `json:"wind"`
+	Daylight      float64 `json:"daylight"`
+}
+
+// Window is a time span as long as the activity.
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Mean score of the hours.
+	Score int `json:"score"`
+}
+
+// Day is the best window of a day.
+type Day struct {
+	Date string `json:"date"`
+	Best Window `json:"best"`
+}
+
+// Plan are the scores of the hours, the best window of every day and the best
+// windows overall.
+type Plan struct {
+	Hours   []Hour   `json:"hours"`
+	Days    []Day    `json:"days"`
+	Windows []Window `json:"windows"`
+}
+
+// Scores the hour at the coordinates.
+func (p Profile) Score(h weather.Hour, lat float64, lon float64) Hour {
+	s := Hour{
+		Time:          h.Time,
+		Temperature:   temperatureScore(h.TemperatureMean, p.TemperatureMin, p.TemperatureMax),
+		Precipitation: limitScore(precipitation(h), p.PrecipitationMax),
+		Wind:          limitScore(h.WindSpeed, p.WindMax),
+	}
+
+	// Daylight in the middle of the hour, counting the twilight as half
+	e := astro.SunPosition(h.Time.Add(30*time.Minute), lat, lon).Elevation
+
+	switch {
+	case e > astro.SunriseElevation:
+		s.Daylight = 1
+	case e > -6:
+		s.Daylight = 0.5
+	}
+
+	// Weighted geometric mean, so that a single bad factor spoils the hour
+	w := p.Weights
+	total := w.Temperature + w.Precipitation + w.Wind + w.Daylight
+	score := math.Pow(s.Temperature, w.Temperature/total) *
+		math.Pow(s.Precipitation, w.Precipitation/total) *
+		math.Pow(s.Wind, w.Wind/total) *
+		math.Pow(s.Daylight, w.Daylight/total)
+
+	s.Score = int(math.Round(score * 100))
+
+	return s
+}
+
+// Scores the hours and finds the best windows, the days are in the location.
+func (p Profile) Plan(hours []weather.Hour, lat float64, lon float64, loc *time.Location) Plan {
+	plan := Plan{Hours: []Hour{}, Days: []Day{}, Windows: []Window{}}
+
+	for _, h := range hours {
+		plan.Hours = append(plan.Hours, p.Score(h, lat, lon))
+	}
+
+	windows := p.windows(plan.Hours)
+
+	for _, w := range windows {
+		date := w.Start.In(loc).Format("2006-01-02")
+
+		if len(plan.Days) == 0 || plan.Days[len(plan.Days)-1].Date != date {
+			plan.Days = append(plan.Days, Day{Date: date, Best: w})
+		} else if d := &plan.Days[len(plan.Days)-1]; w.Score > d.Best.Score {
+			d.Best = w
+		}
+	}
+
+	// Best windows first, earlier ones on a tie
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].Score > windows[j].Score })
+
+	for _, w := range windows {
+		if len(plan.Windows) == Windows {
+			break
+		}
+
+		overlaps := false
+
+		for _, b := range plan.Windows {
+			if w.Start.Before(b.End) && b.Start.Before(w.End) {
+				overlaps = true
+				break
+			}
+		}
+
+		if !overlaps && w.Score > 0 {
+			plan.Windows = append(plan.Windows, w)
+		}
+	}
+
+	return plan
+}
+
+// Returns all windows of consecutive hours as long as the activity.
+func (p Profile) windows(hours []Hour) []Window {
+	n := int(math.Ceil(p.Duration.Hours()))
+	windows := []Window{}
+
+	for i := 0; i+n <= len(hours); i++ {
+		// Skip windows with gaps in the forecast
+		if !hours[i+n-1].Time.Equal(hours[i].Time.Add(time.Duration(n-1) * time.Hour)) {
+			continue
+		}
+
+		sum := 0
+
+		for _, h := range hours[i : i+n] {
+			sum += h.Score
+		}
+
+		windows = append(windows, Window{
+			Start: hours[i].Time,
+			End:   hours[i].Time.Add(time.Duration(n) * time.Hour),
+			Score: int(math.Round(float64(sum) / float64(n))),
+		})
+	}
+
+	return windows
+}
+
+// Returns the precipitation to expect, the mean or half of the upper band of
+// the forecast, whichever is higher, to account for the risk of showers.
+func precipitation(h weather.Hour) float64 {
+	return math.Max(h.Precipitation, h.PrecipitationMax/2)
+}
+
+// Returns 1 within the range, falling to 0 within the tolerance outside.
+func temperatureScore(t float64, min float64, max float64) float64 {
+	off := math.Max(min-t, t-max)
+	if off <= 0 {
+		return 1
+	}
+
+	return math.Max(0, 1-off/temperatureTolerance)
+}
+
+// Returns 1 for no value, falling to 0 at the limit.
+func limitScore(v float64, limit float64) float64 {
+	return math.Max(0, 1-math.Max(v, 0)/limit)
+}
@@ -0,0 +1,181 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package activity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Bern
+const (
+	latitude  = 46.95
+	longitude = 7.45
+)
+
+func TestProfiles(t *testing.T) {
+	for name, p := range Profiles {
+		if err := p.Validate(); err != nil {
+			t.Errorf("Expected the built in activity %q to be valid, but got %v", name, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	custom := map[string]Profile{
+		"cycling": {TemperatureMin: 5, TemperatureMax: 30, PrecipitationMax: 2, WindMax: 50, Duration: time.Hour,
+			Weights: Weights{Precipitation: 1}},
+		"broken": {PrecipitationMax: 1, WindMax: 1},
+	}
+
+	p, err := Lookup("cycling", custom)
+	if err != nil || p.TemperatureMin != 5 {
+		t.Errorf("Expected the own cycling activity, but got %+v and %v", p, err)
+	}
+
+	if p, err := Lookup("hiking", custom); err != nil || p.Duration != 4*time.Hour {
+		t.Errorf("Expected the built in hiking activity, but got %+v and %v", p, err)
+	}
+
+	if _, err := Lookup("broken", custom); err == nil {
+		t.Errorf("Expected an error for an invalid activity")
+	}
+
+	if _, err := Lookup("skiing", custom); err == nil {
+		t.Errorf("Expected an error for an unknown activity")
+	}
+
+	names := Names(custom)
+	if len(names) != 5 || names[0] != "bbq" || names[1] != "broken" {
+		t.Errorf("Expected the sorted names of all activities, but got %v", names)
+	}
+}
+
+func TestScore(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Zurich")
+	p := Profiles["cycling"]
+	noon := time.Date(2023, 6, 21, 12, 0, 0, 0, loc)
+
+	perfect := p.Score(weather.Hour{Time: noon, TemperatureMean: 20, WindSpeed: 0}, latitude, longitude)
+	if perfect.Score != 100 {
+		t.Errorf("Expected a perfect score, but got %+v", perfect)
+	}
+
+	rain := p.Score(weather.Hour{Time: noon, TemperatureMean: 20, Precipitation: 1}, latitude, longitude)
+	if rain.Score != 0 {
+		t.Errorf("Expected rain at the limit to spoil the hour, but got %+v", rain)
+	}
+
+	showers := p.Score(weather.Hour{Time: noon, TemperatureMean: 20, PrecipitationMax: 1}, latitude, longitude)
+	if showers.Score == 0 || showers.Score >= 100 {
+		t.Errorf("Expected the risk of showers to lower the score, but got %+v", showers)
+	}
+
+	cold := p.Score(weather.Hour{Time: noon, TemperatureMean: 7}, latitude, longitude)
+	if cold.Temperature != 0.5 {
+		t.Errorf("Expected half the temperature score 5 °C below the range, but got %+v", cold)
+	}
+
+	night := p.Score(weather.Hour{Time: noon.Add(-11 * time.Hour), TemperatureMean: 20}, latitude, longitude)
+	if night.Daylight != 0 || night.Score != 0 {
+		t.Errorf("Expected no daylight at 1:00, but got %+v", night)
+	}
+
+	// Without a weight the factor is ignored
+	p.Weights.Daylight = 0
+
+	night = p.Score(weather.Hour{Time: noon.Add(-11 * time.Hour), TemperatureMean: 20}, latitude, longitude)
+	if night.Score != 100 {
+		t.Errorf("Expected the daylight to be ignored, but got %+v", night)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Zurich")
+	p := Profiles["bbq"]
+
+	hours := []weather.Hour{}
+
+	for i := 0; i < 48; i++ {
+		h := weather.Hour{Time: time.Date(2023, 6, 21, i, 0, 0, 0, loc), TemperatureMean: 24}
+
+		// Rain on the first afternoon, a windy evening on the second day
+		if i >= 12 && i < 20 {
+			h.Precipitation = 2
+		}
+
+		if i >= 42 {
+			h.WindSpeed = 25
+		}
+
+		hours = append(hours, h)
+	}
+
+	plan := p.Plan(hours, latitude, longitude, loc)
+
+	if len(plan.Hours) != 48 {
+		t.Errorf("Expected 48 hour scores, but got %d", len(plan.Hours))
+	}
+
+	if len(plan.Days) != 2 {
+		t.Fatalf("Expected two days, but got %+v", plan.Days)
+	}
+
+	if len(plan.Windows) != Windows {
+		t.Fatalf("Expected %d windows, but got %+v", Windows, plan.Windows)
+	}
+
+	for i, w := range plan.Windows {
+		if w.End.Sub(w.Start) != 3*time.Hour {
+			t.Errorf("Expected windows of 3 hours, but got %+v", w)
+		}
+
+		if w.Start.Day() == 21 && w.Start.Hour() >= 10 && w.Start.Hour() < 20 {
+			t.Errorf("Expected no window in the rain, but got %+v", w)
+		}
+
+		for _, b := range plan.Windows[:i] {
+			if w.Start.Before(b.End) && b.Start.Before(w.End) {
+				t.Errorf("Expected no overlapping windows, but got %+v and %+v", w, b)
+			}
+
+			if w.Score > b.Score {
+				t.Errorf("Expected the best windows first, but got %+v", plan.Windows)
+			}
+		}
+	}
+
+	if plan.Days[1].Best.Score != 100 || plan.Days[1].Best.Start.Hour() >= 18 {
+		t.Errorf("Expected a perfect window before the windy evening, but got %+v", plan.Days[1].Best)
+	}
+}
+
+func TestWindowsSkipGaps(t *testing.T) {
+	p := Profiles["hiking"]
+	start := time.Date(2023, 6, 21, 10, 0, 0, 0, time.UTC)
+
+	hours := []Hour{{Time: start}, {Time: start.Add(time.Hour)}, {Time: start.Add(5 * time.Hour)},
+		{Time: start.Add(6 * time.Hour)}}
+
+	if w := p.windows(hours); len(w) != 0 {
+		t.Errorf("Expected no window across the gap, but got %+v", w)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package bar formats the weather for status bars like waybar, i3bar, polybar,
+// tmux and xbar.
+package bar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+// The supported formats.
+const (
+	FormatWaybar   = "waybar"
+	FormatI3bar    = "i3bar"
+	FormatI3blocks = "i3blocks"
+	FormatPolybar  = "polybar"
+	FormatTmux     = "tmux"
+	FormatXbar     = "xbar"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{FormatWaybar, FormatI3bar, FormatI3blocks, FormatPolybar, FormatTmux, FormatXbar}
+
+// Range of temperatures mapped to the percentage of waybar.
+const (
+	percentageMin = -20.0
+	percentageMax = 40.0
+)
+
+// Status is the weather as shown in a status bar.
+type Status struct {
+	// Short text like "☀ 17°".
+	Text string
+	// Title and forecast lines for the tooltip.
+	Tooltip []string
+	// Classes for styling, the condition and the warning level.
+	Class []string
+	// Temperature mapped to 0 - 100.
+	Percentage int
+	// Color as hex value, empty if the theme has none.
+	Color string
+}
+
+// Builds the status from the weather data of a location.
+func NewStatus(location string, w *weather.Weather, theme printer.Theme) Status {
+	c := w.Current
+
+	s := Status{
+		Text:  fmt.Sprintf("%s %.0f°", swissmeteo.IconSymbol(c.Icon), c.Temperature),
+		Class: []string{string(swissmeteo.IconCondition(c.Icon))},
+		Color: theme.TemperatureColor(c.Temperature),
+	}
+
+	p := (c.Temperature - percentageMin) / (percentageMax - percentageMin) * 100
+	s.Percentage = int(math.Round(math.Max(0, math.Min(100, p))))
+
+	s.Tooltip = append(s.Tooltip, fmt.Sprintf("%s: %.1f °C, %s", location, c.Temperature,
+		swissmeteo.IconDescription(c.Icon)))
+
+	for _, d := range w.Days {
+		date := d.DayDate
+		if t, err := time.Parse("2006-01-02", d.DayDate); err == nil {
+			date = t.Format("Mon 02.01.")
+		}
+
+		s.Tooltip = append(s.Tooltip, fmt.Sprintf("%s %s %.0f/%.0f °C %.1f mm", date,
+			swissmeteo.IconSymbol(d.IconDay), d.TemperatureMin, d.TemperatureMax, d.Precipitation))
+	}
+
+	// The highest warning decides the class and the color
+	level := 0
+
+	warnings := append([]weather.Warning{}, w.Warnings...)
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Level > warnings[j].Level })
+
+	for _, wr := range warnings {
+		s.Tooltip = append(s.Tooltip, fmt.Sprintf("⚠ Level %d %s warning", wr.Level, wr.Type))
+
+		if wr.Level > level {
+			level = wr.Level
+		}
+	}
+
+	if level > 0 {
+		s.Class = append(s.Class, fmt.Sprintf("warning-%d", level))
+
+		if c := theme.WarningColor(level); c != "" {
+			s.Color = c
+		}
+	}
+
+	return s
+}
+
+// Renders the status in the given format.
+func Render(format string, s Status) (string, error) {
+	switch format {
+	case FormatWaybar:
+		return waybar(s)
+	case FormatI3bar:
+		return i3bar(s)
+	case FormatI3blocks:
+		// Full text, short text and color, one per line
+		return strings.Join([]string{s.Text, s.Text, s.Color}, "\n"), nil
+	case FormatPolybar:
+		if s.Color == "" {
+			return s.Text, nil
+		}
+
+		return fmt.Sprintf("%%{F%s}%s%%{F-}", s.Color, s.Text), nil
+	case FormatTmux:
+		if s.Color == "" {
+			return s.Text, nil
+		}
+
+		return fmt.Sprintf("#[fg=%s]%s#[default]", s.Color, s.Text), nil
+	case FormatXbar:
+		return xbar(s), nil
+	}
+
+	return "", fmt.Errorf("unknown format %q, supported are %s", format, strings.Join(Formats, ", "))
+}
+
+func waybar(s Status) (string, error) {
+	b, err := json.Marshal(struct {
+		Text       string   `json:"text"`
+		Tooltip    string   `json:"tooltip"`
+		Class      []string `json:"class"`
+		Percentage int      `json:"percentage"`
+		Alt        string   `json:"alt"`
+	}{
+		Text:       s.Text,
+		Tooltip:    strings.Join(s.Tooltip, "\n"),
+		Class:      s.Class,
+		Percentage: s.Percentage,
+		Alt:        s.Class[0],
+	})
+
+	return string(b), err
+}
+
+func i3bar(s Status) (string, error) {
+	b, err := json.Marshal(struct {
+		Name      string `json:"name"`
+		FullText  string `json:"full_text"`
+		ShortText string `json:"short_text"`
+		Color     string `json:"color,omitempty"`
+	}{
+		Name:      "sunly",
+		FullText:  s.Text,
+		ShortText: s.Text,
+		Color:     s.Color,
+	})
+
+	return string(b), err
+}
+
+func xbar(s Status) string {
+	title := s.Text
+	if s.Color != "" {
+		title = fmt.Sprintf("%s | color=%s", title, s.Color)
+	}
+
+	lines := []string{title, "---"}
+
+	for _, t := range s.Tooltip {
+		// Pipes separate the parameters in xbar
+		lines = append(lines, strings.ReplaceAll(t, "|", "/"))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,122 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package bar
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/weather"
+)
+
+func testStatus() Status {
+	w := &weather.Weather{}
+	w.Current.Icon = 1
+	w.Current.Temperature = 17
+	w.Days = []weather.Day{{DayDate: "2023-05-07", IconDay: 17, TemperatureMax: 18, TemperatureMin: 11, Precipitation: 12.7}}
+	w.Warnings = []weather.Warning{{Type: "rain", Level: 2}, {Type: "wind", Level: 3}}
+
+	return NewStatus("Bern", w, printer.Themes["default"])
+}
+
+func TestNewStatus(t *testing.T) {
+	s := testStatus()
+
+	if s.Text != "☀ 17°" {
+		t.Errorf("Expected text ☀ 17°, but got %s", s.Text)
+	}
+
+	if strings.Join(s.Class, " ") != "clear warning-3" {
+		t.Errorf("Unexpected classes %v", s.Class)
+	}
+
+	// 17 °C on a scale from -20 to 40
+	if s.Percentage != 62 {
+		t.Errorf("Expected percentage 62, but got %d", s.Percentage)
+	}
+
+	// The warning color wins over the temperature
+	if s.Color != "#fb8c00" {
+		t.Errorf("Expected the color of warning level 3, but got %s", s.Color)
+	}
+
+	expected := []string{"Bern: 17.0 °C, sunny", "Sun 07.05. ☂ 11/18 °C 12.7 mm", "⚠ Level 3 wind warning", "⚠ Level 2 rain warning"}
+	if strings.Join(s.Tooltip, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected tooltip %q", s.Tooltip)
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := testStatus()
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{FormatI3blocks, "☀ 17°\n☀ 17°\n#fb8c00"},
+		{FormatPolybar, "%{F#fb8c00}☀ 17°%{F-}"},
+		{FormatTmux, "#[fg=#fb8c00]☀ 17°#[default]"},
+		{FormatI3bar, `{"name":"sunly","full_text":"☀ 17°","short_text":"☀ 17°","color":"#fb8c00"}`},
+	}
+
+	for _, tt := range tests {
+		got, err := Render(tt.format, s)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.format, err)
+		}
+
+		if got != tt.expected {
+			t.Errorf("%s: expected %q, but got %q", tt.format, tt.expected, got)
+		}
+	}
+
+	out, err := Render(FormatXbar, s)
+	if err != nil || !strings.HasPrefix(out, "☀ 17° | color=#fb8c00\n---\nBern: 17.0 °C, sunny\n") {
+		t.Errorf("Unexpected xbar output %q", out)
+	}
+
+	if _, err = Render("conky", s); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestRenderWaybar(t *testing.T) {
+	out, err := Render(FormatWaybar, testStatus())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var v struct {
+		Text       string   `json:"text"`
+		Tooltip    string   `json:"tooltip"`
+		Class      []string `json:"class"`
+		Percentage int      `json:"percentage"`
+	}
+
+	err = json.Unmarshal([]byte(out), &v)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if v.Text != "☀ 17°" || v.Percentage != 62 || len(v.Class) != 2 || !strings.Contains(v.Tooltip, "\n") {
+		t.Errorf("Unexpected waybar output %s", out)
+	}
+}
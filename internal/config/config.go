@@ -0,0 +1,142 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package config loads the sunly config file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/darox/sunly/internal/activity"
+	"github.com/darox/sunly/internal/notify"
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/weather"
+	"gopkg.in/yaml.v3"
+)
+
+// Name of the config file in the home directory.
+const fileName = ".sunly.yaml"
+
+// Config is the content of the config file.
+type Config struct {
+	Places []Place `yaml:"places"`
+	// Name of the theme to use, either a built in or a user defined one.
+	Theme  string           `yaml:"theme"`
+	Themes map[string]Theme `yaml:"themes"`
+	// Name of the weather provider to use.
+	Provider string `yaml:"provider"`
+	// Settings of the providers by name.
+	Providers map[string]weather.Config `yaml:"providers"`
+	// Sources compared with the selected provider by sunly forecast --ensemble.
+	Ensemble []Source `yaml:"ensemble"`
+	// Alert rules checked by sunly check.
+	Rules []rules.Rule `yaml:"rules"`
+	// Where sunly notify delivers triggered rules.
+	Notifiers []notify.Config `yaml:"notifiers"`
+	// Jobs run by sunly daemon.
+	Jobs []Job `yaml:"jobs"`
+	// Activities of sunly plan in addition to the built in ones.
+	Activities map[string]activity.Profile `yaml:"activities"`
+}
+
+// Theme defines the colors of the output. Styles are a space separated list of
+// colors, either as hex value like #ff8800 or by name, and the attributes bold,
+// dim and underline.
+type Theme struct {
+	Header      string      `yaml:"header"`
+	Stale       string      `yaml:"stale"`
+	Temperature []ColorStop `yaml:"temperature"`
+	Rain        []ColorStop `yaml:"rain"`
+	Warning     []ColorStop `yaml:"warning"`
+}
+
+// ColorStop applies the style to all values from the given one up to the next
+// stop.
+type ColorStop struct {
+	From  float64 `yaml:"from"`
+	Style string  `yaml:"style"`
+}
+
+// Place is a saved location.
+type Place struct {
+	Name string `yaml:"name"`
+	Zip  string `yaml:"zip"`
+}
+
+// Source is a forecast source of the ensemble.
+type Source struct {
+	// Name shown in the output, the provider if empty.
+	Name     string `yaml:"name"`
+	Provider string `yaml:"provider"`
+	// Settings of the provider, the ones under providers if empty.
+	weather.Config `yaml:",inline"`
+}
+
+// Job is a task that sunly daemon runs on a schedule.
+type Job struct {
+	Name string `yaml:"name"`
+	// Cron expression, a macro like @hourly or an interval like "@every 10m".
+	Schedule string `yaml:"schedule"`
+	// One of refresh, check, notify, webhook or record.
+	Type string `yaml:"type"`
+	// Zip codes of refresh, webhook and record jobs.
+	Zips []string `yaml:"zips"`
+	// Names of the rules that check and notify jobs evaluate, all if empty.
+	Rules []string `yaml:"rules"`
+	// Where webhook jobs post the current weather.
+	URL string `yaml:"url"`
+	// Sends a rule that keeps triggering again after this duration.
+	RepeatAfter time.Duration `yaml:"repeatAfter"`
+}
+
+// Returns the path of the config file in the home directory.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding the home directory: %w", err)
+	}
+
+	return filepath.Join(home, fileName), nil
+}
+
+// Loads the config file at the given path. A missing file results in an empty
+// config.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+
+	err = yaml.Unmarshal(b, c)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %w", path, err)
+	}
+
+	return c, nil
+}
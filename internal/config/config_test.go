@@ -0,0 +1,132 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sunly.yaml")
+
+	err := os.WriteFile(path, []byte(`
+places:
+  - name: Office
+    zip: "3006"
+  - name: Home
+    zip: "8001"
+theme: dark
+themes:
+  dark:
+    header: bold cyan
+    temperature:
+      - from: 0
+        style: "#4fc3f7"
+rules:
+  - name: frost
+    zip: "3006"
+    metric: temperature
+    operator: "<"
+    value: 0
+    within: 12h
+notifiers:
+  - type: ntfy
+    url: https://ntfy.sh/frost
+jobs:
+  - name: frost alerts
+    schedule: "*/15 * * * *"
+    type: notify
+    rules: [frost]
+    repeatAfter: 12h
+provider: meteoswiss
+providers:
+  openmeteo:
+    baseURL: http://localhost:8080
+ensemble:
+  - name: icon
+    provider: openmeteo
+    baseURL: http://localhost:8081
+activities:
+  tennis:
+    temperatureMin: 15
+    temperatureMax: 28
+    precipitationMax: 0.3
+    windMax: 25
+    duration: 2h
+    weights:
+      precipitation: 3
+      wind: 2
+`), 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(c.Places) != 2 || c.Places[1].Zip != "8001" || c.Places[0].Name != "Office" {
+		t.Errorf("Unexpected places %+v", c.Places)
+	}
+
+	if c.Theme != "dark" || c.Themes["dark"].Header != "bold cyan" || c.Themes["dark"].Temperature[0].Style != "#4fc3f7" {
+		t.Errorf("Unexpected themes %+v", c.Themes)
+	}
+
+	if len(c.Rules) != 1 || c.Rules[0].Within != 12*time.Hour || c.Rules[0].Operator != "<" {
+		t.Errorf("Unexpected rules %+v", c.Rules)
+	}
+
+	if len(c.Notifiers) != 1 || c.Notifiers[0].Type != "ntfy" {
+		t.Errorf("Unexpected notifiers %+v", c.Notifiers)
+	}
+
+	if len(c.Jobs) != 1 || c.Jobs[0].Schedule != "*/15 * * * *" || c.Jobs[0].Rules[0] != "frost" ||
+		c.Jobs[0].RepeatAfter != 12*time.Hour {
+		t.Errorf("Unexpected jobs %+v", c.Jobs)
+	}
+
+	if c.Provider != "meteoswiss" || c.Providers["openmeteo"].BaseURL != "http://localhost:8080" {
+		t.Errorf("Unexpected providers %q %+v", c.Provider, c.Providers)
+	}
+
+	if len(c.Ensemble) != 1 || c.Ensemble[0].Name != "icon" || c.Ensemble[0].BaseURL != "http://localhost:8081" {
+		t.Errorf("Unexpected ensemble %+v", c.Ensemble)
+	}
+
+	tennis := c.Activities["tennis"]
+	if tennis.Duration != 2*time.Hour || tennis.WindMax != 25 || tennis.Weights.Wind != 2 || tennis.Weights.Daylight != 0 {
+		t.Errorf("Unexpected activities %+v", c.Activities)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(c.Places) != 0 {
+		t.Errorf("Expected an empty config, but got %+v", c)
+	}
+}
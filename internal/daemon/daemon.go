@@ -0,0 +1,196 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package daemon runs jobs on schedules in a long lived process.
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Maximum time a single run of a job may take.
+const jobTimeout = 2 * time.Minute
+
+// Job is a task that runs on a schedule.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+}
+
+// Loader returns the jobs to run. It is called on start and on every reload.
+type Loader func() ([]Job, error)
+
+// clock tells the time and waits, the tests replace it to control time.
+type clock interface {
+	Now() time.Time
+	// Returns a channel that receives the time after the duration and a
+	// function that stops the timer.
+	After(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+
+	return t.C, t.Stop
+}
+
+// Daemon runs the jobs returned by its loader until it is stopped.
+type Daemon struct {
+	load   Loader
+	logger *slog.Logger
+	clock  clock
+
+	mu      sync.Mutex
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// Creates a daemon that logs to the logger.
+func New(load Loader, logger *slog.Logger) *Daemon {
+	return &Daemon{
+		load:    load,
+		logger:  logger,
+		clock:   realClock{},
+		running: map[string]bool{},
+	}
+}
+
+// Runs the jobs until the context is done. A value on reload loads the jobs
+// again, if that fails the current jobs keep running. On shutdown, Run waits
+// for the jobs that are running to finish.
+func (d *Daemon) Run(ctx context.Context, reload <-chan struct{}) error {
+	jobs, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("daemon started", "jobs", len(jobs))
+
+	next := d.schedule(jobs)
+
+	for {
+		tick, stop := d.clock.After(d.untilNext(next))
+
+		select {
+		case <-ctx.Done():
+			stop()
+			d.logger.Info("shutting down, waiting for running jobs")
+			d.wg.Wait()
+			d.logger.Info("daemon stopped")
+
+			return nil
+		case <-reload:
+			stop()
+
+			j, err := d.load()
+			if err != nil {
+				d.logger.Error("reload failed, keeping the current jobs", "error", err)
+				continue
+			}
+
+			jobs = j
+			next = d.schedule(jobs)
+
+			d.logger.Info("reloaded", "jobs", len(jobs))
+		case <-tick:
+			now := d.clock.Now()
+
+			for i, j := range jobs {
+				if next[i].IsZero() || next[i].After(now) {
+					continue
+				}
+
+				d.start(j)
+				next[i] = j.Schedule.Next(now)
+			}
+		}
+	}
+}
+
+// Returns the next run of every job.
+func (d *Daemon) schedule(jobs []Job) []time.Time {
+	now := d.clock.Now()
+	next := make([]time.Time, len(jobs))
+
+	for i, j := range jobs {
+		next[i] = j.Schedule.Next(now)
+		d.logger.Debug("scheduled", "job", j.Name, "next", next[i])
+	}
+
+	return next
+}
+
+// Returns the time until the earliest next run.
+func (d *Daemon) untilNext(next []time.Time) time.Duration {
+	// Without anything to run the daemon just waits for a signal
+	earliest := d.clock.Now().Add(24 * time.Hour)
+
+	for _, t := range next {
+		if !t.IsZero() && t.Before(earliest) {
+			earliest = t
+		}
+	}
+
+	return earliest.Sub(d.clock.Now())
+}
+
+// Runs the job in the background, unless its previous run is still going on.
+func (d *Daemon) start(j Job) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running[j.Name] {
+		d.logger.Warn("skipping run, the previous one is still running", "job", j.Name)
+		return
+	}
+
+	d.running[j.Name] = true
+	d.wg.Add(1)
+
+	go func() {
+		defer d.wg.Done()
+
+		// Runs are not cancelled on shutdown, they are waited for instead
+		ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+		defer cancel()
+
+		start := d.clock.Now()
+		err := j.Run(ctx)
+		duration := d.clock.Now().Sub(start)
+
+		if err != nil {
+			d.logger.Error("job failed", "job", j.Name, "duration", duration, "error", err)
+		} else {
+			d.logger.Info("job finished", "job", j.Name, "duration", duration)
+		}
+
+		d.mu.Lock()
+		delete(d.running, j.Name)
+		d.mu.Unlock()
+	}()
+}
@@ -0,0 +1,277 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Schedule that runs a job at a short interval, which ParseSchedule doesn't
+// allow.
+type testSchedule time.Duration
+
+func (s testSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// Timer the daemon waits on, fired by the test.
+type fakeTimer struct {
+	c chan time.Time
+	d time.Duration
+}
+
+// Clock that only moves when a test fires one of its timers.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiting chan fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		waiting: make(chan fakeTimer),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	t := fakeTimer{c: make(chan time.Time, 1), d: d}
+	c.waiting <- t
+
+	return t.c, func() bool { return true }
+}
+
+// Waits for the daemon to wait on a timer, then moves the time forward and
+// fires it.
+func (c *fakeClock) tick(t *testing.T) {
+	t.Helper()
+
+	timer := c.wait(t)
+
+	c.mu.Lock()
+	c.now = c.now.Add(timer.d)
+	now := c.now
+	c.mu.Unlock()
+
+	timer.c <- now
+}
+
+// Returns the next timer the daemon waits on.
+func (c *fakeClock) wait(t *testing.T) fakeTimer {
+	t.Helper()
+
+	select {
+	case timer := <-c.waiting:
+		return timer
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the daemon to wait")
+		return fakeTimer{}
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// Returns a daemon using the clock.
+func testDaemon(load Loader, c *fakeClock) *Daemon {
+	d := New(load, testLogger())
+	d.clock = c
+
+	return d
+}
+
+func TestRun(t *testing.T) {
+	var finished atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	c := newFakeClock()
+	d := testDaemon(func() ([]Job, error) {
+		return []Job{{
+			Name:     "count",
+			Schedule: testSchedule(10 * time.Minute),
+			Run: func(ctx context.Context) error {
+				started <- struct{}{}
+				<-release
+				finished.Add(1)
+
+				return nil
+			},
+		}}, nil
+	}, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- d.Run(ctx, nil) }()
+
+	for i := 0; i < 3; i++ {
+		c.tick(t)
+		<-started
+		release <- struct{}{}
+
+		// The run is over once the daemon is done with it
+		d.wg.Wait()
+	}
+
+	// Shutdown waits for running jobs
+	c.tick(t)
+	<-started
+	c.wait(t)
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("Expected Run to wait for the running job")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if finished.Load() != 4 {
+		t.Errorf("Expected all 4 runs to finish, but %d did", finished.Load())
+	}
+}
+
+func TestRunReload(t *testing.T) {
+	var loads atomic.Int32
+	ran := make(chan string)
+
+	c := newFakeClock()
+	d := testDaemon(func() ([]Job, error) {
+		n := loads.Add(1)
+		if n == 2 {
+			return nil, errors.New("broken config")
+		}
+
+		name := "first"
+		if n > 2 {
+			name = "second"
+		}
+
+		return []Job{{
+			Name:     name,
+			Schedule: testSchedule(5 * time.Minute),
+			Run: func(ctx context.Context) error {
+				ran <- name
+
+				return nil
+			},
+		}}, nil
+	}, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reload := make(chan struct{})
+	done := make(chan error)
+
+	go func() { done <- d.Run(ctx, reload) }()
+
+	expect := func(name string) {
+		t.Helper()
+
+		c.tick(t)
+
+		if n := <-ran; n != name {
+			t.Errorf("Expected job %s to run, but got %s", name, n)
+		}
+
+		d.wg.Wait()
+	}
+
+	expect("first")
+
+	// A failed reload keeps the current jobs
+	c.wait(t)
+	reload <- struct{}{}
+	expect("first")
+
+	c.wait(t)
+	reload <- struct{}{}
+	expect("second")
+
+	c.wait(t)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestRunSkipsOverlappingRuns(t *testing.T) {
+	var runs atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	c := newFakeClock()
+	d := testDaemon(func() ([]Job, error) {
+		return []Job{{
+			Name:     "slow",
+			Schedule: testSchedule(5 * time.Minute),
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				started <- struct{}{}
+				<-release
+
+				return nil
+			},
+		}}, nil
+	}, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- d.Run(ctx, nil) }()
+
+	c.tick(t)
+	<-started
+
+	// Once the daemon waits again, it has handled the second tick
+	c.tick(t)
+	c.wait(t)
+
+	if runs.Load() != 1 {
+		t.Errorf("Expected a single run while the first one is going on, but got %d", runs.Load())
+	}
+
+	close(release)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule tells when a job runs next.
+type Schedule interface {
+	// Returns the first time after t the job runs, or the zero time if it
+	// never runs again.
+	Next(t time.Time) time.Time
+}
+
+// Shortcuts for common cron expressions.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parses a schedule, either a cron expression with the five fields minute,
+// hour, day of month, month and day of week, a macro like @hourly or a fixed
+// interval like "@every 10m".
+func ParseSchedule(s string) (Schedule, error) {
+	s = strings.TrimSpace(s)
+
+	if d, ok := strings.CutPrefix(s, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", s, err)
+		}
+
+		if interval < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: the interval must be at least 1s", s)
+		}
+
+		return every(interval), nil
+	}
+
+	if m, ok := macros[s]; ok {
+		s = m
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, but got %d", s, len(fields))
+	}
+
+	c := &cron{}
+	bounds := []struct {
+		set      *uint64
+		min, max int
+	}{
+		{&c.minute, 0, 59},
+		{&c.hour, 0, 23},
+		{&c.dom, 1, 31},
+		{&c.month, 1, 12},
+		{&c.dow, 0, 7},
+	}
+
+	for i, b := range bounds {
+		set, err := parseField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", s, err)
+		}
+
+		*b.set = set
+	}
+
+	// Sunday can be written as 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+
+	return c, nil
+}
+
+// Parses a cron field like "*", "*/15", "1-5", "8-18/2" or "0,30" into a bit
+// set of the values.
+func parseField(field string, min int, max int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		r, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		from, to := min, max
+
+		if r != "*" {
+			fromText, toText, isRange := strings.Cut(r, "-")
+
+			var err error
+
+			from, err = strconv.Atoi(fromText)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", fromText)
+			}
+
+			to = from
+			if isRange {
+				to, err = strconv.Atoi(toText)
+				if err != nil {
+					return 0, fmt.Errorf("invalid value %q", toText)
+				}
+			} else if hasStep {
+				// "5/15" means every 15 starting at 5
+				to = max
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for v := from; v <= to; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// every runs a job at a fixed interval.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cron runs a job at the times matching a cron expression, in local time.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// Whether the day fields are unrestricted, which changes how they combine
+	domAny, dowAny bool
+}
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Give up on expressions that never match, like the 31st of February
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Like cron, a day matches if either day field matches when both are
+// restricted, otherwise both have to match.
+func (c *cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+
+	if !c.domAny && !c.dowAny {
+		return dom || dow
+	}
+
+	return dom && dow
+}
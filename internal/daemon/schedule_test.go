@@ -0,0 +1,75 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Sunday
+	start := time.Date(2023, 5, 7, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2023, 5, 7, 10, 15, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2023, 5, 8, 6, 0, 0, 0, time.UTC)},
+		{"30 7-18/2 * * 1-5", time.Date(2023, 5, 8, 7, 30, 0, 0, time.UTC)},
+		{"0 12 1 * *", time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2023, 5, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2023, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{"5,50 10 * * *", time.Date(2023, 5, 7, 10, 50, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2023, 5, 7, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", start.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.schedule)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.schedule, err)
+			continue
+		}
+
+		if got := s.Next(start); !got.Equal(tt.expected) {
+			t.Errorf("%s: expected next run at %s, but got %s", tt.schedule, tt.expected, got)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, s := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "a * * * *", "5-1 * * * *", "@every 10ms", "@often"} {
+		if _, err := ParseSchedule(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestScheduleNeverMatches(t *testing.T) {
+	s, err := ParseSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no next run for the 31st of February, but got %s", next)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package dashboard implements the interactive full screen view of sunly.
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/weather"
+	"golang.org/x/term"
+)
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+
+	// How often the dashboard checks for a resized terminal and outdated data.
+	tick = time.Second
+)
+
+// Fetcher returns the weather and the name of the location of a zip code.
+type Fetcher func(ctx context.Context, zip string) (*weather.Weather, string, error)
+
+// Dashboard shows the weather of the saved places.
+type Dashboard struct {
+	places   []*view
+	selected int
+	fetch    Fetcher
+	refresh  time.Duration
+	now      func() time.Time
+}
+
+// view is the state of a single place.
+type view struct {
+	place     config.Place
+	weather   *weather.Weather
+	location  string
+	err       error
+	fetchedAt time.Time
+	loading   bool
+}
+
+// result of a fetch running in the background.
+type result struct {
+	view     *view
+	weather  *weather.Weather
+	location string
+	err      error
+}
+
+// Creates a dashboard for the places that refetches the data of the selected
+// place once it is older than refresh.
+func New(places []config.Place, fetch Fetcher, refresh time.Duration) *Dashboard {
+	d := &Dashboard{
+		fetch:   fetch,
+		refresh: refresh,
+		now:     time.Now,
+	}
+
+	for _, p := range places {
+		if p.Name == "" {
+			p.Name = p.Zip
+		}
+
+		d.places = append(d.places, &view{place: p})
+	}
+
+	return d
+}
+
+// Runs the dashboard on the terminal until the user quits or the context is
+// done.
+func (d *Dashboard) Run(ctx context.Context, in *os.File, out io.Writer) error {
+	if len(d.places) == 0 {
+		return errors.New("no places to show")
+	}
+
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("the dashboard needs a terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("error switching the terminal to raw mode: %w", err)
+	}
+
+	defer func() {
+		fmt.Fprint(out, leaveScreen)
+		_ = term.Restore(fd, state)
+	}()
+
+	fmt.Fprint(out, enterScreen)
+
+	keys := make(chan string)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		readKeys(in, keys, done)
+	}()
+
+	// Stop the reader before the terminal is restored, so it doesn't take the
+	// input meant for the shell. It notices within the poll interval.
+	defer func() {
+		close(done)
+
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+		}
+	}()
+
+	results := make(chan result)
+	ticker := time.NewTicker(tick)
+
+	defer ticker.Stop()
+
+	width, height := 0, 0
+	redraw := true
+
+	for {
+		if d.fetchOutdated(ctx, results) {
+			redraw = true
+		}
+
+		w, h, err := term.GetSize(fd)
+		if err != nil {
+			w, h = 80, 24
+		}
+
+		if w != width || h != height {
+			width, height = w, h
+			redraw = true
+		}
+
+		if redraw {
+			fmt.Fprint(out, clearScreen+d.Render(width, height))
+			redraw = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok || !d.handleKey(k) {
+				return nil
+			}
+
+			redraw = true
+		case r := <-results:
+			r.view.loading = false
+			r.view.fetchedAt = d.now()
+			r.view.err = r.err
+
+			if r.err == nil {
+				r.view.weather = r.weather
+				r.view.location = r.location
+			}
+
+			redraw = true
+		case <-ticker.C:
+		}
+	}
+}
+
+// Starts fetching the selected place if its data is outdated and returns
+// whether it did.
+func (d *Dashboard) fetchOutdated(ctx context.Context, results chan<- result) bool {
+	v := d.places[d.selected]
+
+	if v.loading || (!v.fetchedAt.IsZero() && d.now().Sub(v.fetchedAt) < d.refresh) {
+		return false
+	}
+
+	v.loading = true
+
+	go func() {
+		w, location, err := d.fetch(ctx, v.place.Zip)
+
+		select {
+		case results <- result{view: v, weather: w, location: location, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return true
+}
+
+// Handles a key press and returns false if the dashboard should quit.
+func (d *Dashboard) handleKey(k string) bool {
+	switch k {
+	case "q", "\x03", "esc":
+		return false
+	case "right", "l", "\t", "n":
+		d.selected = (d.selected + 1) % len(d.places)
+	case "left", "h", "p":
+		d.selected = (d.selected - 1 + len(d.places)) % len(d.places)
+	case "r":
+		// Outdate the data, so it gets fetched again
+		d.places[d.selected].fetchedAt = time.Time{}
+	default:
+		if len(k) == 1 && k[0] >= '1' && k[0] <= '9' && int(k[0]-'1') < len(d.places) {
+			d.selected = int(k[0] - '1')
+		}
+	}
+
+	return true
+}
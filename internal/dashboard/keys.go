@@ -0,0 +1,199 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package dashboard
+
+import (
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// How often the key reader checks whether the dashboard stopped.
+	keyPollInterval = 100 * time.Millisecond
+	// How long the rest of an escape sequence may take before a lone escape is
+	// taken as the escape key.
+	escapeTimeout = 50 * time.Millisecond
+)
+
+// keyParser splits the bytes read from the terminal into key presses. Escape
+// sequences may be split across reads, so incomplete ones are kept until the
+// rest arrives or flush gives up on them.
+type keyParser struct {
+	pending []byte
+}
+
+// Adds the bytes read and returns the keys that are complete.
+func (p *keyParser) feed(b []byte) []string {
+	p.pending = append(p.pending, b...)
+
+	return p.parse()
+}
+
+// Returns whether bytes of an incomplete key are kept.
+func (p *keyParser) incomplete() bool {
+	return len(p.pending) > 0
+}
+
+// Gives up waiting for the rest of an incomplete key. A lone escape is the
+// escape key, anything else that is incomplete is dropped.
+func (p *keyParser) flush() []string {
+	if len(p.pending) == 0 {
+		return nil
+	}
+
+	if p.pending[0] != '\x1b' {
+		p.pending = nil
+		return nil
+	}
+
+	p.pending = p.pending[1:]
+
+	return append([]string{"esc"}, p.parse()...)
+}
+
+func (p *keyParser) parse() []string {
+	keys := []string{}
+
+	for len(p.pending) > 0 {
+		k, n := parseKey(p.pending)
+		if n == 0 {
+			break
+		}
+
+		p.pending = p.pending[n:]
+
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// Parses the key at the start of b and returns it with the number of bytes it
+// took, zero if it is incomplete. Escape sequences other than the arrow keys
+// are skipped with an empty key.
+func parseKey(b []byte) (string, int) {
+	if b[0] != '\x1b' {
+		if !utf8.FullRune(b) {
+			return "", 0
+		}
+
+		_, n := utf8.DecodeRune(b)
+
+		return string(b[:n]), n
+	}
+
+	if len(b) < 2 {
+		return "", 0
+	}
+
+	switch b[1] {
+	case '[':
+		// Control sequences end with a byte between @ and ~
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return arrowKey(b[i], string(b[2:i])), i + 1
+			}
+		}
+
+		return "", 0
+	case 'O':
+		if len(b) < 3 {
+			return "", 0
+		}
+
+		return arrowKey(b[2], ""), 3
+	default:
+		// An escape followed by another key, e.g. with Alt
+		return "esc", 1
+	}
+}
+
+// Returns the arrow key of the final byte of a sequence without parameters.
+func arrowKey(final byte, params string) string {
+	if params != "" {
+		return ""
+	}
+
+	switch final {
+	case 'C':
+		return "right"
+	case 'D':
+		return "left"
+	}
+
+	return ""
+}
+
+// Reads key presses from the terminal until done is closed.
+func readKeys(in *os.File, keys chan<- string, done <-chan struct{}) {
+	defer close(keys)
+
+	buf := make([]byte, 16)
+	p := &keyParser{}
+
+	send := func(ks []string) bool {
+		for _, k := range ks {
+			select {
+			case keys <- k:
+			case <-done:
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for {
+		timeout := keyPollInterval
+		if p.incomplete() {
+			timeout = escapeTimeout
+		}
+
+		ready, err := waitReadable(in, timeout)
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if !ready {
+			if !send(p.flush()) {
+				return
+			}
+
+			continue
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+
+		if !send(p.feed(buf[:n])) {
+			return
+		}
+	}
+}
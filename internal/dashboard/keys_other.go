@@ -0,0 +1,31 @@
+//go:build !unix && !windows
+
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package dashboard
+
+import (
+	"os"
+	"time"
+)
+
+// Systems without a way to wait for input block in the read instead.
+func waitReadable(f *os.File, timeout time.Duration) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,103 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package dashboard
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKeyParser(t *testing.T) {
+	p := &keyParser{}
+
+	if keys := p.feed([]byte("q")); !slices.Equal(keys, []string{"q"}) {
+		t.Errorf("Expected q, but got %v", keys)
+	}
+
+	// An arrow key split across reads
+	if keys := p.feed([]byte("\x1b")); len(keys) != 0 {
+		t.Errorf("Expected no key yet, but got %v", keys)
+	}
+
+	if keys := p.feed([]byte("[C2")); !slices.Equal(keys, []string{"right", "2"}) {
+		t.Errorf("Expected right and 2, but got %v", keys)
+	}
+
+	// Other sequences are no keys
+	if keys := p.feed([]byte("\x1b[1;5A\x1bOD")); !slices.Equal(keys, []string{"left"}) {
+		t.Errorf("Expected only left, but got %v", keys)
+	}
+
+	// A lone escape is the escape key once no more bytes follow
+	if keys := p.feed([]byte("\x1b")); len(keys) != 0 {
+		t.Errorf("Expected no key yet, but got %v", keys)
+	}
+
+	if keys := p.flush(); !slices.Equal(keys, []string{"esc"}) {
+		t.Errorf("Expected esc, but got %v", keys)
+	}
+
+	if p.incomplete() {
+		t.Errorf("Expected nothing to be pending after the flush")
+	}
+}
+
+func TestReadKeysStops(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("waiting for input needs a console on windows")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	keys := make(chan string)
+	done := make(chan struct{})
+
+	go readKeys(r, keys, done)
+
+	_, _ = w.Write([]byte("\x1b[D"))
+
+	if k := <-keys; k != "left" {
+		t.Errorf("Expected left, but got %q", k)
+	}
+
+	_, _ = w.Write([]byte("\x1b"))
+
+	if k := <-keys; k != "esc" {
+		t.Errorf("Expected esc, but got %q", k)
+	}
+
+	close(done)
+
+	select {
+	case _, ok := <-keys:
+		if ok {
+			t.Errorf("Expected no more keys after the stop")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the reader to stop without any input")
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/darox/sunly/internal/printer"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+const (
+	// Width of a day in the forecast strip.
+	dayWidth = 13
+	// Rows of the temperature and precipitation charts.
+	temperatureRows   = 6
+	precipitationRows = 4
+	// Hours shown in the charts.
+	chartHours = 48
+)
+
+// Renders the selected place to fit into the given terminal size.
+func (d *Dashboard) Render(width int, height int) string {
+	v := d.places[d.selected]
+
+	lines := []string{
+		d.header(width),
+		strings.Repeat("─", width),
+	}
+
+	switch {
+	case v.weather == nil && v.err != nil:
+		lines = append(lines, fmt.Sprintf(" Error: %s", v.err))
+	case v.weather == nil:
+		lines = append(lines, " Loading…")
+	default:
+		lines = append(lines, current(v, d.now())...)
+
+		if v.err != nil {
+			lines = append(lines, fmt.Sprintf(" Refresh failed: %s", v.err))
+		}
+
+		lines = append(lines, strings.Repeat("─", width))
+		lines = append(lines, forecast(v.weather, width)...)
+		lines = append(lines, strings.Repeat("─", width))
+		lines = append(lines, charts(v.weather, d.now(), width)...)
+	}
+
+	// Cut off whatever doesn't fit
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	for i, l := range lines {
+		lines[i] = truncate(l, width)
+	}
+
+	return strings.Join(lines, "\r\n")
+}
+
+func (d *Dashboard) header(width int) string {
+	v := d.places[d.selected]
+
+	title := fmt.Sprintf(" sunly  %s", v.place.Name)
+	if v.location != "" && v.location != v.place.Name {
+		title = fmt.Sprintf("%s · %s", title, v.location)
+	}
+
+	title = fmt.Sprintf("%s (%s)", title, v.place.Zip)
+
+	help := fmt.Sprintf("%d/%d  ←/→ place · r refresh · q quit ", d.selected+1, len(d.places))
+
+	gap := width - utf8.RuneCountInString(title) - utf8.RuneCountInString(help)
+	if gap < 1 {
+		gap = 1
+	}
+
+	return title + strings.Repeat(" ", gap) + help
+}
+
+// Returns the lines with the current conditions.
+func current(v *view, now time.Time) []string {
+	w := v.weather
+
+	now1 := fmt.Sprintf(" Now  %s %.1f °C  %s", swissmeteo.IconSymbol(w.Current.Icon),
+		w.Current.Temperature, swissmeteo.IconDescription(w.Current.Icon))
+
+	for _, h := range w.Hours {
+		if !now.Before(h.Time) && now.Before(h.End()) {
+			now1 = fmt.Sprintf("%s   Wind %.0f km/h %s", now1, h.WindSpeed, compass(h.WindDirection))
+			break
+		}
+	}
+
+	f := w.Freshness(now, 0)
+
+	updated := fmt.Sprintf(" Updated %s", f.UpdatedAt.Format("15:04 02.01.2006"))
+	if f.Stale {
+		updated += " (stale)"
+	}
+
+	// Show sunrise and sunset of the current day
+	for i, sunrise := range w.Sunrise {
+		if sunrise.Year() != now.Year() || sunrise.YearDay() != now.YearDay() || i >= len(w.Sunset) {
+			continue
+		}
+
+		updated = fmt.Sprintf("%s   Sunrise %s  Sunset %s", updated, sunrise.Format("15:04"),
+			w.Sunset[i].Format("15:04"))
+
+		break
+	}
+
+	return []string{now1, updated}
+}
+
+// Returns the lines of the forecast strip, as many days as fit into the width.
+func forecast(w *weather.Weather, width int) []string {
+	days := w.Days
+	if n := (width - 1) / dayWidth; len(days) > n {
+		days = days[:n]
+	}
+
+	rows := make([]string, 3)
+
+	for _, d := range days {
+		date := d.DayDate
+
+		if t, err := time.ParseInLocation("2006-01-02", d.DayDate, time.Local); err == nil {
+			date = t.Format("Mon 02.01")
+		}
+
+		rows[0] += pad(date, dayWidth)
+		rows[1] += pad(fmt.Sprintf("%s %.0f/%.0f°", swissmeteo.IconSymbol(d.IconDay), d.TemperatureMin, d.TemperatureMax), dayWidth)
+		rows[2] += pad(fmt.Sprintf("%.1f mm", d.Precipitation), dayWidth)
+	}
+
+	for i := range rows {
+		rows[i] = " " + rows[i]
+	}
+
+	return rows
+}
+
+// Returns the hourly temperature and precipitation charts starting at the
+// current hour.
+func charts(w *weather.Weather, now time.Time, width int) []string {
+	hours := w.HoursFrom(now)
+
+	// At most two days
+	if len(hours) > chartHours {
+		hours = hours[:chartHours]
+	}
+
+	o := printer.DefaultChartOptions(width)
+	o.TemperatureRows = temperatureRows
+	o.PrecipitationRows = precipitationRows
+	o.ShadeNight = true
+	o.Sunrise, o.Sunset = w.Sunrise, w.Sunset
+
+	return printer.RenderChartLines(hours, o)
+}
+
+// Returns the compass direction of the wind direction in degrees.
+func compass(degrees int) string {
+	directions := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+	return directions[((degrees+22)%360+360)%360/45]
+}
+
+// Pads the string with spaces to the given width.
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+
+	return s
+}
+
+// Cuts the string to the given visible width. Escape sequences of colors take
+// no space and are kept, a cut line ends with a reset so that the color doesn't
+// spill into the rest of the terminal.
+func truncate(s string, width int) string {
+	var b strings.Builder
+
+	visible := 0
+	colored := false
+
+	for i := 0; i < len(s); {
+		// Copy escape sequences like \x1b[1;31m as a whole
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+
+			if j < len(s) {
+				j++
+			}
+
+			b.WriteString(s[i:j])
+			colored = true
+			i = j
+
+			continue
+		}
+
+		if visible == width {
+			if colored {
+				b.WriteString("\x1b[0m")
+			}
+
+			return b.String()
+		}
+
+		_, n := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+n])
+		visible++
+		i += n
+	}
+
+	return b.String()
+}
@@ -0,0 +1,139 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package dashboard
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/config"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/internal/weather/meteoswiss"
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+func testDashboard() *Dashboard {
+	s := &swissmeteo.Weather{}
+	s.CurrentWeather.Time = 1683452400000
+	s.CurrentWeather.Icon = 1
+	s.CurrentWeather.Temperature = 17
+	s.Forecast = []swissmeteo.Day{
+		{DayDate: "2023-05-07", IconDay: 25, TemperatureMax: 18, TemperatureMin: 11, Precipitation: 12.7},
+		{DayDate: "2023-05-08", IconDay: 4, TemperatureMax: 19, TemperatureMin: 11, Precipitation: 4.7},
+	}
+	s.Graph.Start = 1683410400000
+	s.Graph.StartLowResolution = 1683410400000
+	s.Graph.TemperatureMean1H = []float64{14.1, 13.7, 13.3, 12.6, 11.9, 11.2, 12.0, 15.0}
+	s.Graph.Precipitation1H = []float64{1.3, 1.9, 2.1, 1.1, 0.3, 0.4, 0, 0}
+	s.Graph.WindSpeed3H = []float64{10.6, 8.1, 4.5}
+	s.Graph.WindDirection3H = []int{83, 120, 146}
+
+	w := meteoswiss.Convert(s)
+
+	d := New([]config.Place{{Name: "Office", Zip: "3006"}, {Zip: "8001"}}, func(ctx context.Context, zip string) (*weather.Weather, string, error) {
+		return w, "Bern", nil
+	}, time.Minute)
+
+	d.now = func() time.Time { return time.UnixMilli(1683410400000).Add(30 * time.Minute) }
+	d.places[0].weather = w
+	d.places[0].location = "Bern"
+
+	return d
+}
+
+func TestRender(t *testing.T) {
+	d := testDashboard()
+
+	out := d.Render(80, 40)
+
+	expected := []string{
+		"sunly  Office · Bern (3006)",
+		"1/2",
+		"☀ 17.0 °C  sunny",
+		"Wind 11 km/h E",
+		"⚡ 11/18°",
+		"12.7 mm",
+		"Temperature (°C)",
+		"15.0 │",
+		"Precipitation (mm/h)",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Errorf("Expected dashboard to contain %q, got:\n%s", e, out)
+		}
+	}
+
+	for _, l := range strings.Split(out, "\r\n") {
+		if n := len([]rune(l)); n > 80 {
+			t.Errorf("Expected lines to fit into 80 columns, but got %d", n)
+		}
+	}
+
+	if n := len(strings.Split(d.Render(80, 5), "\r\n")); n != 5 {
+		t.Errorf("Expected 5 lines, but got %d", n)
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	d := testDashboard()
+
+	d.handleKey("right")
+	if d.selected != 1 {
+		t.Errorf("Expected the second place to be selected, but got %d", d.selected)
+	}
+
+	d.handleKey("right")
+	if d.selected != 0 {
+		t.Errorf("Expected the selection to wrap around, but got %d", d.selected)
+	}
+
+	d.handleKey("2")
+	if d.selected != 1 || !strings.Contains(d.Render(80, 10), "Loading") {
+		t.Errorf("Expected the second place to be loading")
+	}
+
+	if d.handleKey("q") {
+		t.Errorf("Expected q to quit")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s        string
+		width    int
+		expected string
+	}{
+		{"sunny", 10, "sunny"},
+		{"sunny", 3, "sun"},
+		{"°C °C", 2, "°C"},
+		// Colors take no space and a cut line is reset
+		{"\x1b[31mhot\x1b[0m", 3, "\x1b[31mhot\x1b[0m"},
+		{"\x1b[31mhot\x1b[0m cold", 4, "\x1b[31mhot\x1b[0m \x1b[0m"},
+		{"\x1b[38;2;255;0;0mhot\x1b[0m", 2, "\x1b[38;2;255;0;0mho\x1b[0m"},
+	}
+
+	for _, test := range tests {
+		if got := truncate(test.s, test.width); got != test.expected {
+			t.Errorf("Expected %q cut to %d to be %q, but got %q", test.s, test.width, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package ensemble combines the forecasts of several providers into a
+// consensus with the spread between them.
+package ensemble
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+const (
+	// Spread of a daily temperature from which the sources disagree, in °C.
+	TemperatureDisagreement = 4.0
+	// Spread of the daily precipitation from which the sources disagree, in mm.
+	PrecipitationDisagreement = 5.0
+)
+
+// Member is the forecast of one source.
+type Member struct {
+	Source  string
+	Weather *weather.Weather
+}
+
+// Stats summarizes the values of the sources.
+type Stats struct {
+	Mean float64 `json:"mean"`
+	// Difference between the highest and the lowest value.
+	Spread float64 `json:"spread"`
+	// Lowest and highest value, including the uncertainty bands of the sources
+	// that have them.
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// SourceDay is the daily forecast of a single source.
+type SourceDay struct {
+	Source         string  `json:"source"`
+	TemperatureMin float64 `json:"temperatureMin"`
+	TemperatureMax float64 `json:"temperatureMax"`
+	Precipitation  float64 `json:"precipitation"`
+}
+
+// Day is the consensus of the daily forecasts.
+type Day struct {
+	DayDate        string      `json:"dayDate"`
+	TemperatureMin Stats       `json:"temperatureMin"`
+	TemperatureMax Stats       `json:"temperatureMax"`
+	Precipitation  Stats       `json:"precipitation"`
+	Sources        []SourceDay `json:"sources"`
+	// Whether the spread of a value exceeds its disagreement threshold.
+	Disagree bool `json:"disagree"`
+}
+
+// SourceHour is the hourly forecast of a single source.
+type SourceHour struct {
+	Source        string  `json:"source"`
+	Temperature   float64 `json:"temperature"`
+	Precipitation float64 `json:"precipitation"`
+}
+
+// Hour is the consensus of the hourly forecasts.
+type Hour struct {
+	Time          time.Time    `json:"time"`
+	Temperature   Stats        `json:"temperature"`
+	Precipitation Stats        `json:"precipitation"`
+	Sources       []SourceHour `json:"sources"`
+}
+
+// Ensemble is the consensus of the forecasts of all sources.
+type Ensemble struct {
+	Sources []string `json:"sources"`
+	// Latest update of the current weather of the sources.
+	UpdatedAt time.Time `json:"updatedAt"`
+	Days      []Day     `json:"days"`
+	Hours     []Hour    `json:"hours"`
+}
+
+// Combines the forecasts of the members. Days are aligned by their date and
+// hours by their start, values missing in a source are left out of the stats.
+func New(members []Member) Ensemble {
+	e := Ensemble{Sources: []string{}, Days: []Day{}, Hours: []Hour{}}
+
+	days := map[string]*Day{}
+	hours := map[int64]*Hour{}
+
+	// Bands of the hourly values, as not all sources have them
+	type band struct{ temperature, precipitation []float64 }
+
+	bands := map[int64]*band{}
+
+	for _, m := range members {
+		e.Sources = append(e.Sources, m.Source)
+
+		if m.Weather.UpdatedAt.After(e.UpdatedAt) {
+			e.UpdatedAt = m.Weather.UpdatedAt
+		}
+
+		for _, d := range m.Weather.Days {
+			day, ok := days[d.DayDate]
+			if !ok {
+				day = &Day{DayDate: d.DayDate}
+				days[d.DayDate] = day
+			}
+
+			day.Sources = append(day.Sources, SourceDay{
+				Source:         m.Source,
+				TemperatureMin: d.TemperatureMin,
+				TemperatureMax: d.TemperatureMax,
+				Precipitation:  d.Precipitation,
+			})
+		}
+
+		for _, h := range m.Weather.Hours {
+			t := h.Time.Truncate(time.Hour).Unix()
+
+			hour, ok := hours[t]
+			if !ok {
+				hour = &Hour{Time: time.Unix(t, 0)}
+				hours[t] = hour
+				bands[t] = &band{}
+			}
+
+			hour.Sources = append(hour.Sources, SourceHour{
+				Source:        m.Source,
+				Temperature:   h.TemperatureMean,
+				Precipitation: h.Precipitation,
+			})
+
+			// Missing bands are zero, so only bands around the value are used
+			b := bands[t]
+			if h.TemperatureMin <= h.TemperatureMean && h.TemperatureMean <= h.TemperatureMax {
+				b.temperature = append(b.temperature, h.TemperatureMin, h.TemperatureMax)
+			}
+
+			if h.PrecipitationMin <= h.Precipitation && h.Precipitation <= h.PrecipitationMax {
+				b.precipitation = append(b.precipitation, h.PrecipitationMin, h.PrecipitationMax)
+			}
+		}
+	}
+
+	for _, d := range days {
+		var min, max, precipitation []float64
+
+		for _, s := range d.Sources {
+			min = append(min, s.TemperatureMin)
+			max = append(max, s.TemperatureMax)
+			precipitation = append(precipitation, s.Precipitation)
+		}
+
+		d.TemperatureMin = stats(min, nil)
+		d.TemperatureMax = stats(max, nil)
+		d.Precipitation = stats(precipitation, nil)
+		d.Disagree = len(d.Sources) > 1 && (d.TemperatureMin.Spread >= TemperatureDisagreement ||
+			d.TemperatureMax.Spread >= TemperatureDisagreement ||
+			d.Precipitation.Spread >= PrecipitationDisagreement)
+
+		e.Days = append(e.Days, *d)
+	}
+
+	for t, h := range hours {
+		var temperature, precipitation []float64
+
+		for _, s := range h.Sources {
+			temperature = append(temperature, s.Temperature)
+			precipitation = append(precipitation, s.Precipitation)
+		}
+
+		h.Temperature = stats(temperature, bands[t].temperature)
+		h.Precipitation = stats(precipitation, bands[t].precipitation)
+
+		e.Hours = append(e.Hours, *h)
+	}
+
+	sort.Slice(e.Days, func(i, j int) bool { return e.Days[i].DayDate < e.Days[j].DayDate })
+	sort.Slice(e.Hours, func(i, j int) bool { return e.Hours[i].Time.Before(e.Hours[j].Time) })
+
+	return e
+}
+
+// Returns the stats of the values, with the band widening the low and high.
+func stats(values []float64, band []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+
+	s := Stats{}
+
+	lowest, highest := math.Inf(1), math.Inf(-1)
+
+	for _, v := range values {
+		s.Mean += v
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+	}
+
+	s.Mean /= float64(len(values))
+	s.Spread = highest - lowest
+	s.Low = lowest
+	s.High = highest
+
+	for _, v := range band {
+		s.Low = math.Min(s.Low, v)
+		s.High = math.Max(s.High, v)
+	}
+
+	return s
+}
@@ -0,0 +1,99 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ensemble
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestNew(t *testing.T) {
+	start := time.UnixMilli(1683410400000)
+
+	meteoswiss := &weather.Weather{
+		UpdatedAt: start,
+		Days: []weather.Day{
+			{DayDate: "2023-05-07", TemperatureMin: 10, TemperatureMax: 20, Precipitation: 1},
+			{DayDate: "2023-05-08", TemperatureMin: 11, TemperatureMax: 19, Precipitation: 0},
+		},
+		Hours: []weather.Hour{
+			{Time: start, TemperatureMean: 12, TemperatureMin: 11, TemperatureMax: 14, Precipitation: 0.5, PrecipitationMax: 1},
+			{Time: start.Add(time.Hour), TemperatureMean: 13},
+		},
+	}
+
+	openmeteo := &weather.Weather{
+		UpdatedAt: start.Add(time.Hour),
+		Days: []weather.Day{
+			{DayDate: "2023-05-08", TemperatureMin: 10, TemperatureMax: 25, Precipitation: 2},
+		},
+		Hours: []weather.Hour{
+			{Time: start, TemperatureMean: 10, TemperatureMin: 10, TemperatureMax: 10},
+		},
+	}
+
+	e := New([]Member{{"meteoswiss", meteoswiss}, {"openmeteo", openmeteo}})
+
+	if !e.UpdatedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the latest update, but got %s", e.UpdatedAt)
+	}
+
+	if len(e.Days) != 2 {
+		t.Fatalf("Expected 2 days, but got %d", len(e.Days))
+	}
+
+	// The first day only has one source
+	if len(e.Days[0].Sources) != 1 || e.Days[0].Disagree {
+		t.Errorf("Expected a single source that can't disagree, but got %+v", e.Days[0])
+	}
+
+	d := e.Days[1]
+	if d.TemperatureMax.Mean != 22 || d.TemperatureMax.Spread != 6 {
+		t.Errorf("Expected mean 22 and spread 6, but got %+v", d.TemperatureMax)
+	}
+
+	if !d.Disagree {
+		t.Errorf("Expected the sources to disagree on %s", d.DayDate)
+	}
+
+	if len(e.Hours) != 2 {
+		t.Fatalf("Expected 2 hours, but got %d", len(e.Hours))
+	}
+
+	h := e.Hours[0]
+	if h.Temperature.Mean != 11 || h.Temperature.Spread != 2 {
+		t.Errorf("Expected mean 11 and spread 2, but got %+v", h.Temperature)
+	}
+
+	// The band of MeteoSwiss widens the range
+	if h.Temperature.Low != 10 || h.Temperature.High != 14 {
+		t.Errorf("Expected the range 10 to 14, but got %+v", h.Temperature)
+	}
+
+	if h.Precipitation.High != 1 {
+		t.Errorf("Expected the precipitation band up to 1, but got %+v", h.Precipitation)
+	}
+
+	// A missing band is ignored
+	if e.Hours[1].Temperature.Low != 13 {
+		t.Errorf("Expected the missing band to be ignored, but got %+v", e.Hours[1].Temperature)
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package exporter serves the weather of a few locations as Prometheus metrics.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darox/sunly/internal/server"
+	"github.com/darox/sunly/internal/weather"
+)
+
+// How long a scrape waits for the upstream APIs, below the default scrape
+// timeout of Prometheus.
+const defaultFetchTimeout = 8 * time.Second
+
+// Exporter collects the metrics of the configured zip codes.
+type Exporter struct {
+	source       server.Source
+	zips         []string
+	ttl          time.Duration
+	fetchTimeout time.Duration
+	now          func() time.Time
+
+	mu       sync.Mutex
+	places   map[string]place
+	scrapes  int
+	failures map[string]int
+}
+
+// place is the last successfully fetched data of a zip code.
+type place struct {
+	location  string
+	canton    string
+	weather   *weather.Weather
+	fetchedAt time.Time
+}
+
+// Creates an exporter for the zip codes that refetches the data from the source
+// once it is older than ttl.
+func New(source server.Source, zips []string, ttl time.Duration) *Exporter {
+	return &Exporter{
+		source:       source,
+		zips:         zips,
+		ttl:          ttl,
+		fetchTimeout: defaultFetchTimeout,
+		now:          time.Now,
+		places:       map[string]place{},
+		failures:     map[string]int{},
+	}
+}
+
+// Serves the metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+
+	e.Collect(r.Context(), &b)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(b.Bytes())
+}
+
+// Refreshes outdated data and writes all metrics to the buffer. The zip codes
+// are fetched concurrently, so that a slow upstream only delays a scrape up to
+// the fetch timeout.
+func (e *Exporter) Collect(ctx context.Context, b *bytes.Buffer) {
+	e.mu.Lock()
+	e.scrapes++
+
+	start := e.now()
+
+	outdated := []string{}
+
+	for _, zip := range e.zips {
+		if p, ok := e.places[zip]; !ok || e.now().Sub(p.fetchedAt) >= e.ttl {
+			outdated = append(outdated, zip)
+		}
+	}
+
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, e.fetchTimeout)
+	defer cancel()
+
+	type result struct {
+		zip   string
+		place place
+		err   error
+	}
+
+	results := make(chan result, len(outdated))
+
+	for _, zip := range outdated {
+		go func(zip string) {
+			p, err := e.fetch(ctx, zip)
+			results <- result{zip, p, err}
+		}(zip)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for range outdated {
+		r := <-results
+
+		if r.err != nil {
+			// Keep serving the last known values
+			e.failures[r.zip]++
+			continue
+		}
+
+		e.places[r.zip] = r.place
+	}
+
+	e.write(b, e.now().Sub(start))
+}
+
+func (e *Exporter) fetch(ctx context.Context, zip string) (place, error) {
+	ld, err := e.source.Locations(ctx, zip)
+	if err != nil {
+		return place{}, err
+	}
+
+	p := place{fetchedAt: e.now()}
+	l := weather.Location{Zip: zip}
+
+	for _, r := range ld.Records {
+		if r.Fields.Postleitzahl == zip {
+			p.location = r.Fields.Ortbez18
+			p.canton = r.Fields.Kanton
+			l, _ = weather.LocationOf(ld, zip)
+
+			break
+		}
+	}
+
+	p.weather, err = e.source.Weather(ctx, l)
+	if err != nil {
+		return place{}, err
+	}
+
+	return p, nil
+}
+
+func (e *Exporter) write(b *bytes.Buffer, duration time.Duration) {
+	zips := make([]string, 0, len(e.places))
+	for zip := range e.places {
+		zips = append(zips, zip)
+	}
+
+	sort.Strings(zips)
+
+	gauge := func(name string, help string, value func(zip string, p place, m *metric)) {
+		m := &metric{name: name, help: help, kind: "gauge"}
+
+		for _, zip := range zips {
+			value(zip, e.places[zip], m)
+		}
+
+		m.write(b)
+	}
+
+	gauge("sunly_temperature_celsius", "Current temperature.", func(zip string, p place, m *metric) {
+		m.add(p.labels(zip), p.weather.Current.Temperature)
+	})
+
+	gauge("sunly_precipitation_mm", "Precipitation expected in the current hour.", func(zip string, p place, m *metric) {
+		if h, ok := p.currentHour(e.now()); ok {
+			m.add(p.labels(zip), h.Precipitation)
+		}
+	})
+
+	gauge("sunly_wind_speed_kmh", "Wind speed expected in the current hour.", func(zip string, p place, m *metric) {
+		if h, ok := p.currentHour(e.now()); ok {
+			m.add(p.labels(zip), h.WindSpeed)
+		}
+	})
+
+	gauge("sunly_forecast_temperature_max_celsius", "Forecast maximum temperature of the day.",
+		func(zip string, p place, m *metric) {
+			for _, d := range p.weather.Days {
+				m.add(append(p.labels(zip), "day", d.DayDate), d.TemperatureMax)
+			}
+		})
+
+	gauge("sunly_forecast_temperature_min_celsius", "Forecast minimum temperature of the day.",
+		func(zip string, p place, m *metric) {
+			for _, d := range p.weather.Days {
+				m.add(append(p.labels(zip), "day", d.DayDate), d.TemperatureMin)
+			}
+		})
+
+	gauge("sunly_forecast_precipitation_mm", "Forecast precipitation of the day.", func(zip string, p place, m *metric) {
+		for _, d := range p.weather.Days {
+			m.add(append(p.labels(zip), "day", d.DayDate), d.Precipitation)
+		}
+	})
+
+	gauge("sunly_sunrise_timestamp_seconds", "Time of the sunrise today.", func(zip string, p place, m *metric) {
+		if len(p.weather.Sunrise) > 0 {
+			m.add(p.labels(zip), float64(p.weather.Sunrise[0].UnixMilli())/1000)
+		}
+	})
+
+	gauge("sunly_sunset_timestamp_seconds", "Time of the sunset today.", func(zip string, p place, m *metric) {
+		if len(p.weather.Sunset) > 0 {
+			m.add(p.labels(zip), float64(p.weather.Sunset[0].UnixMilli())/1000)
+		}
+	})
+
+	gauge("sunly_weather_updated_timestamp_seconds", "Time of the last update of the current weather.",
+		func(zip string, p place, m *metric) {
+			m.add(p.labels(zip), float64(p.weather.UpdatedAt.UnixMilli())/1000)
+		})
+
+	scrapes := &metric{name: "sunly_scrapes_total", help: "Number of scrapes of the exporter.", kind: "counter"}
+	scrapes.add(nil, float64(e.scrapes))
+	scrapes.write(b)
+
+	failures := &metric{
+		name: "sunly_upstream_errors_total",
+		help: "Number of failed requests to the upstream APIs.",
+		kind: "counter",
+	}
+
+	for _, zip := range e.zips {
+		failures.add([]string{"zip", zip}, float64(e.failures[zip]))
+	}
+
+	failures.write(b)
+
+	d := &metric{name: "sunly_scrape_duration_seconds", help: "Duration of the last scrape.", kind: "gauge"}
+	d.add(nil, duration.Seconds())
+	d.write(b)
+}
+
+func (p place) labels(zip string) []string {
+	return []string{"zip", zip, "location", p.location, "canton", p.canton}
+}
+
+// Returns the graph values of the hour that contains now.
+func (p place) currentHour(now time.Time) (weather.Hour, bool) {
+	for _, h := range p.weather.Hours {
+		if !now.Before(h.Time) && now.Before(h.End()) {
+			return h, true
+		}
+	}
+
+	return weather.Hour{}, false
+}
+
+// metric is a metric family in the Prometheus text format.
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	samples []string
+}
+
+// Adds a sample with labels given as name and value pairs.
+func (m *metric) add(labels []string, value float64) {
+	var s strings.Builder
+
+	s.WriteString(m.name)
+
+	if len(labels) > 0 {
+		s.WriteString("{")
+
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				s.WriteString(",")
+			}
+
+			fmt.Fprintf(&s, "%s=\"%s\"", labels[i], escape(labels[i+1]))
+		}
+
+		s.WriteString("}")
+	}
+
+	s.WriteString(" ")
+	s.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+
+	m.samples = append(m.samples, s.String())
+}
+
+func (m *metric) write(b *bytes.Buffer) {
+	fmt.Fprintf(b, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", m.name, m.kind)
+
+	for _, s := range m.samples {
+		b.WriteString(s)
+		b.WriteString("\n")
+	}
+}
+
+// Escapes a label value as required by the text format.
+func escape(v string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(v)
+}
@@ -0,0 +1,170 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/internal/weather/meteoswiss"
+	"github.com/darox/sunly/pkg/swissmeteo"
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+type fakeSource struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+	// Zip codes whose weather only returns when the context is done.
+	slow map[string]bool
+}
+
+func (f *fakeSource) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+
+	if f.slow[l.Zip] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	s := &swissmeteo.Weather{}
+	s.CurrentWeather.Time = 1683452400000
+	s.CurrentWeather.Temperature = 17
+	s.Forecast = []swissmeteo.Day{{DayDate: "2023-05-07", TemperatureMax: 18, TemperatureMin: 11, Precipitation: 12.7}}
+	s.Graph.Start = 1683410400000
+	s.Graph.StartLowResolution = 1683410400000
+	s.Graph.TemperatureMean1H = []float64{14.1, 13.7}
+	s.Graph.Precipitation1H = []float64{1.3, 0.4}
+	s.Graph.WindSpeed3H = []float64{10.6}
+	s.Graph.Sunrise = []int64{1683432367173}
+
+	w := meteoswiss.Convert(s)
+
+	return w, nil
+}
+
+func (f *fakeSource) Locations(ctx context.Context, query string) (*swisspost.LocationData, error) {
+	ld := &swisspost.LocationData{}
+
+	err := json.Unmarshal([]byte(`{
+		"nhits": 2,
+		"records": [
+			{"fields": {"postleitzahl": "5242", "ortbez18": "Birr", "kanton": "AG"}},
+			{"fields": {"postleitzahl": "3006", "ortbez18": "Bern", "kanton": "BE"}}
+		]
+	}`), ld)
+
+	return ld, err
+}
+
+func scrape(t *testing.T, e *Exporter) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	b, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return string(b)
+}
+
+func TestMetrics(t *testing.T) {
+	src := &fakeSource{}
+	e := New(src, []string{"3006"}, time.Minute)
+	e.now = func() time.Time { return time.UnixMilli(1683410400000).Add(30 * time.Minute) }
+
+	out := scrape(t, e)
+
+	expected := []string{
+		"# TYPE sunly_temperature_celsius gauge",
+		`sunly_temperature_celsius{zip="3006",location="Bern",canton="BE"} 17`,
+		`sunly_precipitation_mm{zip="3006",location="Bern",canton="BE"} 1.3`,
+		`sunly_wind_speed_kmh{zip="3006",location="Bern",canton="BE"} 10.6`,
+		`sunly_forecast_temperature_max_celsius{zip="3006",location="Bern",canton="BE",day="2023-05-07"} 18`,
+		`sunly_sunrise_timestamp_seconds{zip="3006",location="Bern",canton="BE"} 1.683432367173e+09`,
+		"sunly_scrapes_total 1",
+		`sunly_upstream_errors_total{zip="3006"} 0`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+
+	// The data is still fresh, so the second scrape must not fetch again
+	scrape(t, e)
+
+	if src.calls != 1 {
+		t.Errorf("Expected one upstream call, but got %d", src.calls)
+	}
+}
+
+func TestUpstreamErrors(t *testing.T) {
+	e := New(&fakeSource{err: errors.New("boom")}, []string{"3006"}, time.Minute)
+
+	scrape(t, e)
+	out := scrape(t, e)
+
+	if !strings.Contains(out, `sunly_upstream_errors_total{zip="3006"} 2`) {
+		t.Errorf("Expected two upstream errors, got:\n%s", out)
+	}
+
+	if strings.Contains(out, "sunly_temperature_celsius{") {
+		t.Errorf("Expected no temperature without data, got:\n%s", out)
+	}
+}
+
+func TestSlowUpstream(t *testing.T) {
+	e := New(&fakeSource{slow: map[string]bool{"5242": true}}, []string{"3006", "5242"}, time.Minute)
+	e.fetchTimeout = 20 * time.Millisecond
+
+	out := scrape(t, e)
+
+	if !strings.Contains(out, `sunly_temperature_celsius{zip="3006",location="Bern",canton="BE"} 17`) {
+		t.Errorf("Expected the metrics of the fast zip code, but got\n%s", out)
+	}
+
+	if !strings.Contains(out, `sunly_upstream_errors_total{zip="5242"} 1`) {
+		t.Errorf("Expected the slow zip code to time out, but got\n%s", out)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("Unexpected escaped value %s", got)
+	}
+}
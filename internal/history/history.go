@@ -0,0 +1,471 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package history keeps a local record of observed weather, since MeteoSwiss
+// only returns the present and the future.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Observation is the weather of a location at a point in time.
+type Observation struct {
+	Zip      string `json:"zip"`
+	Location string `json:"location"`
+	// Time MeteoSwiss updated the current weather.
+	Time        time.Time `json:"time"`
+	RecordedAt  time.Time `json:"recordedAt"`
+	Temperature float64   `json:"temperature"`
+	Icon        int       `json:"icon"`
+	// Graph values of the hour the observation falls into.
+	Hour weather.Hour `json:"hour"`
+}
+
+// Day aggregates the observations of a day.
+type Day struct {
+	Date            string  `json:"date"`
+	TemperatureMin  float64 `json:"temperatureMin"`
+	TemperatureMax  float64 `json:"temperatureMax"`
+	TemperatureMean float64 `json:"temperatureMean"`
+	Precipitation   float64 `json:"precipitation"`
+	Observations    int     `json:"observations"`
+}
+
+var zipPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// Store keeps the observations of each zip code in an append only file with
+// one JSON object per line.
+type Store struct {
+	Dir string
+	// Logs the lines that are skipped as they can't be parsed, the default
+	// logger if nil.
+	Logger *slog.Logger
+}
+
+// Returns the default directory of the history, in XDG_DATA_HOME or
+// ~/.local/share.
+func DefaultDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error finding the home directory: %w", err)
+		}
+
+		dir = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dir, "sunly", "history"), nil
+}
+
+// Creates the observation of the weather at the given time.
+func NewObservation(zip string, location string, w *weather.Weather, now time.Time) Observation {
+	o := Observation{
+		Zip:         zip,
+		Location:    location,
+		Time:        w.UpdatedAt,
+		RecordedAt:  now,
+		Temperature: w.Current.Temperature,
+		Icon:        w.Current.Icon,
+	}
+
+	hours := w.Hours
+
+	for _, h := range hours {
+		if !h.Time.After(o.Time) && h.End().After(o.Time) {
+			o.Hour = h
+			return o
+		}
+	}
+
+	if len(hours) > 0 {
+		o.Hour = hours[0]
+	}
+
+	return o
+}
+
+// Returns the path of the file of the zip code with the given suffix. The zip
+// code is checked, as it becomes part of the path.
+func (s *Store) path(zip string, suffix string) (string, error) {
+	if !zipPattern.MatchString(zip) {
+		return "", fmt.Errorf("invalid zip code %q", zip)
+	}
+
+	return filepath.Join(s.Dir, zip+suffix), nil
+}
+
+func (s *Store) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+
+	return s.Logger
+}
+
+// Appends the observation to the history of its zip code. It returns false
+// without writing anything if the last observation has the same time, as
+// MeteoSwiss didn't update the weather in between.
+func (s *Store) Append(o Observation) (bool, error) {
+	path, err := s.path(o.Zip, ".jsonl")
+	if err != nil {
+		return false, err
+	}
+
+	last, ok, err := lastLine[Observation](path, s.logger())
+	if err != nil {
+		return false, err
+	}
+
+	if ok && last.Time.Equal(o.Time) {
+		return false, nil
+	}
+
+	return true, s.appendLine(path, o)
+}
+
+// Returns the observations of the zip code between since and until, ordered
+// by time. A zero since or until leaves the range open on that side.
+func (s *Store) Query(zip string, since time.Time, until time.Time) ([]Observation, error) {
+	path, err := s.path(zip, ".jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	obs := []Observation{}
+
+	err = readLines(path, s.logger(), func(o Observation) {
+		if inRange(o.Time, since, until) {
+			obs = append(obs, o)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(obs, func(i, j int) bool { return obs[i].Time.Before(obs[j].Time) })
+
+	return obs, nil
+}
+
+// Reports whether t is in the range, where a zero since or until leaves the
+// range open on that side.
+func inRange(t time.Time, since time.Time, until time.Time) bool {
+	return (since.IsZero() || !t.Before(since)) && (until.IsZero() || t.Before(until))
+}
+
+// Appends the value as a line of JSON to the file. A file that doesn't end
+// with a newline was cut off while appending, so the line starts on a new line
+// and the cut off one is skipped when reading.
+func (s *Store) appendLine(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(s.Dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening history: %w", err)
+	}
+
+	cut, err := endsWithoutNewline(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error reading history: %w", err)
+	}
+
+	if cut {
+		b = append([]byte{'\n'}, b...)
+	}
+
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error writing history: %w", err)
+	}
+
+	return f.Close()
+}
+
+// Reports whether the file has content that doesn't end with a newline.
+func endsWithoutNewline(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+
+	last := make([]byte, 1)
+
+	_, err = f.ReadAt(last, info.Size()-1)
+	if err != nil {
+		return false, err
+	}
+
+	return last[0] != '\n', nil
+}
+
+// Parses every line of the file and passes it to add. A missing file has no
+// lines. Lines that can't be parsed were cut off by a crash while appending, so
+// they are logged and skipped instead of making the whole history unreadable.
+func readLines[T any](path string, logger *slog.Logger, add func(T)) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error opening history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	// Forecast snapshots are longer than the default limit of a line
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var v T
+
+		err = json.Unmarshal(scanner.Bytes(), &v)
+		if err != nil {
+			logger.Warn("skipping malformed history line", "path", path, "line", line, "error", err)
+			continue
+		}
+
+		add(v)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return fmt.Errorf("error reading history: %w", err)
+	}
+
+	return nil
+}
+
+// Parses the last line of the file. It reads the file backwards from its end,
+// so appending doesn't get slower as the history grows. A missing or empty file
+// has no last line, neither has a file whose last line can't be parsed, which
+// is logged.
+func lastLine[T any](path string, logger *slog.Logger) (T, bool, error) {
+	var v T
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return v, false, nil
+	}
+
+	if err != nil {
+		return v, false, fmt.Errorf("error opening history: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return v, false, fmt.Errorf("error reading history: %w", err)
+	}
+
+	var tail []byte
+
+	// Read ever larger chunks until the tail holds a whole line
+	for pos, size := info.Size(), int64(4096); pos > 0; size *= 2 {
+		n := min(size, pos)
+		pos -= n
+
+		chunk := make([]byte, n)
+
+		_, err = f.ReadAt(chunk, pos)
+		if err != nil {
+			return v, false, fmt.Errorf("error reading history: %w", err)
+		}
+
+		tail = append(chunk, tail...)
+		trimmed := bytes.TrimRight(tail, "\r\n")
+
+		i := bytes.LastIndexByte(trimmed, '\n')
+		if i < 0 && pos > 0 {
+			continue
+		}
+
+		line := trimmed[i+1:]
+		if len(line) == 0 {
+			return v, false, nil
+		}
+
+		err = json.Unmarshal(line, &v)
+		if err != nil {
+			logger.Warn("skipping malformed last history line", "path", path, "error", err)
+
+			var zero T
+
+			return zero, false, nil
+		}
+
+		return v, true, nil
+	}
+
+	return v, false, nil
+}
+
+// Aggregates the observations by day in the location of loc. The
+// precipitation of an hour is counted once, from its latest observation.
+func Daily(obs []Observation, loc *time.Location) []Day {
+	days := []Day{}
+	index := map[string]int{}
+	sums := map[string]float64{}
+	rain := map[string]map[int64]float64{}
+
+	for _, o := range obs {
+		date := o.Time.In(loc).Format("2006-01-02")
+
+		i, ok := index[date]
+		if !ok {
+			i = len(days)
+			index[date] = i
+			days = append(days, Day{Date: date, TemperatureMin: o.Temperature, TemperatureMax: o.Temperature})
+			rain[date] = map[int64]float64{}
+		}
+
+		d := &days[i]
+		d.TemperatureMin = math.Min(d.TemperatureMin, o.Temperature)
+		d.TemperatureMax = math.Max(d.TemperatureMax, o.Temperature)
+		d.Observations++
+		sums[date] += o.Temperature
+
+		if !o.Hour.Time.IsZero() {
+			rain[date][o.Hour.Time.Unix()] = o.Hour.Precipitation
+		}
+	}
+
+	for i := range days {
+		d := &days[i]
+		d.TemperatureMean = math.Round(sums[d.Date]/float64(d.Observations)*10) / 10
+
+		for _, p := range rain[d.Date] {
+			d.Precipitation += p
+		}
+
+		d.Precipitation = math.Round(d.Precipitation*10) / 10
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	return days
+}
+
+// Parses the start of a query, either a duration back from now like "7d" or
+// "12h", or a date like "2023-05-01" in the location of now.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, expected e.g. 7d, 12h or 2023-05-01", s)
+}
+
+// Writes the observations as CSV with a header row.
+func WriteCSV(w io.Writer, obs []Observation) error {
+	c := csv.NewWriter(w)
+
+	_ = c.Write([]string{"zip", "location", "time", "temperature", "icon", "precipitation", "wind_speed", "wind_direction"})
+
+	for _, o := range obs {
+		_ = c.Write([]string{
+			o.Zip,
+			o.Location,
+			o.Time.Format(time.RFC3339),
+			strconv.FormatFloat(o.Temperature, 'f', -1, 64),
+			strconv.Itoa(o.Icon),
+			strconv.FormatFloat(o.Hour.Precipitation, 'f', -1, 64),
+			strconv.FormatFloat(o.Hour.WindSpeed, 'f', -1, 64),
+			strconv.Itoa(o.Hour.WindDirection),
+		})
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
+
+// Writes the daily aggregates as CSV with a header row.
+func WriteDailyCSV(w io.Writer, days []Day) error {
+	c := csv.NewWriter(w)
+
+	_ = c.Write([]string{"date", "temperature_min", "temperature_max", "temperature_mean", "precipitation", "observations"})
+
+	for _, d := range days {
+		_ = c.Write([]string{
+			d.Date,
+			strconv.FormatFloat(d.TemperatureMin, 'f', -1, 64),
+			strconv.FormatFloat(d.TemperatureMax, 'f', -1, 64),
+			strconv.FormatFloat(d.TemperatureMean, 'f', -1, 64),
+			strconv.FormatFloat(d.Precipitation, 'f', -1, 64),
+			strconv.Itoa(d.Observations),
+		})
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
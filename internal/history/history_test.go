@@ -0,0 +1,247 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package history
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestNewObservation(t *testing.T) {
+	start := time.UnixMilli(1683410400000)
+
+	w := &weather.Weather{
+		UpdatedAt: start.Add(90 * time.Minute),
+		Current:   weather.Current{Temperature: 13.9},
+		Hours: []weather.Hour{
+			{Time: start, TemperatureMean: 14.1},
+			{Time: start.Add(time.Hour), TemperatureMean: 13.7, Precipitation: 0.4},
+			{Time: start.Add(2 * time.Hour), TemperatureMean: 13.3, Precipitation: 1.2},
+		},
+	}
+
+	o := NewObservation("3006", "Bern", w, time.Now())
+
+	if o.Temperature != 13.9 || o.Hour.Precipitation != 0.4 || o.Hour.TemperatureMean != 13.7 {
+		t.Errorf("Expected the observation to hold the second hour, but got %+v", o)
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := &Store{Dir: filepath.Join(t.TempDir(), "history")}
+	start := time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC)
+
+	for i, temperature := range []float64{10, 12, 15} {
+		added, err := s.Append(Observation{Zip: "3006", Time: start.Add(time.Duration(i) * time.Hour), Temperature: temperature})
+		if err != nil || !added {
+			t.Fatalf("Expected the observation to be added, but got %v, %v", added, err)
+		}
+	}
+
+	added, err := s.Append(Observation{Zip: "3006", Time: start.Add(2 * time.Hour), Temperature: 15})
+	if err != nil || added {
+		t.Errorf("Expected an unchanged observation to be skipped, but got %v, %v", added, err)
+	}
+
+	obs, err := s.Query("3006", start.Add(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(obs) != 2 || obs[0].Temperature != 12 || obs[1].Temperature != 15 {
+		t.Errorf("Expected the last two observations, but got %+v", obs)
+	}
+
+	obs, err = s.Query("8001", time.Time{}, time.Time{})
+	if err != nil || len(obs) != 0 {
+		t.Errorf("Expected no observations of an unknown zip code, but got %+v, %v", obs, err)
+	}
+}
+
+func TestTornLine(t *testing.T) {
+	var logs bytes.Buffer
+
+	s := &Store{Dir: t.TempDir(), Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	start := time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC)
+
+	// The last append was cut off by a crash
+	err := os.WriteFile(filepath.Join(s.Dir, "3006.jsonl"),
+		[]byte("{\"zip\":\"3006\",\"time\":\"2023-05-07T06:00:00Z\"}\n{\"zip\":\"30"), 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	obs, err := s.Query("3006", time.Time{}, time.Time{})
+	if err != nil || len(obs) != 1 {
+		t.Errorf("Expected the torn line to be skipped, but got %+v, %v", obs, err)
+	}
+
+	added, err := s.Append(Observation{Zip: "3006", Time: start.Add(time.Hour), Temperature: 12})
+	if err != nil || !added {
+		t.Fatalf("Expected the observation to be added after a torn line, but got %v, %v", added, err)
+	}
+
+	obs, err = s.Query("3006", time.Time{}, time.Time{})
+	if err != nil || len(obs) != 2 || obs[1].Temperature != 12 {
+		t.Errorf("Expected the observation before and after the torn line, but got %+v, %v", obs, err)
+	}
+
+	if !strings.Contains(logs.String(), "malformed") {
+		t.Errorf("Expected the torn line to be logged, but got %q", logs.String())
+	}
+}
+
+func TestInvalidZip(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	for _, zip := range []string{"", "../3006", "30061"} {
+		if _, err := s.Append(Observation{Zip: zip}); err == nil {
+			t.Errorf("Expected an error for the zip code %q", zip)
+		}
+
+		if _, err := s.Query(zip, time.Time{}, time.Time{}); err == nil {
+			t.Errorf("Expected an error for the zip code %q", zip)
+		}
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "3006.jsonl")
+
+	_, ok, err := lastLine[Observation](path, slog.Default())
+	if err != nil || ok {
+		t.Errorf("Expected no last line of a missing file, but got %v, %v", ok, err)
+	}
+
+	// The last line is longer than the first chunk that is read
+	long := strings.Repeat("x", 10000)
+	content := "{\"zip\":\"3006\",\"location\":\"Bern\"}\n{\"zip\":\"3006\",\"location\":\"" + long + "\"}\n\n"
+
+	err = os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	o, ok, err := lastLine[Observation](path, slog.Default())
+	if err != nil || !ok || o.Location != long {
+		t.Errorf("Expected the long last line, but got %v, %v with %d characters", ok, err, len(o.Location))
+	}
+
+	err = os.WriteFile(path, []byte("{\"zip\":\"3006\",\"location\":\"Bern\"}"), 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	o, ok, err = lastLine[Observation](path, slog.Default())
+	if err != nil || !ok || o.Location != "Bern" {
+		t.Errorf("Expected a single line without a newline, but got %+v, %v, %v", o, ok, err)
+	}
+}
+
+func TestDaily(t *testing.T) {
+	day := time.Date(2023, 5, 7, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+
+	obs := []Observation{
+		{Time: hour(6), Temperature: 8, Hour: weather.Hour{Time: hour(6), Precipitation: 0.5}},
+		// Same hour again, its precipitation is only counted once
+		{Time: hour(6).Add(30 * time.Minute), Temperature: 9, Hour: weather.Hour{Time: hour(6), Precipitation: 0.7}},
+		{Time: hour(14), Temperature: 19, Hour: weather.Hour{Time: hour(14), Precipitation: 1.1}},
+		{Time: hour(30), Temperature: 11, Hour: weather.Hour{Time: hour(30)}},
+	}
+
+	days := Daily(obs, time.UTC)
+
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, but got %d", len(days))
+	}
+
+	d := days[0]
+	if d.Date != "2023-05-07" || d.TemperatureMin != 8 || d.TemperatureMax != 19 || d.TemperatureMean != 12 ||
+		d.Precipitation != 1.8 || d.Observations != 3 {
+		t.Errorf("Unexpected first day %+v", d)
+	}
+
+	if days[1].Date != "2023-05-08" || days[1].Observations != 1 {
+		t.Errorf("Unexpected second day %+v", days[1])
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2023, 5, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"7d":         time.Date(2023, 4, 30, 12, 0, 0, 0, time.UTC),
+		"12h":        time.Date(2023, 5, 7, 0, 0, 0, 0, time.UTC),
+		"2023-05-01": time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for s, expected := range tests {
+		got, err := ParseSince(s, now)
+		if err != nil || !got.Equal(expected) {
+			t.Errorf("%s: expected %s, but got %s, %v", s, expected, got, err)
+		}
+	}
+
+	if _, err := ParseSince("last week", now); err == nil {
+		t.Errorf("Expected an error for an invalid time")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	b := &bytes.Buffer{}
+	o := Observation{
+		Zip:         "3006",
+		Location:    "Bern",
+		Time:        time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC),
+		Temperature: 8.5,
+		Icon:        2,
+		Hour:        weather.Hour{Precipitation: 0.4, WindSpeed: 12, WindDirection: 270},
+	}
+
+	err := WriteCSV(b, []Observation{o})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := "zip,location,time,temperature,icon,precipitation,wind_speed,wind_direction\n" +
+		"3006,Bern,2023-05-07T06:00:00Z,8.5,2,0.4,12,270\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+
+	err = WriteDailyCSV(b, []Day{{Date: "2023-05-07", TemperatureMin: 8, TemperatureMax: 19, TemperatureMean: 12, Precipitation: 1.8, Observations: 3}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected = "date,temperature_min,temperature_max,temperature_mean,precipitation,observations\n2023-05-07,8,19,12,1.8,3\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, b.String())
+	}
+}
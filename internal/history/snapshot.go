@@ -0,0 +1,112 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Minimum time between two snapshots of the forecast of a zip code, the
+// forecast doesn't change much more often and the snapshots are large.
+const SnapshotInterval = time.Hour
+
+// Snapshot is the forecast of a location as it was issued, to be compared
+// against the observations later on.
+type Snapshot struct {
+	Zip      string         `json:"zip"`
+	IssuedAt time.Time      `json:"issuedAt"`
+	Days     []weather.Day  `json:"days"`
+	Hours    []ForecastHour `json:"hours"`
+}
+
+// ForecastHour is the forecast temperature of an hour.
+type ForecastHour struct {
+	Time        time.Time `json:"time"`
+	Temperature float64   `json:"temperature"`
+}
+
+// Creates the snapshot of the forecast. The current weather's update time is
+// used as the issue time, and only the hours after it are kept.
+func NewSnapshot(zip string, w *weather.Weather) Snapshot {
+	s := Snapshot{
+		Zip:      zip,
+		IssuedAt: w.UpdatedAt,
+		Days:     w.Days,
+		Hours:    []ForecastHour{},
+	}
+
+	for _, h := range w.Hours {
+		if h.Time.After(s.IssuedAt) {
+			s.Hours = append(s.Hours, ForecastHour{Time: h.Time, Temperature: h.TemperatureMean})
+		}
+	}
+
+	return s
+}
+
+// Appends the snapshot to the forecasts of its zip code. It returns false
+// without writing anything if the last snapshot is less than SnapshotInterval
+// older.
+func (s *Store) AppendSnapshot(snap Snapshot) (bool, error) {
+	path, err := s.path(snap.Zip, ".forecasts.jsonl")
+	if err != nil {
+		return false, err
+	}
+
+	// Only the issue time of the last snapshot is needed
+	last, ok, err := lastLine[struct {
+		IssuedAt time.Time `json:"issuedAt"`
+	}](path, s.logger())
+	if err != nil {
+		return false, err
+	}
+
+	if ok && snap.IssuedAt.Sub(last.IssuedAt) < SnapshotInterval {
+		return false, nil
+	}
+
+	return true, s.appendLine(path, snap)
+}
+
+// Returns the snapshots of the zip code issued between since and until,
+// ordered by issue time.
+func (s *Store) Snapshots(zip string, since time.Time, until time.Time) ([]Snapshot, error) {
+	path, err := s.path(zip, ".forecasts.jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := []Snapshot{}
+
+	err = readLines(path, s.logger(), func(snap Snapshot) {
+		if inRange(snap.IssuedAt, since, until) {
+			snaps = append(snaps, snap)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].IssuedAt.Before(snaps[j].IssuedAt) })
+
+	return snaps, nil
+}
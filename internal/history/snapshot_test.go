@@ -0,0 +1,74 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	start := time.UnixMilli(1683410400000)
+
+	w := &weather.Weather{
+		UpdatedAt: start.Add(30 * time.Minute),
+		Days:      []weather.Day{{DayDate: "2023-05-07", TemperatureMax: 18}},
+		Hours: []weather.Hour{
+			{Time: start, TemperatureMean: 14.1},
+			{Time: start.Add(time.Hour), TemperatureMean: 13.7},
+			{Time: start.Add(2 * time.Hour), TemperatureMean: 13.3},
+		},
+	}
+
+	s := NewSnapshot("3006", w)
+
+	if len(s.Hours) != 2 || s.Hours[0].Temperature != 13.7 {
+		t.Errorf("Expected the hours after the issue time, but got %+v", s.Hours)
+	}
+
+	if len(s.Days) != 1 || s.Days[0].TemperatureMax != 18 {
+		t.Errorf("Expected the daily forecast, but got %+v", s.Days)
+	}
+}
+
+func TestAppendSnapshot(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	issued := time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		issued   time.Time
+		expected bool
+	}{
+		{issued, true},
+		{issued.Add(20 * time.Minute), false},
+		{issued.Add(time.Hour), true},
+	} {
+		added, err := s.AppendSnapshot(Snapshot{Zip: "3006", IssuedAt: tt.issued})
+		if err != nil || added != tt.expected {
+			t.Errorf("%s: expected added to be %v, but got %v, %v", tt.issued, tt.expected, added, err)
+		}
+	}
+
+	snaps, err := s.Snapshots("3006", issued.Add(time.Minute), time.Time{})
+	if err != nil || len(snaps) != 1 || !snaps[0].IssuedAt.Equal(issued.Add(time.Hour)) {
+		t.Errorf("Expected the second snapshot, but got %+v, %v", snaps, err)
+	}
+}
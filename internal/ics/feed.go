@@ -0,0 +1,184 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darox/sunly/internal/server"
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Feed serves the calendars of the configured zip codes on /<zip>.ics, so
+// that calendar apps can subscribe to them.
+type Feed struct {
+	source server.Source
+	zips   []string
+	ttl    time.Duration
+	now    func() time.Time
+	// Locks of the zip codes, so that a calendar is only fetched by one
+	// request at a time and the others get its result.
+	fetching map[string]*sync.Mutex
+
+	mu        sync.Mutex
+	calendars map[string]feedEntry
+	locations map[string]weather.Location
+}
+
+// feedEntry is the last rendered calendar of a zip code.
+type feedEntry struct {
+	calendar  string
+	fetchedAt time.Time
+}
+
+// Creates a feed for the zip codes that refetches the data from the source
+// once it is older than ttl.
+func NewFeed(source server.Source, zips []string, ttl time.Duration) *Feed {
+	f := &Feed{
+		source:    source,
+		zips:      zips,
+		ttl:       ttl,
+		now:       time.Now,
+		fetching:  map[string]*sync.Mutex{},
+		calendars: map[string]feedEntry{},
+		locations: map[string]weather.Location{},
+	}
+
+	for _, zip := range zips {
+		f.fetching[zip] = &sync.Mutex{}
+	}
+
+	return f
+}
+
+// Returns the paths of the calendars.
+func (f *Feed) Paths() []string {
+	paths := make([]string, 0, len(f.zips))
+
+	for _, zip := range f.zips {
+		paths = append(paths, "/"+zip+".ics")
+	}
+
+	return paths
+}
+
+// Serves the calendar of the zip code in the path.
+func (f *Feed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	zip, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".ics")
+	if !ok || !f.serves(zip) {
+		http.NotFound(w, r)
+		return
+	}
+
+	calendar, err := f.calendar(r.Context(), zip)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", zip+".ics"))
+	_, _ = w.Write([]byte(calendar))
+}
+
+func (f *Feed) serves(zip string) bool {
+	for _, z := range f.zips {
+		if z == zip {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the cached calendar of the zip code or renders it again. Only the
+// maps are locked while fetching, so zip codes don't wait for each other.
+func (f *Feed) calendar(ctx context.Context, zip string) (string, error) {
+	lock := f.fetching[zip]
+	lock.Lock()
+	defer lock.Unlock()
+
+	f.mu.Lock()
+	e, cached := f.calendars[zip]
+	f.mu.Unlock()
+
+	if cached && f.now().Sub(e.fetchedAt) < f.ttl {
+		return e.calendar, nil
+	}
+
+	l, err := f.location(ctx, zip)
+	if err != nil {
+		return "", err
+	}
+
+	w, err := f.source.Weather(ctx, l)
+	if err != nil {
+		// Keep serving the last known calendar
+		if cached {
+			return e.calendar, nil
+		}
+
+		return "", fmt.Errorf("error getting the weather: %w", err)
+	}
+
+	e = feedEntry{calendar: Render(l, w, f.now()), fetchedAt: f.now()}
+
+	f.mu.Lock()
+	f.calendars[zip] = e
+	f.mu.Unlock()
+
+	return e.calendar, nil
+}
+
+// Returns the location of the zip code, which is only looked up once.
+func (f *Feed) location(ctx context.Context, zip string) (weather.Location, error) {
+	f.mu.Lock()
+	l, ok := f.locations[zip]
+	f.mu.Unlock()
+
+	if ok {
+		return l, nil
+	}
+
+	ld, err := f.source.Locations(ctx, zip)
+	if err != nil {
+		return weather.Location{}, fmt.Errorf("error getting the location: %w", err)
+	}
+
+	l, ok = weather.LocationOf(ld, zip)
+	if !ok {
+		return weather.Location{}, fmt.Errorf("unknown zip code %s", zip)
+	}
+
+	f.mu.Lock()
+	f.locations[zip] = l
+	f.mu.Unlock()
+
+	return l, nil
+}
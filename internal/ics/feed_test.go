@@ -0,0 +1,189 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+type fakeSource struct {
+	mu            sync.Mutex
+	calls         int
+	locationCalls int
+	err           error
+	// Fetches of these zip codes tell that they started and wait until their
+	// channel is closed.
+	block   map[string]chan struct{}
+	started chan string
+}
+
+func (f *fakeSource) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	f.mu.Lock()
+	f.calls++
+	err := f.err
+	block := f.block[l.Zip]
+	f.mu.Unlock()
+
+	if block != nil {
+		f.started <- l.Zip
+		<-block
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return testWeather(), nil
+}
+
+func (f *fakeSource) Locations(ctx context.Context, query string) (*swisspost.LocationData, error) {
+	f.mu.Lock()
+	f.locationCalls++
+	f.mu.Unlock()
+
+	ld := &swisspost.LocationData{}
+
+	err := json.Unmarshal([]byte(`{
+		"nhits": 1,
+		"records": [
+			{"fields": {"postleitzahl": "3006", "ortbez18": "Bern", "kanton": "BE", "geo_point_2d": [46.94, 7.47]}}
+		]
+	}`), ld)
+
+	return ld, err
+}
+
+func get(t *testing.T, f *Feed, path string) (int, string, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+	b, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return rec.Code, rec.Header().Get("Content-Type"), string(b)
+}
+
+func TestFeed(t *testing.T) {
+	source := &fakeSource{}
+	f := NewFeed(source, []string{"3006"}, time.Minute)
+
+	now := time.Date(2023, 5, 7, 10, 5, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	code, contentType, body := get(t, f, "/3006.ics")
+	if code != 200 || contentType != "text/calendar; charset=utf-8" {
+		t.Errorf("Expected a calendar, but got %d %s", code, contentType)
+	}
+
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.Contains(body, "LOCATION:Bern\r\n") {
+		t.Errorf("Unexpected calendar %s", body)
+	}
+
+	// Cached until the ttl has passed
+	get(t, f, "/3006.ics")
+
+	if source.calls != 1 {
+		t.Errorf("Expected the weather to be fetched once, but got %d calls", source.calls)
+	}
+
+	// The last calendar is served if the upstream fails
+	now = now.Add(2 * time.Minute)
+	source.err = errors.New("upstream down")
+
+	if code, _, cached := get(t, f, "/3006.ics"); code != 200 || cached != body {
+		t.Errorf("Expected the last calendar, but got %d %s", code, cached)
+	}
+
+	if source.calls != 2 {
+		t.Errorf("Expected the weather to be fetched again, but got %d calls", source.calls)
+	}
+
+	if source.locationCalls != 1 {
+		t.Errorf("Expected the location to be looked up once, but got %d calls", source.locationCalls)
+	}
+
+	for _, path := range []string{"/8001.ics", "/3006", "/"} {
+		if code, _, _ := get(t, f, path); code != 404 {
+			t.Errorf("Expected 404 for %s, but got %d", path, code)
+		}
+	}
+
+	if p := f.Paths(); len(p) != 1 || p[0] != "/3006.ics" {
+		t.Errorf("Unexpected paths %v", p)
+	}
+}
+
+func TestFeedConcurrentRequests(t *testing.T) {
+	release := make(chan struct{})
+	source := &fakeSource{block: map[string]chan struct{}{"3006": release}, started: make(chan string)}
+	f := NewFeed(source, []string{"3006", "8001"}, time.Minute)
+
+	done := make(chan int)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			rec := httptest.NewRecorder()
+			f.ServeHTTP(rec, httptest.NewRequest("GET", "/3006.ics", nil))
+			done <- rec.Code
+		}()
+	}
+
+	<-source.started
+
+	// Other zip codes don't wait for the one that is fetched
+	if code, _, _ := get(t, f, "/8001.ics"); code != 200 {
+		t.Errorf("Expected the calendar of 8001 while 3006 is fetched, but got %d", code)
+	}
+
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if code := <-done; code != 200 {
+			t.Errorf("Expected the calendar of 3006, but got %d", code)
+		}
+	}
+
+	// The second request of 3006 got the calendar the first one fetched
+	if source.calls != 2 {
+		t.Errorf("Expected the weather of each zip code to be fetched once, but got %d calls", source.calls)
+	}
+}
+
+func TestFeedError(t *testing.T) {
+	f := NewFeed(&fakeSource{err: errors.New("upstream down")}, []string{"3006"}, time.Minute)
+
+	if code, _, _ := get(t, f, "/3006.ics"); code != 502 {
+		t.Errorf("Expected 502, but got %d", code)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package ics exports the forecast, the sunrise and sunset and the weather
+// warnings of a location as iCalendar (RFC 5545).
+package ics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+const (
+	// Longest line in octets, longer lines are folded.
+	maxLineLength = 75
+	// How often subscribed calendars should refresh.
+	refreshInterval = "PT1H"
+	// How long before a warning its alarm goes off.
+	alarmBefore = "-PT30M"
+	// Format of times in UTC.
+	utcFormat = "20060102T150405Z"
+	// How long after now a warning without an end is shown.
+	openWarningLength = 24 * time.Hour
+)
+
+// Renders the calendar of the location at the given time.
+func Render(l weather.Location, w *weather.Weather, now time.Time) string {
+	c := &calendar{location: l, stamp: now.UTC().Format(utcFormat)}
+
+	c.line("BEGIN:VCALENDAR")
+	c.line("VERSION:2.0")
+	c.line("PRODID:-//darox//sunly//EN")
+	c.line("CALSCALE:GREGORIAN")
+	c.line("METHOD:PUBLISH")
+	c.line("X-WR-CALNAME:" + text("sunly "+l.Name))
+	c.line("REFRESH-INTERVAL;VALUE=DURATION:" + refreshInterval)
+	c.line("X-PUBLISHED-TTL:" + refreshInterval)
+
+	for _, d := range w.Days {
+		date, err := time.Parse("2006-01-02", d.DayDate)
+		if err != nil {
+			continue
+		}
+
+		c.begin("forecast-" + date.Format("20060102"))
+		c.line("DTSTART;VALUE=DATE:" + date.Format("20060102"))
+		c.line("DTEND;VALUE=DATE:" + date.AddDate(0, 0, 1).Format("20060102"))
+		c.line("SUMMARY:" + text(fmt.Sprintf("%s %.0f/%.0f °C %.1f mm", swissmeteo.IconSymbol(d.IconDay),
+			d.TemperatureMin, d.TemperatureMax, d.Precipitation)))
+		c.line("DESCRIPTION:" + text(fmt.Sprintf("%s\nMin %.1f °C, max %.1f °C\nPrecipitation %.1f mm",
+			swissmeteo.IconDescription(d.IconDay), d.TemperatureMin, d.TemperatureMax, d.Precipitation)))
+		c.line("TRANSP:TRANSPARENT")
+		c.end()
+	}
+
+	c.sun("sunrise", "Sunrise", w.Sunrise)
+	c.sun("sunset", "Sunset", w.Sunset)
+
+	for _, wa := range w.Warnings {
+		c.begin(fmt.Sprintf("warning-%s-%d", strings.ReplaceAll(strings.ToLower(wa.Type), " ", "-"),
+			wa.ValidFrom.Unix()))
+		c.line("DTSTART:" + wa.ValidFrom.UTC().Format(utcFormat))
+
+		// An event without an end is over at its start (RFC 5545), so warnings
+		// that last until they are lifted are shown until a day from now,
+		// which moves on with every refresh
+		end := wa.ValidTo
+		description := wa.Text
+
+		if end.IsZero() {
+			end = wa.ValidFrom
+			if now.After(end) {
+				end = now
+			}
+
+			end = end.Add(openWarningLength)
+			description = strings.TrimPrefix(description+"\nThe end is not known yet.", "\n")
+		}
+
+		c.line("DTEND:" + end.UTC().Format(utcFormat))
+
+		summary := fmt.Sprintf("⚠ Level %d %s warning", wa.Level, wa.Type)
+
+		c.line("SUMMARY:" + text(summary))
+
+		if description != "" {
+			c.line("DESCRIPTION:" + text(description))
+		}
+
+		c.line("CATEGORIES:WARNING")
+		c.line(fmt.Sprintf("PRIORITY:%d", priority(wa.Level)))
+		c.line("BEGIN:VALARM")
+		c.line("ACTION:DISPLAY")
+		c.line("TRIGGER:" + alarmBefore)
+		c.line("DESCRIPTION:" + text(summary))
+		c.line("END:VALARM")
+		c.end()
+	}
+
+	c.line("END:VCALENDAR")
+
+	return c.b.String()
+}
+
+// calendar builds the lines of a calendar.
+type calendar struct {
+	b        strings.Builder
+	location weather.Location
+	stamp    string
+}
+
+// Adds the events of the sunrise or sunset times.
+func (c *calendar) sun(uid string, summary string, times []time.Time) {
+	for _, t := range times {
+		c.begin(uid + "-" + t.UTC().Format("20060102"))
+		c.line("DTSTART:" + t.UTC().Format(utcFormat))
+		c.line("SUMMARY:" + text(summary))
+		c.line("TRANSP:TRANSPARENT")
+		c.end()
+	}
+}
+
+// Begins an event with the uid, which is unique for the location.
+func (c *calendar) begin(uid string) {
+	l := c.location
+
+	key := l.Zip
+	if key == "" {
+		key = fmt.Sprintf("%.4f_%.4f", l.Latitude, l.Longitude)
+	}
+
+	c.line("BEGIN:VEVENT")
+	c.line(fmt.Sprintf("UID:%s-%s@sunly", uid, key))
+	c.line("DTSTAMP:" + c.stamp)
+	c.line("LOCATION:" + text(l.Name))
+
+	if l.Latitude != 0 || l.Longitude != 0 {
+		c.line(fmt.Sprintf("GEO:%.6f;%.6f", l.Latitude, l.Longitude))
+	}
+}
+
+func (c *calendar) end() {
+	c.line("END:VEVENT")
+}
+
+// Adds a content line, folded after 75 octets and ended by CRLF.
+func (c *calendar) line(s string) {
+	n := maxLineLength
+
+	for len(s) > n {
+		// Don't split UTF-8 sequences
+		i := n
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+
+		c.b.WriteString(s[:i])
+		c.b.WriteString("\r\n ")
+		s = s[i:]
+
+		// The space of the continuation lines counts too
+		n = maxLineLength - 1
+	}
+
+	c.b.WriteString(s)
+	c.b.WriteString("\r\n")
+}
+
+// Escapes the value of a text property.
+func text(s string) string {
+	return strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n").Replace(s)
+}
+
+// Returns the priority of the warning level, 1 being the highest.
+func priority(level int) int {
+	if level >= 4 {
+		return 1
+	}
+
+	if level >= 3 {
+		return 5
+	}
+
+	return 9
+}
@@ -0,0 +1,134 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ics
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+var update = flag.Bool("update", false, "Update the golden files")
+
+func testWeather() *weather.Weather {
+	return &weather.Weather{
+		UpdatedAt: time.Date(2023, 5, 7, 10, 0, 0, 0, time.UTC),
+		Days: []weather.Day{
+			{DayDate: "2023-05-07", IconDay: 1, TemperatureMin: 11, TemperatureMax: 18.4, Precipitation: 0},
+			{DayDate: "2023-05-08", IconDay: 14, TemperatureMin: 9.6, TemperatureMax: 15, Precipitation: 12.7},
+		},
+		Sunrise: []time.Time{time.Date(2023, 5, 7, 4, 6, 7, 0, time.UTC), time.Date(2023, 5, 8, 4, 4, 40, 0, time.UTC)},
+		Sunset:  []time.Time{time.Date(2023, 5, 7, 18, 49, 2, 0, time.UTC)},
+		Warnings: []weather.Warning{
+			{
+				Type:      "Thunderstorm",
+				Level:     3,
+				Text:      "Severe thunderstorms with hail; gusts of 90 km/h, heavy rain. Stay away from trees and keep an eye on the forecast.",
+				ValidFrom: time.Date(2023, 5, 8, 12, 0, 0, 0, time.UTC),
+				ValidTo:   time.Date(2023, 5, 8, 20, 0, 0, 0, time.UTC),
+			},
+			{Type: "Frost", Level: 2, ValidFrom: time.Date(2023, 5, 7, 22, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	l := weather.Location{Zip: "3006", Name: "Bern", Latitude: 46.94, Longitude: 7.47}
+	got := Render(l, testWeather(), time.Date(2023, 5, 7, 10, 5, 0, 0, time.UTC))
+
+	path := filepath.Join("testdata", "calendar.ics")
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if got != string(expected) {
+		t.Errorf("Expected the calendar to match %s, run the tests with -update after checking it, but got\n%s",
+			path, got)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("Expected lines of at most %d octets, but got %q", maxLineLength, line)
+		}
+
+		if !strings.HasSuffix(got, "\r\n") || strings.Contains(line, "\n") {
+			t.Errorf("Expected lines ended by CRLF, but got %q", line)
+		}
+	}
+}
+
+func TestRenderCoordinates(t *testing.T) {
+	l := weather.Location{Name: "46.9400, 7.4700", Latitude: 46.94, Longitude: 7.47}
+	got := Render(l, testWeather(), time.Date(2023, 5, 7, 10, 5, 0, 0, time.UTC))
+
+	if !strings.Contains(got, "UID:forecast-20230507-46.9400_7.4700@sunly\r\n") {
+		t.Errorf("Expected the coordinates in the uids, but got\n%s", got)
+	}
+
+	if !strings.Contains(got, "LOCATION:46.9400\\, 7.4700\r\n") {
+		t.Errorf("Expected the location to be escaped, but got\n%s", got)
+	}
+}
+
+func TestText(t *testing.T) {
+	if s := text("a;b,c\\d\ne"); s != `a\;b\,c\\d\ne` {
+		t.Errorf("Expected the text to be escaped, but got %q", s)
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	c := &calendar{}
+	c.line("SUMMARY:" + strings.Repeat("ü", 100))
+
+	lines := strings.Split(strings.TrimSuffix(c.b.String(), "\r\n"), "\r\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, but got %q", lines)
+	}
+
+	unfolded := ""
+
+	for i, l := range lines {
+		if len(l) > maxLineLength {
+			t.Errorf("Expected at most %d octets, but got %d", maxLineLength, len(l))
+		}
+
+		if i > 0 {
+			l = strings.TrimPrefix(l, " ")
+		}
+
+		unfolded += l
+	}
+
+	if unfolded != "SUMMARY:"+strings.Repeat("ü", 100) {
+		t.Errorf("Expected the unfolded line to be unchanged, but got %q", unfolded)
+	}
+}
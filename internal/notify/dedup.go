@@ -0,0 +1,138 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/statefile"
+)
+
+// Sent remembers which rules were delivered to which notifiers, so a rule that
+// keeps triggering on every run is only sent once. A rule is notified again
+// after it stopped triggering or when the repeat interval is over. A failed
+// delivery is only tried again for the notifier that failed.
+type Sent struct {
+	// Time of the last notification by key of the rule and id of the notifier.
+	Keys map[string]map[string]time.Time `json:"sent"`
+}
+
+// Delivery is a triggered rule and the notifiers that still have to get it.
+type Delivery struct {
+	Trigger   rules.Trigger
+	Notifiers []string
+}
+
+// Returns the key of a rule at a location.
+func Key(name string, zip string) string {
+	return name + "|" + zip
+}
+
+// Loads the sent notifications, a missing file results in an empty state.
+func LoadSent(path string) (*Sent, error) {
+	s := &Sent{Keys: map[string]map[string]time.Time{}}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading sent notifications: %w", err)
+	}
+
+	err = json.Unmarshal(b, s)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sent notifications %s: %w", path, err)
+	}
+
+	if s.Keys == nil {
+		s.Keys = map[string]map[string]time.Time{}
+	}
+
+	return s, nil
+}
+
+// Saves the sent notifications by writing a temporary file and renaming it.
+// Processes that load, change and save them at the same time need to hold
+// statefile.Lock of the path.
+func (s *Sent) Save(path string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return statefile.Write(path, b)
+}
+
+// Returns the triggers with the notifiers that didn't get them yet. The keys of
+// the checked rules that didn't trigger are forgotten, so they are notified
+// again the next time they trigger. A repeatAfter of zero never repeats a
+// notification while the rule keeps triggering. Successful deliveries must be
+// recorded with Record.
+func (s *Sent) Filter(checked []string, notifiers []string, triggers []rules.Trigger, now time.Time,
+	repeatAfter time.Duration) []Delivery {
+	triggered := map[string]bool{}
+	result := []Delivery{}
+
+	for _, t := range triggers {
+		k := Key(t.Name, t.Zip)
+		triggered[k] = true
+
+		d := Delivery{Trigger: t}
+
+		for _, n := range notifiers {
+			sent, ok := s.Keys[k][n]
+			if ok && (repeatAfter <= 0 || now.Sub(sent) < repeatAfter) {
+				continue
+			}
+
+			d.Notifiers = append(d.Notifiers, n)
+		}
+
+		if len(d.Notifiers) > 0 {
+			result = append(result, d)
+		}
+	}
+
+	for _, k := range checked {
+		if !triggered[k] {
+			delete(s.Keys, k)
+		}
+	}
+
+	return result
+}
+
+// Records that the trigger was delivered to the notifier.
+func (s *Sent) Record(t rules.Trigger, notifier string, now time.Time) {
+	k := Key(t.Name, t.Zip)
+
+	if s.Keys[k] == nil {
+		s.Keys[k] = map[string]time.Time{}
+	}
+
+	s.Keys[k][notifier] = now
+}
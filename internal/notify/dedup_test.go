@@ -0,0 +1,146 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/rules"
+)
+
+// Returns the names of the triggers and their notifiers.
+func deliveries(ds []Delivery) []string {
+	result := []string{}
+
+	for _, d := range ds {
+		result = append(result, d.Trigger.Name+":"+strings.Join(d.Notifiers, ","))
+	}
+
+	return result
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC)
+	frost := rules.Trigger{Name: "frost", Zip: "3006"}
+	wind := rules.Trigger{Name: "wind", Zip: "3006"}
+	checked := []string{Key("frost", "3006"), Key("wind", "3006")}
+	notifiers := []string{"ntfy"}
+
+	s := &Sent{Keys: map[string]map[string]time.Time{}}
+
+	// Sends and records the deliveries like notify does
+	run := func(triggers []rules.Trigger, now time.Time, repeatAfter time.Duration) []string {
+		ds := s.Filter(checked, notifiers, triggers, now, repeatAfter)
+
+		for _, d := range ds {
+			for _, n := range d.Notifiers {
+				s.Record(d.Trigger, n, now)
+			}
+		}
+
+		return deliveries(ds)
+	}
+
+	if got := run([]rules.Trigger{frost}, now, 0); len(got) != 1 {
+		t.Errorf("Expected the first trigger to be sent, but got %v", got)
+	}
+
+	if got := run([]rules.Trigger{frost, wind}, now.Add(time.Hour), 0); len(got) != 1 || got[0] != "wind:ntfy" {
+		t.Errorf("Expected only the new trigger to be sent, but got %v", got)
+	}
+
+	// Frost stopped triggering, so it is sent again when it comes back
+	run([]rules.Trigger{wind}, now.Add(2*time.Hour), 0)
+
+	if got := run([]rules.Trigger{frost, wind}, now.Add(3*time.Hour), 0); len(got) != 1 || got[0] != "frost:ntfy" {
+		t.Errorf("Expected frost to be sent again, but got %v", got)
+	}
+
+	if got := run([]rules.Trigger{frost, wind}, now.Add(4*time.Hour), 2*time.Hour); len(got) != 1 || got[0] != "wind:ntfy" {
+		t.Errorf("Expected wind to be repeated after 2h, but got %v", got)
+	}
+}
+
+func TestFilterRetriesFailedNotifiers(t *testing.T) {
+	now := time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC)
+	frost := rules.Trigger{Name: "frost", Zip: "3006"}
+	checked := []string{Key("frost", "3006")}
+	notifiers := []string{"ntfy", "smtp"}
+
+	s := &Sent{Keys: map[string]map[string]time.Time{}}
+
+	got := deliveries(s.Filter(checked, notifiers, []rules.Trigger{frost}, now, 0))
+	if len(got) != 1 || got[0] != "frost:ntfy,smtp" {
+		t.Fatalf("Expected frost to be sent to both notifiers, but got %v", got)
+	}
+
+	// Only ntfy succeeded
+	s.Record(frost, "ntfy", now)
+
+	got = deliveries(s.Filter(checked, notifiers, []rules.Trigger{frost}, now.Add(time.Minute), 0))
+	if len(got) != 1 || got[0] != "frost:smtp" {
+		t.Errorf("Expected only the failed notifier to be retried, but got %v", got)
+	}
+
+	s.Record(frost, "smtp", now.Add(time.Minute))
+
+	if got = deliveries(s.Filter(checked, notifiers, []rules.Trigger{frost}, now.Add(2*time.Minute), 0)); len(got) != 0 {
+		t.Errorf("Expected nothing to be sent, but got %v", got)
+	}
+}
+
+func TestFilterKeepsUncheckedKeys(t *testing.T) {
+	now := time.Now()
+	s := &Sent{Keys: map[string]map[string]time.Time{Key("frost", "8001"): {"ntfy": now}}}
+
+	s.Filter([]string{Key("wind", "3006")}, []string{"ntfy"}, nil, now, 0)
+
+	if _, ok := s.Keys[Key("frost", "8001")]; !ok {
+		t.Errorf("Expected the key of a rule that wasn't checked to be kept")
+	}
+}
+
+func TestSentSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sunly", "notify-sent.json")
+
+	s, err := LoadSent(path)
+	if err != nil || len(s.Keys) != 0 {
+		t.Fatalf("Expected an empty state, but got %+v, %v", s, err)
+	}
+
+	now := time.Date(2023, 5, 7, 6, 0, 0, 0, time.UTC)
+	s.Record(rules.Trigger{Name: "frost", Zip: "3006"}, "ntfy", now)
+
+	err = s.Save(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	s, err = LoadSent(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if got := s.Keys[Key("frost", "3006")]["ntfy"]; !got.Equal(now) {
+		t.Errorf("Expected the sent time to be %s, but got %s", now, got)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// Desktop shows the notification on the desktop with notify-send, which
+// passes it on to the notification daemon over D-Bus.
+type Desktop struct {
+	Command string
+}
+
+func (d *Desktop) Notify(ctx context.Context, n Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, d.Command, "--app-name=sunly", n.Title, n.Message).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("desktop: error running %s: %w: %s", d.Command, err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDesktop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake notify-send is a shell script")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	command := filepath.Join(dir, "notify-send")
+
+	err := os.WriteFile(command, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+out+"\n"), 0o700)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	d := &Desktop{Command: command}
+
+	err = d.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	args := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(args) != 3 || args[1] != "frost (3006)" || args[2] != testNotification().Message {
+		t.Errorf("Unexpected arguments %q", args)
+	}
+}
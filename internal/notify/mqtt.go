@@ -0,0 +1,171 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Topic used when the config doesn't set one.
+const defaultTopic = "sunly/alerts"
+
+// MQTT publishes the notification as JSON to a topic of an MQTT broker. It
+// speaks just enough MQTT 3.1.1 to publish a single message with QoS 0.
+type MQTT struct {
+	// Host and port of the broker.
+	Addr     string
+	Topic    string
+	Username string
+	Password string
+}
+
+// MQTT control packet types, already shifted into the upper nibble.
+const (
+	mqttConnect    = 0x10
+	mqttConnack    = 0x20
+	mqttPublish    = 0x30
+	mqttDisconnect = 0xe0
+)
+
+func newMQTT(c Config) (*MQTT, error) {
+	if c.Addr == "" {
+		return nil, errors.New("mqtt: addr is required")
+	}
+
+	topic := c.Topic
+	if topic == "" {
+		topic = defaultTopic
+	}
+
+	return &MQTT{Addr: c.Addr, Topic: topic, Username: c.Username, Password: c.Password}, nil
+}
+
+func (m *MQTT) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("mqtt: error encoding notification: %w", err)
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", m.Addr)
+	if err != nil {
+		return fmt.Errorf("mqtt: error connecting to %s: %w", m.Addr, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return fmt.Errorf("mqtt: %w", err)
+	}
+
+	_, err = conn.Write(m.connectPacket())
+	if err != nil {
+		return fmt.Errorf("mqtt: error sending connect: %w", err)
+	}
+
+	// CONNACK: packet type, remaining length 2, flags and return code
+	ack := make([]byte, 4)
+
+	_, err = io.ReadFull(conn, ack)
+	if err != nil {
+		return fmt.Errorf("mqtt: error reading connack: %w", err)
+	}
+
+	if ack[0] != mqttConnack || ack[1] != 2 {
+		return fmt.Errorf("mqtt: unexpected packet 0x%02x instead of connack", ack[0])
+	}
+
+	if ack[3] != 0 {
+		return fmt.Errorf("mqtt: connection refused with code %d", ack[3])
+	}
+
+	body := append(mqttString(m.Topic), payload...)
+
+	_, err = conn.Write(append(mqttHeader(mqttPublish, len(body)), body...))
+	if err != nil {
+		return fmt.Errorf("mqtt: error publishing: %w", err)
+	}
+
+	_, err = conn.Write([]byte{mqttDisconnect, 0})
+	if err != nil {
+		return fmt.Errorf("mqtt: error disconnecting: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the CONNECT packet with a clean session and the credentials.
+func (m *MQTT) connectPacket() []byte {
+	var flags byte = 0x02
+
+	payload := mqttString(fmt.Sprintf("sunly-%d", os.Getpid()))
+
+	if m.Username != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(m.Username)...)
+	}
+
+	if m.Password != "" {
+		flags |= 0x40
+		payload = append(payload, mqttString(m.Password)...)
+	}
+
+	// Protocol name, level 4 (3.1.1), flags and a keep alive of 60 seconds
+	body := append(mqttString("MQTT"), 4, flags, 0, 60)
+	body = append(body, payload...)
+
+	return append(mqttHeader(mqttConnect, len(body)), body...)
+}
+
+// Returns the fixed header with the remaining length in the variable length
+// encoding of MQTT.
+func mqttHeader(packetType byte, length int) []byte {
+	h := []byte{packetType}
+
+	for {
+		b := byte(length % 128)
+		length /= 128
+
+		if length > 0 {
+			b |= 0x80
+		}
+
+		h = append(h, b)
+
+		if length == 0 {
+			return h
+		}
+	}
+}
+
+// Returns the string prefixed with its length.
+func mqttString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
@@ -0,0 +1,172 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// Reads a packet and returns its type and body.
+func readPacket(r io.Reader) (byte, []byte, error) {
+	b := make([]byte, 1)
+
+	_, err := io.ReadFull(r, b)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	packetType := b[0]
+	length, multiplier := 0, 1
+
+	for {
+		_, err = io.ReadFull(r, b)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		length += int(b[0]&0x7f) * multiplier
+		multiplier *= 128
+
+		if b[0]&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+
+	return packetType, body, err
+}
+
+func TestMQTT(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer l.Close()
+
+	type packet struct {
+		packetType byte
+		body       []byte
+	}
+
+	received := make(chan []packet, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		packets := []packet{}
+
+		for {
+			pt, body, err := readPacket(conn)
+			if err != nil {
+				break
+			}
+
+			packets = append(packets, packet{pt, body})
+
+			if pt == mqttConnect {
+				_, _ = conn.Write([]byte{mqttConnack, 2, 0, 0})
+			}
+		}
+
+		received <- packets
+	}()
+
+	m, _ := New(Config{Type: TypeMQTT, Addr: l.Addr().String(), Topic: "greenhouse/alerts", Username: "sunly", Password: "secret"})
+
+	err = m.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var packets []packet
+
+	select {
+	case packets = <-received:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the packets")
+	}
+
+	if len(packets) != 3 || packets[0].packetType != mqttConnect || packets[1].packetType != mqttPublish ||
+		packets[2].packetType != mqttDisconnect {
+		t.Fatalf("Expected connect, publish and disconnect, but got %+v", packets)
+	}
+
+	// Username and password flags are set
+	if packets[0].body[7]&0xc0 != 0xc0 {
+		t.Errorf("Expected the credential flags to be set, but got 0x%02x", packets[0].body[7])
+	}
+
+	publish := packets[1].body
+	topicLength := int(publish[0])<<8 | int(publish[1])
+
+	if topic := string(publish[2 : 2+topicLength]); topic != "greenhouse/alerts" {
+		t.Errorf("Expected topic to be greenhouse/alerts, but got %s", topic)
+	}
+
+	n := Notification{}
+
+	err = json.Unmarshal(publish[2+topicLength:], &n)
+	if err != nil || n.Rule != "frost" {
+		t.Errorf("Unexpected payload %s", publish[2+topicLength:])
+	}
+}
+
+func TestMQTTRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _, _ = readPacket(conn)
+		_, _ = conn.Write([]byte{mqttConnack, 2, 0, 5})
+	}()
+
+	m, _ := New(Config{Type: TypeMQTT, Addr: l.Addr().String()})
+
+	if err := m.Notify(context.Background(), testNotification()); err == nil {
+		t.Errorf("Expected an error when the broker refuses the connection")
+	}
+}
+
+func TestMQTTHeader(t *testing.T) {
+	h := mqttHeader(mqttPublish, 321)
+
+	if len(h) != 3 || h[1] != 0xc1 || h[2] != 0x02 {
+		t.Errorf("Expected the remaining length to take two bytes, but got % x", h)
+	}
+}
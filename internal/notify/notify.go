@@ -0,0 +1,124 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package notify delivers triggered alert rules to webhooks, mail, push
+// services, MQTT brokers and the desktop.
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/rules"
+)
+
+// Types of notifiers that can be configured.
+const (
+	TypeWebhook = "webhook"
+	TypeSMTP    = "smtp"
+	TypeNtfy    = "ntfy"
+	TypeGotify  = "gotify"
+	TypeMQTT    = "mqtt"
+	TypeDesktop = "desktop"
+)
+
+// Timeout of a single delivery.
+const timeout = 5 * time.Second
+
+// Notification is the message sent for a triggered rule.
+type Notification struct {
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Rule      string    `json:"rule"`
+	Zip       string    `json:"zip"`
+	Condition string    `json:"condition"`
+	Time      time.Time `json:"time"`
+	Value     float64   `json:"value"`
+}
+
+// Notifier delivers notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Config configures a notifier. Which fields are used depends on the type.
+type Config struct {
+	Type string `yaml:"type"`
+	// Address of the webhook, the ntfy topic or the Gotify server.
+	URL string `yaml:"url"`
+	// Template of the webhook body, the notification as JSON if empty.
+	Body    string            `yaml:"body"`
+	Headers map[string]string `yaml:"headers"`
+	// Host and port of the SMTP server or the MQTT broker.
+	Addr     string   `yaml:"addr"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	// Access token of ntfy or the application token of Gotify.
+	Token string `yaml:"token"`
+	Topic string `yaml:"topic"`
+}
+
+// Returns an id of the notifier that stays the same as long as it delivers to
+// the same destination. Secrets in the URL are hashed.
+func (c Config) ID() string {
+	h := sha256.Sum256([]byte(strings.Join([]string{c.Type, c.URL, c.Addr, c.Topic, strings.Join(c.To, ",")}, "|")))
+
+	return c.Type + "-" + hex.EncodeToString(h[:6])
+}
+
+// Returns the notification for a triggered rule.
+func FromTrigger(t rules.Trigger) Notification {
+	return Notification{
+		Title:     fmt.Sprintf("%s (%s)", t.Name, t.Zip),
+		Message:   fmt.Sprintf("%s: %g at %s", t.Condition, t.Value, t.Time.Format("15:04 02.01.2006")),
+		Rule:      t.Name,
+		Zip:       t.Zip,
+		Condition: t.Condition,
+		Time:      t.Time,
+		Value:     t.Value,
+	}
+}
+
+// Creates the notifier described by the config.
+func New(c Config) (Notifier, error) {
+	client := &http.Client{Timeout: timeout}
+
+	switch c.Type {
+	case TypeWebhook:
+		return newWebhook(c, client)
+	case TypeSMTP:
+		return newSMTP(c)
+	case TypeNtfy:
+		return newNtfy(c, client)
+	case TypeGotify:
+		return newGotify(c, client)
+	case TypeMQTT:
+		return newMQTT(c)
+	case TypeDesktop:
+		return &Desktop{Command: "notify-send"}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", c.Type)
+	}
+}
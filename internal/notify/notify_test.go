@@ -0,0 +1,99 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/rules"
+)
+
+// Notification used by the tests of all notifiers.
+func testNotification() Notification {
+	return FromTrigger(rules.Trigger{
+		Name:      "frost",
+		Zip:       "3006",
+		Condition: "temperature < 0 within 12h0m0s",
+		Time:      time.Date(2023, 5, 7, 3, 0, 0, 0, time.Local),
+		Value:     -1.6,
+	})
+}
+
+func TestFromTrigger(t *testing.T) {
+	n := testNotification()
+
+	if n.Title != "frost (3006)" {
+		t.Errorf("Expected title to be frost (3006), but got %s", n.Title)
+	}
+
+	expected := "temperature < 0 within 12h0m0s: -1.6 at 03:00 07.05.2023"
+	if n.Message != expected {
+		t.Errorf("Expected message to be %s, but got %s", expected, n.Message)
+	}
+}
+
+func TestNew(t *testing.T) {
+	valid := []Config{
+		{Type: TypeWebhook, URL: "http://localhost/hook", Body: `{"text": {{json .Message}}}`},
+		{Type: TypeSMTP, Addr: "localhost:25", From: "sunly@example.com", To: []string{"crew@example.com"}},
+		{Type: TypeNtfy, URL: "https://ntfy.sh/frost"},
+		{Type: TypeGotify, URL: "http://localhost", Token: "secret"},
+		{Type: TypeMQTT, Addr: "localhost:1883"},
+		{Type: TypeDesktop},
+	}
+
+	for _, c := range valid {
+		if _, err := New(c); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.Type, err)
+		}
+	}
+
+	invalid := []Config{
+		{Type: "pager"},
+		{Type: TypeWebhook},
+		{Type: TypeWebhook, URL: "http://localhost/hook", Body: "{{.Message"},
+		{Type: TypeSMTP, Addr: "localhost:25"},
+		{Type: TypeGotify, URL: "http://localhost"},
+		{Type: TypeMQTT},
+	}
+
+	for _, c := range invalid {
+		if _, err := New(c); err == nil {
+			t.Errorf("%s: expected an error for %+v", c.Type, c)
+		}
+	}
+}
+
+func TestConfigID(t *testing.T) {
+	a := Config{Type: TypeNtfy, URL: "https://ntfy.sh/frost-secret"}
+
+	if a.ID() != (Config{Type: TypeNtfy, URL: "https://ntfy.sh/frost-secret", Token: "changed"}).ID() {
+		t.Errorf("Expected the id to only depend on the destination")
+	}
+
+	if a.ID() == (Config{Type: TypeNtfy, URL: "https://ntfy.sh/wind"}).ID() {
+		t.Errorf("Expected different destinations to have different ids")
+	}
+
+	if !strings.HasPrefix(a.ID(), "ntfy-") || strings.Contains(a.ID(), "secret") {
+		t.Errorf("Expected the type and a hash, but got %q", a.ID())
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Ntfy publishes the notification to an ntfy topic.
+type Ntfy struct {
+	// URL of the topic, e.g. https://ntfy.sh/my-topic.
+	URL   string
+	Token string
+
+	client *http.Client
+}
+
+func newNtfy(c Config, client *http.Client) (*Ntfy, error) {
+	if c.URL == "" {
+		return nil, errors.New("ntfy: url is required")
+	}
+
+	return &Ntfy{URL: c.URL, Token: c.Token, client: client}, nil
+}
+
+func (n *Ntfy) Notify(ctx context.Context, no Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, strings.NewReader(no.Message))
+	if err != nil {
+		return fmt.Errorf("ntfy: error creating request: %w", err)
+	}
+
+	req.Header.Set("Title", no.Title)
+	req.Header.Set("Tags", "sunly")
+
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	return send(n.client, req, "ntfy")
+}
+
+// Gotify sends the notification as a Gotify message.
+type Gotify struct {
+	// URL of the Gotify server.
+	URL string
+	// Token of the application.
+	Token string
+
+	client *http.Client
+}
+
+func newGotify(c Config, client *http.Client) (*Gotify, error) {
+	if c.URL == "" || c.Token == "" {
+		return nil, errors.New("gotify: url and token are required")
+	}
+
+	return &Gotify{URL: c.URL, Token: c.Token, client: client}, nil
+}
+
+func (g *Gotify) Notify(ctx context.Context, n Notification) error {
+	b, err := json.Marshal(map[string]any{
+		"title":    n.Title,
+		"message":  n.Message,
+		"priority": 5,
+	})
+	if err != nil {
+		return fmt.Errorf("gotify: error encoding message: %w", err)
+	}
+
+	u := strings.TrimSuffix(g.URL, "/") + "/message?token=" + url.QueryEscape(g.Token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("gotify: error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return send(g.client, req, "gotify")
+}
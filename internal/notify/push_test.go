@@ -0,0 +1,80 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfy(t *testing.T) {
+	var r *http.Request
+	var body []byte
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r = req
+		body, _ = io.ReadAll(req.Body)
+	}))
+	defer s.Close()
+
+	n, _ := New(Config{Type: TypeNtfy, URL: s.URL + "/frost", Token: "tk_secret"})
+
+	err := n.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if r.URL.Path != "/frost" || r.Header.Get("Title") != "frost (3006)" || r.Header.Get("Authorization") != "Bearer tk_secret" {
+		t.Errorf("Unexpected request %s %v", r.URL.Path, r.Header)
+	}
+
+	if string(body) != testNotification().Message {
+		t.Errorf("Expected the message as body, but got %s", body)
+	}
+}
+
+func TestGotify(t *testing.T) {
+	var r *http.Request
+	message := map[string]any{}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r = req
+		_ = json.NewDecoder(req.Body).Decode(&message)
+	}))
+	defer s.Close()
+
+	g, _ := New(Config{Type: TypeGotify, URL: s.URL + "/", Token: "secret"})
+
+	err := g.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if r.URL.Path != "/message" || r.URL.Query().Get("token") != "secret" {
+		t.Errorf("Unexpected request %s", r.URL)
+	}
+
+	if message["title"] != "frost (3006)" || message["message"] != testNotification().Message {
+		t.Errorf("Unexpected message %v", message)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP sends the notification as mail.
+type SMTP struct {
+	// Host and port of the server.
+	Addr     string
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+func newSMTP(c Config) (*SMTP, error) {
+	if c.Addr == "" || c.From == "" || len(c.To) == 0 {
+		return nil, errors.New("smtp: addr, from and to are required")
+	}
+
+	return &SMTP{Addr: c.Addr, From: c.From, To: c.To, Username: c.Username, Password: c.Password}, nil
+}
+
+func (s *SMTP) Notify(ctx context.Context, n Notification) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("smtp: invalid address %q: %w", s.Addr, err)
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("smtp: error connecting to %s: %w", s.Addr, err)
+	}
+	defer conn.Close()
+
+	// net/smtp doesn't support contexts, so a deadline bounds the whole session
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return fmt.Errorf("smtp: error starting TLS: %w", err)
+		}
+	}
+
+	if s.Username != "" {
+		err = c.Auth(smtp.PlainAuth("", s.Username, s.Password, host))
+		if err != nil {
+			return fmt.Errorf("smtp: error authenticating: %w", err)
+		}
+	}
+
+	err = c.Mail(s.From)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	for _, to := range s.To {
+		err = c.Rcpt(to)
+		if err != nil {
+			return fmt.Errorf("smtp: error adding recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	_, err = w.Write([]byte(s.message(n, time.Now())))
+	if err != nil {
+		return fmt.Errorf("smtp: error writing message: %w", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("smtp: error sending message: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// Returns the mail with its headers.
+func (s *SMTP) message(n Notification, now time.Time) string {
+	headers := []string{
+		"From: " + s.From,
+		"To: " + strings.Join(s.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", n.Title),
+		"Date: " + now.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+
+	return strings.Join(headers, "\r\n") + "\r\n\r\n" + n.Message + "\r\n"
+}
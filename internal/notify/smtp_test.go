@@ -0,0 +1,131 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Runs a minimal SMTP server that accepts one mail and returns the commands
+// and the data it received.
+func fakeSMTPServer(t *testing.T) (string, <-chan []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	received := make(chan []string, 1)
+
+	go func() {
+		defer l.Close()
+
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		lines := []string{}
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+
+		data := false
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+
+			switch {
+			case data && line == ".":
+				data = false
+				reply("250 OK")
+			case data:
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case line == "DATA":
+				data = true
+				reply("354 Go ahead")
+			case line == "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+
+		received <- lines
+	}()
+
+	return l.Addr().String(), received
+}
+
+func TestSMTP(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+
+	s, err := New(Config{Type: TypeSMTP, Addr: addr, From: "sunly@example.com", To: []string{"crew@example.com"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	err = s.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	select {
+	case lines := <-received:
+		mail := strings.Join(lines, "\n")
+
+		for _, expected := range []string{
+			"MAIL FROM:<sunly@example.com>",
+			"RCPT TO:<crew@example.com>",
+			"Subject: frost (3006)",
+			testNotification().Message,
+		} {
+			if !strings.Contains(mail, expected) {
+				t.Errorf("Expected the session to contain %q, but got:\n%s", expected, mail)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the mail")
+	}
+}
+
+func TestSMTPMessageEncodesSubject(t *testing.T) {
+	s := &SMTP{From: "sunly@example.com", To: []string{"a@example.com", "b@example.com"}}
+
+	m := s.message(Notification{Title: "Frost in Zürich", Message: "-2 °C"}, time.Now())
+
+	if !strings.Contains(m, "Subject: =?utf-8?q?Frost_in_Z=C3=BCrich?=") || !strings.Contains(m, "To: a@example.com, b@example.com") {
+		t.Errorf("Unexpected message:\n%s", m)
+	}
+}
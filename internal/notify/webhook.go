@@ -0,0 +1,113 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// Webhook posts the notification to a URL, either as JSON or with a body
+// rendered from a template.
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	// Renders the body from the notification, nil sends it as JSON.
+	Body *template.Template
+
+	client *http.Client
+}
+
+// Functions available in body templates. json quotes a value, so it can be
+// embedded in a JSON body safely.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func newWebhook(c Config, client *http.Client) (*Webhook, error) {
+	if c.URL == "" {
+		return nil, errors.New("webhook: url is required")
+	}
+
+	w := &Webhook{URL: c.URL, Headers: c.Headers, client: client}
+
+	if c.Body != "" {
+		t, err := template.New("body").Funcs(templateFuncs).Parse(c.Body)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: error parsing body template: %w", err)
+		}
+
+		w.Body = t
+	}
+
+	return w, nil
+}
+
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	body := &bytes.Buffer{}
+
+	if w.Body != nil {
+		err := w.Body.Execute(body, n)
+		if err != nil {
+			return fmt.Errorf("webhook: error rendering body: %w", err)
+		}
+	} else {
+		err := json.NewEncoder(body).Encode(n)
+		if err != nil {
+			return fmt.Errorf("webhook: error encoding notification: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, body)
+	if err != nil {
+		return fmt.Errorf("webhook: error creating request: %w", err)
+	}
+
+	// Headers of the config can override the content type of templated bodies
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	return send(w.client, req, "webhook")
+}
+
+// Sends the request and checks the status code of the response.
+func send(client *http.Client, req *http.Request, name string) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: error sending notification: %w", name, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status code %d", name, res.StatusCode)
+	}
+
+	return nil
+}
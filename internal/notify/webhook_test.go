@@ -0,0 +1,97 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	var body []byte
+	var auth string
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		auth = r.Header.Get("Authorization")
+	}))
+	defer s.Close()
+
+	w, err := New(Config{Type: TypeWebhook, URL: s.URL, Headers: map[string]string{"Authorization": "Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	err = w.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	n := Notification{}
+
+	err = json.Unmarshal(body, &n)
+	if err != nil {
+		t.Fatalf("Expected a JSON body, but got %s", body)
+	}
+
+	if n.Rule != "frost" || n.Value != -1.6 || auth != "Bearer secret" {
+		t.Errorf("Unexpected notification %+v with authorization %q", n, auth)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	var body []byte
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	w, err := New(Config{Type: TypeWebhook, URL: s.URL, Body: `{"text": {{json .Title}}, "zip": "{{.Zip}}"}`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	err = w.Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := `{"text": "frost (3006)", "zip": "3006"}`
+	if string(body) != expected {
+		t.Errorf("Expected body to be %s, but got %s", expected, body)
+	}
+}
+
+func TestWebhookStatus(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	w, _ := New(Config{Type: TypeWebhook, URL: s.URL})
+
+	if err := w.Notify(context.Background(), testNotification()); err == nil {
+		t.Errorf("Expected an error for status 500")
+	}
+}
@@ -0,0 +1,363 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+)
+
+const (
+	// Width of the value labels left of the charts.
+	chartAxisWidth = 9
+
+	// Characters of the charts.
+	bandChar  = '░'
+	nightChar = '·'
+)
+
+// Eighth blocks used for the bars, from empty to full.
+var barBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// ChartOptions control how the hourly charts are drawn.
+type ChartOptions struct {
+	// Width of the whole chart in columns.
+	Width int
+	// Height of the temperature and the precipitation chart in rows.
+	TemperatureRows   int
+	PrecipitationRows int
+	// Shades the hours between sunset and sunrise.
+	ShadeNight bool
+	// Sunrise and sunset times used for the shading.
+	Sunrise []time.Time
+	Sunset  []time.Time
+}
+
+// Returns the default options for a chart of the given width.
+func DefaultChartOptions(width int) ChartOptions {
+	return ChartOptions{
+		Width:             width,
+		TemperatureRows:   8,
+		PrecipitationRows: 4,
+	}
+}
+
+// Renders the temperature as a line with the band between the minimum and the
+// maximum and the precipitation as bars, followed by the time axis in Swiss
+// time.
+func RenderChart(h report.Hourly, o ChartOptions) string {
+	hours := make([]weather.Hour, 0, len(h.Hours))
+	for _, v := range h.Hours {
+		hours = append(hours, v.Hour)
+	}
+
+	lines := []string{fmt.Sprintf("%s %s", h.Zip, h.Location)}
+	lines = append(lines, RenderChartLines(hours, o)...)
+
+	return strings.Join(lines, "\n")
+}
+
+// Renders the charts of the hours as separate lines.
+func RenderChartLines(hours []weather.Hour, o ChartOptions) []string {
+	width := o.Width - chartAxisWidth - 1
+	if len(hours) == 0 || width < 2 {
+		return nil
+	}
+
+	// Whether each column of the chart lies in the night
+	night := make([]bool, width)
+
+	if o.ShadeNight {
+		for c := range night {
+			night[c] = isNight(columnTime(hours, c, width), o.Sunrise, o.Sunset)
+		}
+	}
+
+	lines := []string{"Temperature (°C)"}
+	lines = append(lines, temperatureChart(hours, width, o.TemperatureRows, night)...)
+	lines = append(lines, "Precipitation (mm/h)")
+	lines = append(lines, precipitationChart(hours, width, o.PrecipitationRows, night)...)
+	lines = append(lines, timeAxis(hours, width)...)
+
+	return lines
+}
+
+// Draws the mean temperature as a braille line over the min/max band.
+func temperatureChart(hours []weather.Hour, width int, rows int, night []bool) []string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+
+	for _, h := range hours {
+		lo = math.Min(lo, math.Min(h.TemperatureMin, h.TemperatureMean))
+		hi = math.Max(hi, math.Max(h.TemperatureMax, h.TemperatureMean))
+	}
+
+	if hi == lo {
+		hi, lo = hi+1, lo-1
+	}
+
+	// Each braille character has two columns and four rows of dots
+	dotRows := rows * 4
+	dotY := func(v float64) int {
+		return int(math.Round((hi - v) / (hi - lo) * float64(dotRows-1)))
+	}
+
+	dots := make([][]bool, dotRows)
+	for y := range dots {
+		dots[y] = make([]bool, width*2)
+	}
+
+	prev := -1
+
+	for x := 0; x < width*2; x++ {
+		y := dotY(interpolate(hours, float64(x)/float64(width*2-1), func(h weather.Hour) float64 {
+			return h.TemperatureMean
+		}))
+
+		// Connect steep changes with a vertical line
+		from, to := y, y
+		if prev >= 0 {
+			from, to = min(prev, y), max(prev, y)
+		}
+
+		for i := from; i <= to; i++ {
+			dots[i][x] = true
+		}
+
+		prev = y
+	}
+
+	lines := make([]string, rows)
+
+	for r := 0; r < rows; r++ {
+		var b strings.Builder
+
+		b.WriteString(valueLabel(r, rows, hi, lo, "%.1f"))
+
+		for c := 0; c < width; c++ {
+			pos := (float64(c) + 0.5) / float64(width)
+			top := dotY(interpolate(hours, pos, func(h weather.Hour) float64 { return h.TemperatureMax })) / 4
+			bottom := dotY(interpolate(hours, pos, func(h weather.Hour) float64 { return h.TemperatureMin })) / 4
+
+			switch ch := braille(dots, c*2, r*4); {
+			case ch != 0:
+				mean := interpolate(hours, pos, func(h weather.Hour) float64 { return h.TemperatureMean })
+				b.WriteString(paintTemperature(mean, string(ch)))
+			case r >= top && r <= bottom:
+				b.WriteRune(bandChar)
+			case night[c]:
+				b.WriteRune(nightChar)
+			default:
+				b.WriteRune(' ')
+			}
+		}
+
+		lines[r] = b.String()
+	}
+
+	return lines
+}
+
+// Draws the precipitation as bars made of eighth blocks.
+func precipitationChart(hours []weather.Hour, width int, rows int, night []bool) []string {
+	values := make([]float64, width)
+	hi := 0.0
+
+	for c := range values {
+		// Columns spanning several hours show the wettest one
+		from, to := columnHours(len(hours), c, width)
+		for i := from; i <= to; i++ {
+			values[c] = math.Max(values[c], hours[i].Precipitation)
+		}
+
+		hi = math.Max(hi, values[c])
+	}
+
+	// Keep light rain from looking like a downpour
+	hi = math.Max(hi, 1)
+
+	lines := make([]string, rows)
+
+	for r := 0; r < rows; r++ {
+		var b strings.Builder
+
+		b.WriteString(valueLabel(r, rows, hi, 0, "%.1f"))
+
+		// Row zero is the top of the chart
+		base := (rows - 1 - r) * 8
+
+		for c, v := range values {
+			eighths := int(math.Round(v / hi * float64(rows*8)))
+			if v > 0 && eighths == 0 {
+				eighths = 1
+			}
+
+			level := max(0, min(8, eighths-base))
+
+			if level == 0 && night[c] {
+				b.WriteRune(nightChar)
+				continue
+			}
+
+			b.WriteString(paintRain(v, string(barBlocks[level])))
+		}
+
+		lines[r] = b.String()
+	}
+
+	return lines
+}
+
+// Returns the axis with the hours and the dates in Swiss time.
+func timeAxis(hours []weather.Hour, width int) []string {
+	hourRow := []rune(strings.Repeat(" ", chartAxisWidth+1+width))
+	dayRow := []rune(strings.Repeat(" ", chartAxisWidth+1+width))
+
+	for c := 0; c < width; c++ {
+		from, _ := columnHours(len(hours), c, width)
+
+		// Only label the first column of an hour
+		if c > 0 {
+			if prev, _ := columnHours(len(hours), c-1, width); prev == from {
+				continue
+			}
+		}
+
+		t := hours[from].Time.In(weather.Zurich)
+
+		if t.Hour()%6 == 0 {
+			write(hourRow, chartAxisWidth+1+c, t.Format("15"))
+		}
+
+		if t.Hour() == 0 || c == 0 {
+			write(dayRow, chartAxisWidth+1+c, t.Format("Mon 02.01"))
+		}
+	}
+
+	return []string{strings.TrimRight(string(hourRow), " "), strings.TrimRight(string(dayRow), " ")}
+}
+
+// Returns the value label of a chart row, only the top and the bottom row are
+// labelled.
+func valueLabel(r int, rows int, hi float64, lo float64, format string) string {
+	label := ""
+
+	switch r {
+	case 0:
+		label = fmt.Sprintf(format, hi)
+	case rows - 1:
+		label = fmt.Sprintf(format, lo)
+	}
+
+	return fmt.Sprintf("%*s │", chartAxisWidth-1, label)
+}
+
+// Returns the braille character of the 2x4 dots at x and y or 0 if none is set.
+func braille(dots [][]bool, x int, y int) rune {
+	// Bits of the dots, column by column from the top
+	bits := [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+	var ch rune
+
+	for dx := 0; dx < 2; dx++ {
+		for dy := 0; dy < 4; dy++ {
+			if dots[y+dy][x+dx] {
+				ch |= bits[dx][dy]
+			}
+		}
+	}
+
+	if ch == 0 {
+		return 0
+	}
+
+	return 0x2800 + ch
+}
+
+// Interpolates a value of the hours at the relative position between 0 and 1.
+func interpolate(hours []weather.Hour, pos float64, value func(weather.Hour) float64) float64 {
+	if len(hours) == 1 {
+		return value(hours[0])
+	}
+
+	x := pos * float64(len(hours)-1)
+	i := int(math.Floor(x))
+
+	if i >= len(hours)-1 {
+		return value(hours[len(hours)-1])
+	}
+
+	f := x - float64(i)
+
+	return value(hours[i])*(1-f) + value(hours[i+1])*f
+}
+
+// Returns the range of hours shown in a column of the chart.
+func columnHours(n int, c int, width int) (from int, to int) {
+	from = c * n / width
+	to = (c+1)*n/width - 1
+
+	if to < from {
+		to = from
+	}
+
+	return from, min(to, n-1)
+}
+
+// Returns the time in the middle of a column.
+func columnTime(hours []weather.Hour, c int, width int) time.Time {
+	start := hours[0].Time
+	span := time.Duration(len(hours)) * time.Hour
+
+	return start.Add(time.Duration((float64(c) + 0.5) / float64(width) * float64(span)))
+}
+
+// Returns whether the time is outside of all the days between sunrise and sunset.
+func isNight(t time.Time, sunrise []time.Time, sunset []time.Time) bool {
+	for i := range sunrise {
+		if i < len(sunset) && !t.Before(sunrise[i]) && t.Before(sunset[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Writes the string into the row starting at i, as far as it fits. Nothing is
+// written if it would overwrite or touch a previous label.
+func write(row []rune, i int, s string) {
+	for j := i - 1; j < i+len([]rune(s)) && j < len(row); j++ {
+		if j >= 0 && row[j] != ' ' {
+			return
+		}
+	}
+
+	for _, r := range s {
+		if i >= len(row) {
+			return
+		}
+
+		row[i] = r
+		i++
+	}
+}
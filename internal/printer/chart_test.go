@@ -0,0 +1,118 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+func testHours(n int) []weather.Hour {
+	// Midnight in Switzerland
+	start := time.Date(2023, 5, 7, 0, 0, 0, 0, weather.Zurich)
+	hours := make([]weather.Hour, n)
+
+	for i := range hours {
+		hours[i] = weather.Hour{
+			Time:            start.Add(time.Duration(i) * time.Hour),
+			TemperatureMean: float64(10 + i%12),
+			TemperatureMin:  float64(9 + i%12),
+			TemperatureMax:  float64(12 + i%12),
+		}
+	}
+
+	hours[3].Precipitation = 2
+	hours[4].Precipitation = 0.05
+
+	return hours
+}
+
+func TestRenderChartLines(t *testing.T) {
+	o := DefaultChartOptions(58)
+	lines := RenderChartLines(testHours(48), o)
+
+	// Two titles, both charts and two axis rows
+	if expected := 2 + o.TemperatureRows + o.PrecipitationRows + 2; len(lines) != expected {
+		t.Fatalf("Expected %d lines, but got %d", expected, len(lines))
+	}
+
+	for _, l := range lines {
+		if n := len([]rune(l)); n > 58 {
+			t.Errorf("Expected lines to fit into 58 columns, but got %d: %q", n, l)
+		}
+	}
+
+	chart := strings.Join(lines, "\n")
+
+	if !strings.ContainsRune(chart, bandChar) {
+		t.Errorf("Expected the chart to contain the min/max band:\n%s", chart)
+	}
+
+	if !strings.Contains(lines[1], "23.0 │") || !strings.Contains(lines[o.TemperatureRows], "9.0 │") {
+		t.Errorf("Expected the temperature range as labels:\n%s", chart)
+	}
+
+	// The bar of 2 mm at the fourth hour reaches the top, the drizzle is a sliver
+	precipitation := lines[2+o.TemperatureRows : 2+o.TemperatureRows+o.PrecipitationRows]
+	if []rune(precipitation[0])[chartAxisWidth+1+3] != '█' {
+		t.Errorf("Expected a full bar:\n%s", strings.Join(precipitation, "\n"))
+	}
+
+	if []rune(precipitation[o.PrecipitationRows-1])[chartAxisWidth+1+4] != '▁' {
+		t.Errorf("Expected a sliver:\n%s", strings.Join(precipitation, "\n"))
+	}
+
+	axis := lines[len(lines)-2:]
+	if !strings.HasPrefix(axis[0][chartAxisWidth+1:], "00    06") || !strings.Contains(axis[1], "Sun 07.05") ||
+		!strings.Contains(axis[1], "Mon 08.05") {
+		t.Errorf("Unexpected time axis:\n%s", strings.Join(axis, "\n"))
+	}
+}
+
+func TestRenderChartShadesNight(t *testing.T) {
+	hours := testHours(24)
+
+	o := DefaultChartOptions(34)
+	o.ShadeNight = true
+	o.Sunrise = []time.Time{hours[6].Time}
+	o.Sunset = []time.Time{hours[20].Time}
+
+	lines := RenderChartLines(hours, o)
+	bottom := []rune(lines[1+o.TemperatureRows+o.PrecipitationRows])
+
+	if bottom[chartAxisWidth+1] != nightChar || bottom[chartAxisWidth+1+12] == nightChar {
+		t.Errorf("Expected only the night to be shaded: %q", string(bottom))
+	}
+}
+
+func TestBraille(t *testing.T) {
+	dots := [][]bool{
+		{true, false},
+		{false, false},
+		{false, false},
+		{false, true},
+	}
+
+	if got := braille(dots, 0, 0); got != '⢁' {
+		t.Errorf("Expected ⢁, but got %q", got)
+	}
+}
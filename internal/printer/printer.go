@@ -1,20 +1,728 @@
 package printer
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/darox/sunly/internal/activity"
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/verify"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/astro"
+	"github.com/darox/sunly/pkg/derived"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
-func PrintCurrentTemperature(zip string, location string, temperature float64, updatedAt string) {
+// Marker appended to the update time of data that is too old.
+const staleMarker = "(stale)"
+
+// Options control the optional parts and the colors of the output.
+type Options struct {
+	// Adds sparklines of the next hours to the tables.
+	Sparklines bool
+	// Adds the feels like temperature and the other derived quantities to the
+	// tables.
+	Derived bool
+	// Draws the sparklines with ASCII characters only.
+	ASCII bool
+	// Colors supported by the terminal, NoColor turns off the theme.
+	Colors ColorDepth
+	Theme  Theme
+}
+
+var options = Options{}
+
+// Sets the options used by all the print and render functions.
+func SetOptions(o Options) {
+	options = o
+}
+
+func PrintCurrentTemperature(zip string, location string, temperature float64, d derived.Quantities, updatedAt string,
+	stale bool, hours []weather.Hour) {
+	fmt.Println(RenderCurrentTemperature(zip, location, temperature, d, updatedAt, stale, hours))
+}
+
+// Renders the current temperature. The hours are used for the sparklines of the
+// next 24 hours.
+func RenderCurrentTemperature(zip string, location string, temperature float64, d derived.Quantities, updatedAt string,
+	stale bool, hours []weather.Hour) string {
+	t := table.NewWriter()
+
+	header := table.Row{"Zip", "Location", "Temperature", "Updated at"}
+
+	c := paintTemperature(temperature, fmt.Sprintf("%.1f °C", temperature))
+
+	if stale {
+		updatedAt = fmt.Sprintf("%s %s", updatedAt, paint(options.Theme.Stale, staleMarker))
+	}
+
+	row := table.Row{zip, location, c, updatedAt}
+
+	if options.Derived {
+		humid := hasHumidity([]derived.Quantities{d})
+		header = append(header, derivedHeader(humid)...)
+		row = append(row, derivedCells(d, humid)...)
+	}
+
+	if options.Sparklines {
+		// Start with the hour that is currently running
+		from := time.Now().Truncate(time.Hour)
+		temperatures, precipitation := sparklines(hours, from, from.Add(sparkHours*time.Hour))
+
+		header = append(header, "Next 24h", "Rain 24h")
+		row = append(row, temperatures, precipitation)
+	}
+
+	t.AppendHeader(paintHeader(header))
+	t.AppendRows([]table.Row{row})
+
+	return t.Render()
+}
+
+// Prints any value as indented JSON.
+func PrintJSON(v any) error {
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+
+	return e.Encode(v)
+}
+
+// Renders any value as indented JSON.
+func RenderJSON(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Renders the daily forecast. The hours are used for the sparklines of each
+// day.
+func RenderForecast(f report.Forecast, hours []weather.Hour) string {
+	t := table.NewWriter()
+
+	t.SetTitle(fmt.Sprintf("%s %s", f.Zip, f.Location))
+
+	header := table.Row{"Day", "Min", "Max", "Precipitation"}
+	if options.Sparklines {
+		header = append(header, "Temperature", "Rain")
+	}
+
+	t.AppendHeader(paintHeader(header))
+
+	for _, d := range f.Days {
+		row := table.Row{
+			d.DayDate,
+			paintTemperature(d.TemperatureMin, fmt.Sprintf("%.0f °C", d.TemperatureMin)),
+			paintTemperature(d.TemperatureMax, fmt.Sprintf("%.0f °C", d.TemperatureMax)),
+			paintRain(d.Precipitation, fmt.Sprintf("%.1f mm", d.Precipitation)),
+		}
+
+		if options.Sparklines {
+			// Days without hourly values get empty sparklines
+			from, err := time.ParseInLocation("2006-01-02", d.DayDate, weather.Zurich)
+			if err == nil {
+				temperatures, precipitation := sparklines(hours, from, from.AddDate(0, 0, 1))
+				row = append(row, temperatures, precipitation)
+			}
+		}
+
+		t.AppendRow(row)
+	}
+
+	if len(f.Warnings) == 0 {
+		return t.Render()
+	}
+
+	return t.Render() + "\n" + RenderWarnings(f.Warnings)
+}
+
+// Renders the consensus of the daily forecasts with the values of each source.
+func RenderEnsemble(e report.Ensemble) string {
+	t := table.NewWriter()
+
+	t.SetTitle(fmt.Sprintf("%s %s", e.Zip, e.Location))
+
+	header := table.Row{"Day", "Min", "Max", "Precipitation"}
+	for _, s := range e.Sources {
+		header = append(header, s)
+	}
+
+	t.AppendHeader(paintHeader(append(header, "")))
+
+	for _, d := range e.Days {
+		row := table.Row{
+			d.DayDate,
+			paintTemperature(d.TemperatureMin.Mean, fmt.Sprintf("%.0f °C (%.0f)", d.TemperatureMin.Mean, d.TemperatureMin.Spread)),
+			paintTemperature(d.TemperatureMax.Mean, fmt.Sprintf("%.0f °C (%.0f)", d.TemperatureMax.Mean, d.TemperatureMax.Spread)),
+			paintRain(d.Precipitation.Mean, fmt.Sprintf("%.1f mm (%.1f)", d.Precipitation.Mean, d.Precipitation.Spread)),
+		}
+
+		// Sources without this day get a dash
+		for _, s := range e.Sources {
+			cell := "-"
+
+			for _, v := range d.Sources {
+				if v.Source == s {
+					cell = fmt.Sprintf("%.0f/%.0f °C %.1f mm", v.TemperatureMin, v.TemperatureMax, v.Precipitation)
+				}
+			}
+
+			row = append(row, cell)
+		}
+
+		flag := ""
+		if d.Disagree {
+			flag = paintWarning(3, "disagree")
+		}
+
+		t.AppendRow(append(row, flag))
+	}
+
+	footer := table.Row{"", "mean (spread)", "mean (spread)", "mean (spread)"}
+	for range e.Sources {
+		footer = append(footer, "min/max rain")
+	}
+
+	t.AppendFooter(append(footer, ""))
+
+	if len(e.Errors) == 0 {
+		return t.Render()
+	}
+
+	failed := make([]string, 0, len(e.Errors))
+	for name, err := range e.Errors {
+		failed = append(failed, fmt.Sprintf("Source %s failed: %s", name, err))
+	}
+
+	sort.Strings(failed)
+
+	return t.Render() + "\n" + strings.Join(failed, "\n")
+}
+
+// Renders the measurement of a station, missing values are left out.
+func RenderStation(s report.Station) string {
+	t := table.NewWriter()
+
+	t.SetTitle(fmt.Sprintf("%s (%s), %.1f km from %s", s.Station.Name, s.Station.Abbr, s.Station.Distance,
+		strings.TrimSpace(s.Zip+" "+s.Location)))
+	t.AppendHeader(paintHeader(table.Row{"Measurement", "Value"}))
+
+	m := s.Measurement
+
+	t.AppendRow(table.Row{"Time", formatTime(m.Time, "15:04 02.01.2006")})
+
+	add := func(name string, v *float64, format string, paint func(float64, string) string) {
+		if v == nil {
+			return
+		}
+
+		value := fmt.Sprintf(format, *v)
+		if paint != nil {
+			value = paint(*v, value)
+		}
+
+		t.AppendRow(table.Row{name, value})
+	}
+
+	add("Temperature", m.Temperature, "%.1f °C", paintTemperature)
+	add("Humidity", m.Humidity, "%.0f %%", nil)
+	add("Dew point", m.DewPoint, "%.1f °C", nil)
+	add("Pressure", m.Pressure, "%.1f hPa", nil)
+	add("Pressure at sea level", m.PressureSeaLevel, "%.1f hPa", nil)
+	add("Wind", m.WindSpeed, "%.1f km/h", nil)
+	add("Wind direction", m.WindDirection, "%.0f°", nil)
+	add("Gusts", m.WindGust, "%.1f km/h", nil)
+	add("Precipitation", m.Precipitation, "%.1f mm", paintRain)
+	add("Sunshine", m.Sunshine, "%.0f min", nil)
+	add("Radiation", m.Radiation, "%.0f W/m²", nil)
+
+	return t.Render()
+}
+
+func PrintAstro(a report.Astro) {
+	fmt.Println(RenderAstro(a))
+}
+
+// Renders the times of the sun, the golden and blue hours and the moon as
+// separate tables.
+func RenderAstro(a report.Astro) string {
+	title := strings.TrimSpace(fmt.Sprintf("%s %s", a.Zip, a.Location))
+
+	sun := table.NewWriter()
+	sun.SetTitle(fmt.Sprintf("%s sun", title))
+	sun.AppendHeader(paintHeader(table.Row{"Day", "Dawn", "Sunrise", "Noon", "Elevation", "Sunset", "Dusk"}))
+
+	photo := table.NewWriter()
+	photo.SetTitle(fmt.Sprintf("%s golden and blue hour", title))
+	photo.AppendHeader(paintHeader(table.Row{"Day", "Blue hour", "Golden hour", "Golden hour", "Blue hour"}))
+
+	moon := table.NewWriter()
+	moon.SetTitle(fmt.Sprintf("%s moon", title))
+	moon.AppendHeader(paintHeader(table.Row{"Day", "Moonrise", "Moonset", "Phase", "Illumination"}))
+
+	for _, d := range a.Days {
+		s := d.Sun
+
+		sun.AppendRow(table.Row{
+			d.Date,
+			fmt.Sprintf("%s / %s / %s", formatAstroClock(s.AstronomicalDawn), formatAstroClock(s.NauticalDawn),
+				formatAstroClock(s.CivilDawn)),
+			formatAstroClock(s.Sunrise),
+			formatAstroClock(s.SolarNoon),
+			fmt.Sprintf("%.1f°", s.NoonElevation),
+			formatAstroClock(s.Sunset),
+			fmt.Sprintf("%s / %s / %s", formatAstroClock(s.CivilDusk), formatAstroClock(s.NauticalDusk),
+				formatAstroClock(s.AstronomicalDusk)),
+		})
+
+		photo.AppendRow(table.Row{
+			d.Date,
+			formatPeriod(s.BlueHourMorning),
+			formatPeriod(s.GoldenHourMorning),
+			formatPeriod(s.GoldenHourEvening),
+			formatPeriod(s.BlueHourEvening),
+		})
+
+		moon.AppendRow(table.Row{
+			d.Date,
+			formatAstroClock(d.Moon.Moonrise),
+			formatAstroClock(d.Moon.Moonset),
+			d.Moon.PhaseName,
+			fmt.Sprintf("%.0f%%", d.Moon.Illumination*100),
+		})
+	}
+
+	sun.AppendFooter(table.Row{"", "astronomical / nautical / civil", "", "", "", "", "civil / nautical / astronomical"})
+
+	return sun.Render() + "\n" + photo.Render() + "\n" + moon.Render()
+}
+
+// Prints the time windows that meet a condition.
+func PrintWhen(w report.When) {
+	fmt.Println(RenderWhen(w))
+}
+
+// Renders the time windows that meet a condition.
+func RenderWhen(w report.When) string {
+	t := table.NewWriter()
+	t.SetTitle(strings.TrimSpace(fmt.Sprintf("%s %s: %s", w.Zip, w.Location, w.Condition)))
+	t.AppendHeader(paintHeader(table.Row{"From", "To", "Duration"}))
+
+	for _, wi := range w.Windows {
+		t.AppendRow(table.Row{
+			formatTime(wi.Start, "Mon 02.01. 15:04"),
+			formatTime(wi.End, "Mon 02.01. 15:04"),
+			fmt.Sprintf("%.0fh", wi.End.Sub(wi.Start).Hours()),
+		})
+	}
+
+	return t.Render()
+}
+
+// Prints the best times for an activity.
+func PrintPlan(p report.Plan, hourly bool) {
+	fmt.Println(RenderPlan(p, hourly))
+}
+
+// Renders the best windows, the best window of every day and with hourly also
+// the scores of the hours.
+func RenderPlan(p report.Plan, hourly bool) string {
+	title := strings.TrimSpace(fmt.Sprintf("%s %s", p.Zip, p.Location))
+
+	best := table.NewWriter()
+	best.SetTitle(fmt.Sprintf("%s best times for %s", title, p.Activity))
+	best.AppendHeader(paintHeader(table.Row{"#", "Time", "Score"}))
+
+	for i, w := range p.Windows {
+		best.AppendRow(table.Row{i + 1, formatWindow(w), w.Score})
+	}
+
+	days := table.NewWriter()
+	days.SetTitle(fmt.Sprintf("%s %s by day", title, p.Activity))
+	days.AppendHeader(paintHeader(table.Row{"Day", "Best time", "Score"}))
+
+	for _, d := range p.Days {
+		days.AppendRow(table.Row{d.Date, fmt.Sprintf("%s - %s", formatClock(d.Best.Start), formatClock(d.Best.End)),
+			d.Best.Score})
+	}
+
+	result := best.Render() + "\n" + days.Render()
+
+	if !hourly {
+		return result
+	}
+
+	hours := table.NewWriter()
+	hours.SetTitle(fmt.Sprintf("%s hourly scores for %s", title, p.Activity))
+	hours.AppendHeader(paintHeader(table.Row{"Time", "Score", "Temperature", "Precipitation", "Wind", "Daylight"}))
+
+	for _, h := range p.Hours {
+		hours.AppendRow(table.Row{
+			formatTime(h.Time, "02.01. 15:04"),
+			h.Score,
+			fmt.Sprintf("%.0f%%", h.Temperature*100),
+			fmt.Sprintf("%.0f%%", h.Precipitation*100),
+			fmt.Sprintf("%.0f%%", h.Wind*100),
+			fmt.Sprintf("%.0f%%", h.Daylight*100),
+		})
+	}
+
+	return result + "\n" + hours.Render()
+}
+
+// Formats the window like "Mon 02.01. 15:00 - 17:00".
+func formatWindow(w activity.Window) string {
+	return fmt.Sprintf("%s - %s", formatTime(w.Start, "Mon 02.01. 15:04"), formatTime(w.End, "15:04"))
+}
+
+// Prints the estimated production of the PV system.
+func PrintSolar(s report.Solar, hourly bool) {
+	fmt.Println(RenderSolar(s, hourly))
+}
+
+// Renders the daily production of the PV system and with hourly also the
+// production of the hours with any.
+func RenderSolar(s report.Solar, hourly bool) string {
+	title := strings.TrimSpace(fmt.Sprintf("%s %s", s.Zip, s.Location))
+	panel := fmt.Sprintf("%g kWp, %g° tilt, %g° azimuth", s.Panel.KWp, s.Panel.Tilt, s.Panel.Azimuth)
+
+	days := table.NewWriter()
+	days.SetTitle(fmt.Sprintf("%s PV production (%s)", title, panel))
+	days.AppendHeader(paintHeader(table.Row{"Day", "Energy", "Clear sky", "Share", "Best hour"}))
+
+	total := 0.0
+
+	for _, d := range s.Days {
+		share := "-"
+		if d.ClearSkyEnergy > 0 {
+			share = fmt.Sprintf("%.0f%%", d.Energy/d.ClearSkyEnergy*100)
+		}
+
+		days.AppendRow(table.Row{
+			d.Date,
+			fmt.Sprintf("%.1f kWh", d.Energy),
+			fmt.Sprintf("%.1f kWh", d.ClearSkyEnergy),
+			share,
+			formatClock(d.Peak),
+		})
+
+		total += d.Energy
+	}
+
+	// A footer would shout the unit
+	days.AppendSeparator()
+	days.AppendRow(table.Row{"Total", fmt.Sprintf("%.1f kWh", total)})
+
+	result := days.Render()
+
+	if !hourly {
+		return result
+	}
+
+	hours := table.NewWriter()
+	hours.SetTitle(fmt.Sprintf("%s hourly PV production", title))
+	hours.AppendHeader(paintHeader(table.Row{"Time", "Sun", "Clouds", "Irradiance", "Energy", "Clear sky"}))
+
+	for _, h := range s.Hours {
+		if h.ClearSkyEnergy == 0 {
+			continue
+		}
+
+		hours.AppendRow(table.Row{
+			formatTime(h.Time, "02.01. 15:04"),
+			fmt.Sprintf("%.0f°", h.Elevation),
+			fmt.Sprintf("%.0f%%", h.CloudCover*100),
+			fmt.Sprintf("%.0f W/m²", h.Irradiance),
+			fmt.Sprintf("%.2f kWh", h.Energy),
+			fmt.Sprintf("%.2f kWh", h.ClearSkyEnergy),
+		})
+	}
+
+	return result + "\n" + hours.Render()
+}
+
+// Formats the time in Swiss time, which the tables show no matter where sunly
+// runs. Only the astro tables keep the time zone the times are in, which can be
+// chosen with --tz.
+func formatTime(t time.Time, layout string) string {
+	return t.In(weather.Zurich).Format(layout)
+}
+
+// Formats the clock time, a dash for a time that doesn't occur.
+func formatClock(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return formatTime(t, "15:04")
+}
+
+// Formats the clock time of the astro tables in the time zone of the time, a
+// dash for a time that doesn't occur.
+func formatAstroClock(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format("15:04")
+}
+
+func formatPeriod(p astro.Period) string {
+	if p.Start.IsZero() {
+		return "-"
+	}
+
+	return fmt.Sprintf("%s - %s", formatAstroClock(p.Start), formatAstroClock(p.End))
+}
+
+// Renders the weather warnings, colored by their level.
+func RenderWarnings(warnings []weather.Warning) string {
+	t := table.NewWriter()
+
+	t.SetTitle("Warnings")
+	t.AppendHeader(paintHeader(table.Row{"Level", "Type", "Valid", "Details"}))
+
+	for _, w := range warnings {
+		t.AppendRow(table.Row{
+			paintWarning(w.Level, fmt.Sprintf("%d", w.Level)),
+			paintWarning(w.Level, w.Type),
+			fmt.Sprintf("%s - %s", formatTime(w.ValidFrom, "15:04 02.01."), formatValidTo(w.ValidTo)),
+			w.Text,
+		})
+	}
+
+	return t.Render()
+}
+
+// Formats the end of a warning, which may not be known yet.
+func formatValidTo(t time.Time) string {
+	if t.IsZero() {
+		return "open"
+	}
+
+	return formatTime(t, "15:04 02.01.")
+}
+
+func RenderHourly(h report.Hourly) string {
 	t := table.NewWriter()
 
-	t.AppendHeader(table.Row{"Zip", "Location", "Temperature", "Updated at"})
+	t.SetTitle(fmt.Sprintf("%s %s", h.Zip, h.Location))
+
+	ds := make([]derived.Quantities, 0, len(h.Hours))
+	for _, v := range h.Hours {
+		ds = append(ds, v.Derived)
+	}
+
+	humid := hasHumidity(ds)
+
+	header := table.Row{"Time", "Temperature", "Min", "Max", "Precipitation", "Wind"}
+	if options.Derived {
+		header = append(header, derivedHeader(humid)...)
+	}
+
+	t.AppendHeader(paintHeader(header))
+
+	for _, v := range h.Hours {
+		row := table.Row{
+			formatTime(v.Time, "15:04 02.01.2006"),
+			paintTemperature(v.TemperatureMean, fmt.Sprintf("%.1f °C", v.TemperatureMean)),
+			paintTemperature(v.TemperatureMin, fmt.Sprintf("%.1f °C", v.TemperatureMin)),
+			paintTemperature(v.TemperatureMax, fmt.Sprintf("%.1f °C", v.TemperatureMax)),
+			paintRain(v.Precipitation, fmt.Sprintf("%.1f mm", v.Precipitation)),
+			fmt.Sprintf("%.0f km/h", v.WindSpeed),
+		}
+
+		if options.Derived {
+			row = append(row, derivedCells(v.Derived, humid)...)
+		}
+
+		t.AppendRow(row)
+	}
+
+	return t.Render()
+}
+
+// Reports whether any of the quantities was derived with the humidity, which
+// not every provider has. The dew point is derived whenever it is known.
+func hasHumidity(ds []derived.Quantities) bool {
+	for _, d := range ds {
+		if d.DewPoint != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the header of the derived quantities. Without the humidity, the
+// columns of the quantities that need it are left out.
+func derivedHeader(humid bool) table.Row {
+	header := table.Row{"Feels like", "Wind chill"}
+	if humid {
+		header = append(header, "Heat index", "Humidex", "Dew point")
+	}
+
+	return header
+}
+
+// Returns the cells of the derived quantities, a dash for the missing ones.
+func derivedCells(d derived.Quantities, humid bool) table.Row {
+	cell := func(v *float64, unit string) string {
+		if v == nil {
+			return "-"
+		}
+
+		return fmt.Sprintf("%.1f%s", *v, unit)
+	}
+
+	row := table.Row{
+		paintTemperature(d.FeelsLike, fmt.Sprintf("%.1f °C", d.FeelsLike)),
+		cell(d.WindChill, " °C"),
+	}
+
+	if humid {
+		row = append(row,
+			cell(d.HeatIndex, " °C"),
+			// The humidex has no unit
+			cell(d.Humidex, ""),
+			cell(d.DewPoint, " °C"),
+		)
+	}
+
+	return row
+}
+
+func PrintHistory(zip string, obs []history.Observation) {
+	fmt.Println(RenderHistory(zip, obs))
+}
+
+func RenderHistory(zip string, obs []history.Observation) string {
+	t := table.NewWriter()
+
+	title := zip
+	if len(obs) > 0 {
+		title = fmt.Sprintf("%s %s", zip, obs[len(obs)-1].Location)
+	}
+
+	t.SetTitle(title)
+	t.AppendHeader(paintHeader(table.Row{"Time", "Temperature", "Precipitation", "Wind"}))
+
+	for _, o := range obs {
+		t.AppendRow(table.Row{
+			formatTime(o.Time, "15:04 02.01.2006"),
+			paintTemperature(o.Temperature, fmt.Sprintf("%.1f °C", o.Temperature)),
+			paintRain(o.Hour.Precipitation, fmt.Sprintf("%.1f mm", o.Hour.Precipitation)),
+			fmt.Sprintf("%.0f km/h", o.Hour.WindSpeed),
+		})
+	}
+
+	return t.Render()
+}
+
+func PrintHistoryDays(zip string, days []history.Day) {
+	fmt.Println(RenderHistoryDays(zip, days))
+}
+
+func RenderHistoryDays(zip string, days []history.Day) string {
+	t := table.NewWriter()
+
+	t.SetTitle(zip)
+	t.AppendHeader(paintHeader(table.Row{"Date", "Min", "Max", "Mean", "Precipitation", "Observations"}))
+
+	for _, d := range days {
+		t.AppendRow(table.Row{
+			d.Date,
+			paintTemperature(d.TemperatureMin, fmt.Sprintf("%.1f °C", d.TemperatureMin)),
+			paintTemperature(d.TemperatureMax, fmt.Sprintf("%.1f °C", d.TemperatureMax)),
+			paintTemperature(d.TemperatureMean, fmt.Sprintf("%.1f °C", d.TemperatureMean)),
+			paintRain(d.Precipitation, fmt.Sprintf("%.1f mm", d.Precipitation)),
+			d.Observations,
+		})
+	}
+
+	return t.Render()
+}
+
+func PrintVerification(zip string, leads []verify.Lead) {
+	fmt.Println(RenderVerification(zip, leads))
+}
+
+func RenderVerification(zip string, leads []verify.Lead) string {
+	t := table.NewWriter()
+
+	t.SetTitle(fmt.Sprintf("%s forecast accuracy", zip))
+	t.AppendHeader(paintHeader(table.Row{"Lead", "Hourly", "Max", "Min", "Rain"}))
+
+	for _, l := range leads {
+		t.AppendRow(table.Row{
+			fmt.Sprintf("Day %d", l.Days),
+			formatStats(l.Hourly),
+			formatStats(l.TemperatureMax),
+			formatStats(l.TemperatureMin),
+			formatHitRate(l.Precipitation),
+		})
+	}
+
+	t.AppendFooter(table.Row{"", "bias / MAE / hits", "bias / MAE / hits", "bias / MAE / hits", "hits"})
+
+	return t.Render()
+}
+
+// Formats temperature stats as bias, mean absolute error and hit rate.
+func formatStats(s verify.Stats) string {
+	if s.Count == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%+.1f / %.1f °C / %s", s.Bias, s.MAE, formatHitRate(s))
+}
+
+func formatHitRate(s verify.Stats) string {
+	if s.Count == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.0f%% (%d)", s.HitRate*100, s.Count)
+}
+
+func PrintTriggers(triggers []rules.Trigger) {
+	fmt.Println(RenderTriggers(triggers))
+}
+
+func RenderTriggers(triggers []rules.Trigger) string {
+	t := table.NewWriter()
+
+	t.SetTitle("Triggered rules")
+	t.AppendHeader(paintHeader(table.Row{"Rule", "Zip", "Condition", "Time", "Value"}))
+
+	for _, v := range triggers {
+		t.AppendRow(table.Row{
+			v.Name,
+			v.Zip,
+			v.Condition,
+			formatTime(v.Time, "15:04 02.01.2006"),
+			fmt.Sprintf("%g", v.Value),
+		})
+	}
+
+	return t.Render()
+}
 
-	c := fmt.Sprintf("%.1f °C", temperature)
-	t.AppendRows([]table.Row{
-		{zip, location, c, updatedAt},
-	})
+// Applies the header style of the theme to the header cells.
+func paintHeader(header table.Row) table.Row {
+	for i, h := range header {
+		if s, ok := h.(string); ok {
+			header[i] = paint(options.Theme.Header, s)
+		}
+	}
 
-	fmt.Print(t.Render())
+	return header
 }
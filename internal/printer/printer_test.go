@@ -0,0 +1,67 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/rules"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/derived"
+)
+
+func TestRenderHourlyDerived(t *testing.T) {
+	defer SetOptions(options)
+	SetOptions(Options{Derived: true})
+
+	h := report.Hourly{
+		Zip:      "3006",
+		Location: "Bern",
+		Hours: []report.Hour{{
+			Hour:    weather.Hour{Time: time.Now(), TemperatureMean: 5, WindSpeed: 20},
+			Derived: derived.Derive(5, 0, 20),
+		}},
+	}
+
+	// Without humidity the columns that need it are left out
+	s := RenderHourly(h)
+	if !strings.Contains(s, "WIND CHILL") || strings.Contains(s, "DEW POINT") {
+		t.Errorf("Expected the wind chill without the dew point, but got:\n%s", s)
+	}
+
+	h.Hours[0].Derived = derived.Derive(5, 80, 20)
+
+	s = RenderHourly(h)
+	if !strings.Contains(s, "DEW POINT") || !strings.Contains(s, "HUMIDEX") {
+		t.Errorf("Expected the dew point and the humidex, but got:\n%s", s)
+	}
+}
+
+func TestRenderSwissTime(t *testing.T) {
+	// Noon in UTC is 14:00 in Swiss summer time
+	noon := time.Date(2023, 5, 7, 12, 0, 0, 0, time.UTC)
+
+	out := RenderTriggers([]rules.Trigger{{Name: "rain", Zip: "3006", Time: noon}})
+	if !strings.Contains(out, "14:00 07.05.2023") {
+		t.Errorf("Expected the time in Swiss time, but got:\n%s", out)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Hours covered by the sparklines.
+const sparkHours = 24
+
+var (
+	sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+	sparkASCII  = []rune("_.-:=+*#")
+)
+
+// Returns whether the locale of the terminal uses UTF-8.
+func IsUTF8Locale() bool {
+	// The first variable that is set wins, as in the C library
+	for _, k := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(k); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+
+	return false
+}
+
+// Draws the values as a sparkline scaled between their minimum and maximum.
+// Zero based sparklines start at zero instead and draw zero values as spaces.
+func Sparkline(values []float64, zeroBased bool, ascii bool) string {
+	return sparkline(values, zeroBased, ascii, nil)
+}
+
+// Draws a sparkline and applies the style returned by paint to each character.
+func sparkline(values []float64, zeroBased bool, ascii bool, paint func(v float64, s string) string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	levels := sparkBlocks
+	if ascii {
+		levels = sparkASCII
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	if zeroBased {
+		lo = 0
+	}
+
+	var b strings.Builder
+
+	for _, v := range values {
+		ch := string(levels[0])
+
+		switch {
+		case zeroBased && v <= 0:
+			ch = " "
+		case hi != lo:
+			ch = string(levels[int(math.Round((v-lo)/(hi-lo)*float64(len(levels)-1)))])
+		}
+
+		if paint != nil {
+			ch = paint(v, ch)
+		}
+
+		b.WriteString(ch)
+	}
+
+	return b.String()
+}
+
+// Returns the sparklines of the temperature and the precipitation of the hours
+// in [from, to).
+func sparklines(hours []weather.Hour, from time.Time, to time.Time) (temperature string, precipitation string) {
+	var t, p []float64
+
+	for _, h := range hours {
+		if h.Time.Before(from) || !h.Time.Before(to) {
+			continue
+		}
+
+		t = append(t, h.TemperatureMean)
+		p = append(p, h.Precipitation)
+	}
+
+	return sparkline(t, false, options.ASCII, paintTemperature), sparkline(p, true, options.ASCII, paintRain)
+}
@@ -0,0 +1,85 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []float64
+		zeroBased bool
+		ascii     bool
+		expected  string
+	}{
+		{"range", []float64{10, 12, 14, 17}, false, false, "▁▃▅█"},
+		{"ascii", []float64{10, 12, 14, 17}, false, true, "_-=#"},
+		{"flat", []float64{5, 5}, false, false, "▁▁"},
+		{"zero based", []float64{0, 1, 2}, true, false, " ▅█"},
+		{"empty", nil, false, false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Sparkline(tt.values, tt.zeroBased, tt.ascii); got != tt.expected {
+			t.Errorf("%s: expected %q, but got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestIsUTF8Locale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+	t.Setenv("LANG", "de_CH.UTF-8")
+
+	if !IsUTF8Locale() {
+		t.Errorf("Expected de_CH.UTF-8 to be a UTF-8 locale")
+	}
+
+	t.Setenv("LC_ALL", "C")
+
+	if IsUTF8Locale() {
+		t.Errorf("Expected LC_ALL to take precedence")
+	}
+}
+
+func TestRenderForecastSparklines(t *testing.T) {
+	SetOptions(Options{Sparklines: true})
+	defer SetOptions(Options{})
+
+	start := time.Date(2023, 5, 7, 0, 0, 0, 0, weather.Zurich)
+
+	hours := []weather.Hour{}
+	for i := 0; i < 48; i++ {
+		hours = append(hours, weather.Hour{Time: start.Add(time.Duration(i) * time.Hour), TemperatureMean: float64(i)})
+	}
+
+	f := report.Forecast{Zip: "3006", Location: "Bern", Days: []weather.Day{{DayDate: "2023-05-07"}, {DayDate: "2023-05-08"}}}
+	out := RenderForecast(f, hours)
+
+	if !strings.Contains(out, "TEMPERATURE") || strings.Count(out, "▁▁▂") != 2 || strings.Count(out, "▇██") != 2 {
+		t.Errorf("Expected a sparkline for each day, got:\n%s", out)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ColorDepth is the number of colors a terminal supports.
+type ColorDepth int
+
+const (
+	NoColor ColorDepth = iota
+	Color16
+	Color256
+	TrueColor
+)
+
+// Theme defines the colors of the output. Styles are a space separated list of
+// colors, either as hex value like #ff8800 or by name, and the attributes bold,
+// dim and underline.
+type Theme struct {
+	Header      string
+	Stale       string
+	Temperature []ColorStop
+	Rain        []ColorStop
+	Warning     []ColorStop
+}
+
+// ColorStop applies the style to all values from the given one up to the next
+// stop.
+type ColorStop struct {
+	From  float64
+	Style string
+}
+
+// The built in themes.
+var Themes = map[string]Theme{
+	"default": {
+		Header: "bold",
+		Stale:  "bold #e53935",
+		Temperature: []ColorStop{
+			{math.Inf(-1), "#7986cb"},
+			{-5, "#5c9cff"},
+			{0, "#4fc3f7"},
+			{10, "#66bb6a"},
+			{20, "#fdd835"},
+			{25, "#fb8c00"},
+			{30, "#e53935"},
+		},
+		Rain: []ColorStop{
+			{0.1, "#90caf9"},
+			{1, "#42a5f5"},
+			{4, "#1e63e5"},
+			{10, "#8e24aa"},
+		},
+		Warning: []ColorStop{
+			{2, "#fdd835"},
+			{3, "#fb8c00"},
+			{4, "#e53935"},
+			{5, "bold #8e24aa"},
+		},
+	},
+	"mono": {
+		Header:  "bold",
+		Stale:   "bold",
+		Warning: []ColorStop{{3, "bold"}, {4, "bold underline"}},
+	},
+}
+
+// Colors known by name.
+var namedColors = map[string]string{
+	"black":   "#000000",
+	"red":     "#cd0000",
+	"green":   "#00cd00",
+	"yellow":  "#cdcd00",
+	"blue":    "#0000ee",
+	"magenta": "#cd00cd",
+	"cyan":    "#00cdcd",
+	"white":   "#e5e5e5",
+	"gray":    "#7f7f7f",
+	"orange":  "#ff8700",
+}
+
+// The 16 colors of xterm, used to find the closest one on basic terminals.
+var basicColors = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// Detects how many colors the terminal behind the file supports. NO_COLOR
+// turns off the colors, see https://no-color.org.
+func DetectColorDepth(f *os.File) ColorDepth {
+	if os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(f.Fd())) {
+		return NoColor
+	}
+
+	t := os.Getenv("TERM")
+
+	switch {
+	case t == "dumb":
+		return NoColor
+	case os.Getenv("COLORTERM") == "truecolor" || os.Getenv("COLORTERM") == "24bit":
+		return TrueColor
+	case strings.Contains(t, "256color"):
+		return Color256
+	}
+
+	return Color16
+}
+
+// Validates the styles of the theme.
+func (t Theme) Validate() error {
+	styles := []string{t.Header, t.Stale}
+
+	for _, stops := range [][]ColorStop{t.Temperature, t.Rain, t.Warning} {
+		for _, s := range stops {
+			styles = append(styles, s.Style)
+		}
+	}
+
+	for _, s := range styles {
+		if _, err := escape(s, TrueColor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the style of the last stop the value reaches.
+func styleFor(stops []ColorStop, v float64) string {
+	style := ""
+
+	for _, s := range stops {
+		if v >= s.From {
+			style = s.Style
+		}
+	}
+
+	return style
+}
+
+// Returns the first color of the style as hex value or an empty string if the
+// style has none. Used by outputs that bring their own color syntax.
+func StyleColor(style string) string {
+	for _, token := range strings.Fields(strings.ToLower(style)) {
+		if c, err := parseColor(token); err == nil {
+			return fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+		}
+	}
+
+	return ""
+}
+
+// Returns the color of the temperature in the theme, see StyleColor.
+func (t Theme) TemperatureColor(v float64) string {
+	return StyleColor(styleFor(t.Temperature, v))
+}
+
+// Returns the color of the warning level in the theme, see StyleColor.
+func (t Theme) WarningColor(level int) string {
+	return StyleColor(styleFor(t.Warning, float64(level)))
+}
+
+// Applies the style of the current theme to the string.
+func paint(style string, s string) string {
+	if options.Colors == NoColor || style == "" || s == "" {
+		return s
+	}
+
+	e, err := escape(style, options.Colors)
+	if err != nil || e == "" {
+		return s
+	}
+
+	return e + s + "\x1b[0m"
+}
+
+func paintTemperature(v float64, s string) string {
+	return paint(styleFor(options.Theme.Temperature, v), s)
+}
+
+func paintRain(v float64, s string) string {
+	return paint(styleFor(options.Theme.Rain, v), s)
+}
+
+func paintWarning(level int, s string) string {
+	return paint(styleFor(options.Theme.Warning, float64(level)), s)
+}
+
+// Returns the escape sequence of the style for the color depth.
+func escape(style string, depth ColorDepth) (string, error) {
+	codes := []string{}
+
+	for _, token := range strings.Fields(strings.ToLower(style)) {
+		switch token {
+		case "bold":
+			codes = append(codes, "1")
+		case "dim":
+			codes = append(codes, "2")
+		case "underline":
+			codes = append(codes, "4")
+		default:
+			c, err := parseColor(token)
+			if err != nil {
+				return "", err
+			}
+
+			codes = append(codes, foreground(c, depth))
+		}
+	}
+
+	if len(codes) == 0 {
+		return "", nil
+	}
+
+	return "\x1b[" + strings.Join(codes, ";") + "m", nil
+}
+
+// Parses a color given as hex value or by name.
+func parseColor(s string) ([3]int, error) {
+	if hex, ok := namedColors[s]; ok {
+		s = hex
+	}
+
+	if len(s) != 7 || s[0] != '#' {
+		return [3]int{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return [3]int{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	return [3]int{int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)}, nil
+}
+
+// Returns the SGR parameters to set the foreground color.
+func foreground(c [3]int, depth ColorDepth) string {
+	switch depth {
+	case TrueColor:
+		return fmt.Sprintf("38;2;%d;%d;%d", c[0], c[1], c[2])
+	case Color256:
+		// The 6x6x6 color cube of the 256 colors
+		cube := func(v int) int { return int(math.Round(float64(v) / 255 * 5)) }
+		return fmt.Sprintf("38;5;%d", 16+36*cube(c[0])+6*cube(c[1])+cube(c[2]))
+	case Color16, NoColor:
+	}
+
+	best, dist := 0, math.MaxInt
+	for i, b := range basicColors {
+		d := (b[0]-c[0])*(b[0]-c[0]) + (b[1]-c[1])*(b[1]-c[1]) + (b[2]-c[2])*(b[2]-c[2])
+		if d < dist {
+			best, dist = i, d
+		}
+	}
+
+	if best < 8 {
+		return strconv.Itoa(30 + best)
+	}
+
+	return strconv.Itoa(90 + best - 8)
+}
@@ -0,0 +1,110 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		style    string
+		depth    ColorDepth
+		expected string
+	}{
+		{"bold #ff8800", TrueColor, "\x1b[1;38;2;255;136;0m"},
+		{"#ff0000", Color256, "\x1b[38;5;196m"},
+		{"#ff0000", Color16, "\x1b[91m"},
+		{"red", Color16, "\x1b[31m"},
+		{"", TrueColor, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := escape(tt.style, tt.depth)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.style, err)
+		}
+
+		if got != tt.expected {
+			t.Errorf("%q: expected %q, but got %q", tt.style, tt.expected, got)
+		}
+	}
+
+	if _, err := escape("#12345", TrueColor); err == nil {
+		t.Errorf("Expected an error for an invalid color")
+	}
+}
+
+func TestStyleFor(t *testing.T) {
+	stops := Themes["default"].Temperature
+
+	if got := styleFor(stops, -20); got != "#7986cb" {
+		t.Errorf("Expected the coldest style, but got %q", got)
+	}
+
+	if got := styleFor(stops, 22.5); got != "#fdd835" {
+		t.Errorf("Expected the style from 20 °C, but got %q", got)
+	}
+
+	if got := styleFor(Themes["default"].Rain, 0); got != "" {
+		t.Errorf("Expected no style without rain, but got %q", got)
+	}
+}
+
+func TestThemesAreValid(t *testing.T) {
+	for name, theme := range Themes {
+		if err := theme.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+		}
+	}
+}
+
+func TestRenderWithColors(t *testing.T) {
+	SetOptions(Options{Colors: TrueColor, Theme: Themes["default"]})
+	defer SetOptions(Options{})
+
+	f := report.Forecast{
+		Days:     []weather.Day{{DayDate: "2023-05-07", TemperatureMax: 31, Precipitation: 12.7}},
+		Warnings: []weather.Warning{{Type: "rain", Level: 3, Text: "Expected amounts: 20-40 mm"}},
+	}
+
+	out := RenderForecast(f, nil)
+
+	expected := []string{
+		"\x1b[38;2;229;57;53m31 °C\x1b[0m",
+		"\x1b[38;2;142;36;170m12.7 mm\x1b[0m",
+		"\x1b[38;2;251;140;0mrain\x1b[0m",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Errorf("Expected output to contain %q, got:\n%s", e, out)
+		}
+	}
+
+	SetOptions(Options{Colors: NoColor, Theme: Themes["default"]})
+
+	if out = RenderForecast(f, nil); strings.Contains(out, "\x1b[") {
+		t.Errorf("Expected no colors, got:\n%s", out)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package prompt keeps the small state file the shell prompt segment is read
+// from, so printing the segment never has to wait for the network.
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/statefile"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+// After this time a refresh is considered dead and another one may start.
+const lockTimeout = time.Minute
+
+// Wait after a failed refresh, doubled with every further failure up to the
+// maximum.
+const (
+	failureBackoff    = time.Minute
+	maxFailureBackoff = time.Hour
+)
+
+// State is the last fetched weather of a zip code.
+type State struct {
+	Zip         string    `json:"zip"`
+	Location    string    `json:"location"`
+	Temperature float64   `json:"temperature"`
+	Icon        int       `json:"icon"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+}
+
+// Builds the state from the weather data.
+func NewState(zip string, location string, w *weather.Weather, now time.Time) *State {
+	return &State{
+		Zip:         zip,
+		Location:    location,
+		Temperature: w.Current.Temperature,
+		Icon:        w.Current.Icon,
+		UpdatedAt:   w.UpdatedAt,
+		FetchedAt:   now,
+	}
+}
+
+// Returns the path of the state file of the zip code in the directory.
+func StatePath(dir string, zip string) string {
+	return filepath.Join(dir, fmt.Sprintf("prompt-%s.json", zip))
+}
+
+// Loads the state file. The error wraps fs.ErrNotExist if there is no state
+// yet.
+func Load(path string) (*State, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state: %w", err)
+	}
+
+	s := &State{}
+
+	err = json.Unmarshal(b, s)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing state %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Saves the state file. The file is replaced atomically, so a prompt never
+// reads a half written file.
+func Save(path string, s *State) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return statefile.Write(path, b)
+}
+
+// Returns whether the state is older than maxAge.
+func (s *State) Stale(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(s.FetchedAt) > maxAge
+}
+
+// Formats the segment. The placeholders {icon}, {temp} and {location} are
+// replaced with the values of the state.
+func (s *State) Format(format string) string {
+	return strings.NewReplacer(
+		"{icon}", swissmeteo.IconSymbol(s.Icon),
+		"{temp}", fmt.Sprintf("%.0f", s.Temperature),
+		"{location}", s.Location,
+	).Replace(format)
+}
+
+// Takes the lock of a refresh and returns whether it succeeded. Locks older
+// than a minute are taken over, so a crashed refresh doesn't block forever.
+func TryLock(statePath string, now time.Time) bool {
+	lock := statePath + ".lock"
+
+	if fi, err := os.Stat(lock); err == nil && now.Sub(fi.ModTime()) > lockTimeout {
+		os.Remove(lock)
+	}
+
+	err := os.MkdirAll(filepath.Dir(lock), 0o700)
+	if err != nil {
+		return false
+	}
+
+	f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false
+	}
+
+	f.Close()
+
+	return true
+}
+
+// Releases the lock of a refresh.
+func Unlock(statePath string) {
+	os.Remove(statePath + ".lock")
+}
+
+// failures are the refreshes that failed in a row.
+type failures struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// Records a failed refresh, so that the next ones back off.
+func RecordFailure(statePath string, now time.Time) error {
+	f := readFailures(statePath)
+	f.Count++
+	f.Last = now
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(statePath+".failed", b, 0o600)
+}
+
+// Forgets the failed refreshes after a successful one.
+func ResetFailures(statePath string) {
+	os.Remove(statePath + ".failed")
+}
+
+// Returns whether a refresh failed so recently that the next one should wait.
+func BackingOff(statePath string, now time.Time) bool {
+	f := readFailures(statePath)
+	if f.Count == 0 {
+		return false
+	}
+
+	backoff := maxFailureBackoff
+	if f.Count <= 6 {
+		backoff = min(failureBackoff<<(f.Count-1), maxFailureBackoff)
+	}
+
+	return now.Sub(f.Last) < backoff
+}
+
+// Returns the failed refreshes, none if the file is missing or broken.
+func readFailures(statePath string) failures {
+	f := failures{}
+
+	if b, err := os.ReadFile(statePath + ".failed"); err == nil {
+		_ = json.Unmarshal(b, &f)
+	}
+
+	return f
+}
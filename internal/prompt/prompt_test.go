@@ -0,0 +1,126 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package prompt
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := StatePath(filepath.Join(t.TempDir(), "sunly"), "3006")
+	now := time.Date(2023, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	_, err := Load(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a missing state, but got %v", err)
+	}
+
+	err = Save(path, &State{Zip: "3006", Location: "Bern", Temperature: 17.4, Icon: 1, FetchedAt: now})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if got := s.Format("{icon} {temp}° {location}"); got != "☀ 17° Bern" {
+		t.Errorf("Expected ☀ 17° Bern, but got %s", got)
+	}
+
+	if s.Stale(now.Add(5*time.Minute), 10*time.Minute) || !s.Stale(now.Add(11*time.Minute), 10*time.Minute) {
+		t.Errorf("Expected the state to get stale after 10 minutes")
+	}
+
+	// Only the state file must be left, no temporary files
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the state file, but got %v", entries)
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := StatePath(t.TempDir(), "3006")
+	now := time.Now()
+
+	if !TryLock(path, now) {
+		t.Fatalf("Expected to get the lock")
+	}
+
+	if TryLock(path, now) {
+		t.Errorf("Expected the lock to be taken")
+	}
+
+	// A lock of a crashed refresh gets taken over
+	if !TryLock(path, now.Add(2*lockTimeout)) {
+		t.Errorf("Expected to take over an old lock")
+	}
+
+	Unlock(path)
+
+	if !TryLock(path, now) {
+		t.Errorf("Expected to get the lock after unlocking")
+	}
+}
+
+func TestBackingOff(t *testing.T) {
+	path := StatePath(t.TempDir(), "3006")
+	now := time.Date(2023, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	if BackingOff(path, now) {
+		t.Errorf("Expected no backoff without failures")
+	}
+
+	if err := RecordFailure(path, now); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !BackingOff(path, now.Add(30*time.Second)) || BackingOff(path, now.Add(time.Minute)) {
+		t.Errorf("Expected a backoff of a minute after the first failure")
+	}
+
+	// The backoff doubles with every failure in a row
+	if err := RecordFailure(path, now); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !BackingOff(path, now.Add(90*time.Second)) || BackingOff(path, now.Add(2*time.Minute)) {
+		t.Errorf("Expected a backoff of two minutes after the second failure")
+	}
+
+	for i := 0; i < 20; i++ {
+		_ = RecordFailure(path, now)
+	}
+
+	if !BackingOff(path, now.Add(59*time.Minute)) || BackingOff(path, now.Add(time.Hour)) {
+		t.Errorf("Expected the backoff to be capped at an hour")
+	}
+
+	ResetFailures(path)
+
+	if BackingOff(path, now) {
+		t.Errorf("Expected no backoff after a successful refresh")
+	}
+}
@@ -0,0 +1,465 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package query finds the time windows in which the forecast hours meet a
+// condition like "temp > 15 and rain == 0 and wind < 20". Only the hourly
+// forecast is queried, finer series like the 10 minute precipitation of
+// MeteoSwiss are summed up into their hours by the providers.
+//
+// A condition compares metrics and numbers with <, <=, >, >=, == and !=, and
+// combines the comparisons with and, or, not and parentheses. &&, || and ! can
+// be used as well.
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/astro"
+	"github.com/darox/sunly/pkg/derived"
+)
+
+// Metrics are the names that can be used in a condition with their meaning.
+var Metrics = map[string]string{
+	"temp":     "mean temperature in °C",
+	"temp_min": "lower band of the temperature in °C",
+	"temp_max": "upper band of the temperature in °C",
+	"feels":    "feels like temperature in °C",
+	"rain":     "precipitation in mm/h",
+	"rain_min": "lower band of the precipitation in mm/h",
+	"rain_max": "upper band of the precipitation in mm/h",
+	"wind":     "wind speed in km/h",
+	"wind_dir": "wind direction in degrees",
+	"humidity": "relative humidity in %",
+	"clouds":   "cloud cover in %",
+	"sun":      "elevation of the sun in degrees",
+	"hour":     "hour of the day from 0 to 23 in Swiss time",
+	"icon":     "code of the weather icon",
+}
+
+// Other names of the metrics.
+var aliases = map[string]string{
+	"temperature":   "temp",
+	"precipitation": "rain",
+}
+
+// Values are the metrics of an hour by name, NaN if unknown.
+type Values map[string]float64
+
+// Returns the values of the metrics of the hour at the coordinates. The hour of
+// the day is the one in the location.
+func HourValues(h weather.Hour, lat float64, lon float64, loc *time.Location) Values {
+	v := Values{
+		"temp":     h.TemperatureMean,
+		"temp_min": h.TemperatureMin,
+		"temp_max": h.TemperatureMax,
+		"feels":    derived.Derive(h.TemperatureMean, h.Humidity, h.WindSpeed).FeelsLike,
+		"rain":     h.Precipitation,
+		"rain_min": h.PrecipitationMin,
+		"rain_max": h.PrecipitationMax,
+		"wind":     h.WindSpeed,
+		"wind_dir": float64(h.WindDirection),
+		"humidity": math.NaN(),
+		"clouds":   math.NaN(),
+		"sun":      astro.SunPosition(h.Time.Add(30*time.Minute), lat, lon).Elevation,
+		"hour":     float64(h.Time.In(loc).Hour()),
+		"icon":     float64(h.Icon),
+	}
+
+	if h.Humidity > 0 {
+		v["humidity"] = h.Humidity
+	}
+
+	if h.CloudCover != nil {
+		v["clouds"] = *h.CloudCover
+	}
+
+	return v
+}
+
+// Query is a parsed condition.
+type Query struct {
+	source string
+	expr   node
+}
+
+// Parses the condition.
+func Parse(condition string) (*Query, error) {
+	tokens, err := tokenize(condition)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+
+	p := &parser{tokens: tokens}
+
+	expr, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+
+	return &Query{source: condition, expr: expr}, nil
+}
+
+// Returns the condition as given.
+func (q *Query) String() string {
+	return q.source
+}
+
+// Returns whether the values meet the condition. Comparisons with unknown
+// values are never met.
+func (q *Query) Match(v Values) bool {
+	return q.expr.eval(v)
+}
+
+// Window is a time span in which the condition is met in every hour.
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Returns the windows between from and to that last at least minDuration, the
+// hours of the day are in the location. The hour that is in progress at from
+// counts as well.
+func (q *Query) Windows(hours []weather.Hour, lat float64, lon float64, loc *time.Location, from time.Time,
+	to time.Time, minDuration time.Duration) []Window {
+	hours = append([]weather.Hour{}, hours...)
+	sort.Slice(hours, func(i, j int) bool { return hours[i].Time.Before(hours[j].Time) })
+
+	windows := []Window{}
+
+	var current *Window
+
+	closeWindow := func() {
+		if current != nil && current.End.Sub(current.Start) >= minDuration {
+			windows = append(windows, *current)
+		}
+
+		current = nil
+	}
+
+	for _, h := range hours {
+		if !h.End().After(from) || !h.Time.Before(to) {
+			continue
+		}
+
+		if !q.Match(HourValues(h, lat, lon, loc)) {
+			closeWindow()
+			continue
+		}
+
+		// Gaps in the forecast end a window
+		if current != nil && !current.End.Equal(h.Time) {
+			closeWindow()
+		}
+
+		if current == nil {
+			current = &Window{Start: h.Time}
+		}
+
+		current.End = h.End()
+	}
+
+	closeWindow()
+
+	return windows
+}
+
+// node is a part of a parsed condition.
+type node interface {
+	eval(v Values) bool
+}
+
+type and struct{ left, right node }
+
+func (n and) eval(v Values) bool { return n.left.eval(v) && n.right.eval(v) }
+
+type or struct{ left, right node }
+
+func (n or) eval(v Values) bool { return n.left.eval(v) || n.right.eval(v) }
+
+type not struct{ operand node }
+
+func (n not) eval(v Values) bool { return !n.operand.eval(v) }
+
+// operand is a metric or a number.
+type operand struct {
+	metric string
+	number float64
+}
+
+func (o operand) value(v Values) float64 {
+	if o.metric == "" {
+		return o.number
+	}
+
+	return v[o.metric]
+}
+
+type comparison struct {
+	left, right operand
+	operator    string
+}
+
+func (n comparison) eval(v Values) bool {
+	a, b := n.left.value(v), n.right.value(v)
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return false
+	}
+
+	switch n.operator {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	}
+
+	return false
+}
+
+// Kinds of tokens.
+const (
+	tokenWord = iota
+	tokenNumber
+	tokenOperator
+	tokenParen
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+// Splits the condition into words, numbers, operators and parentheses.
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, token{tokenParen, string(r)})
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+
+			tokens = append(tokens, token{tokenWord, strings.ToLower(string(runes[i:j]))})
+			i = j
+		case unicode.IsDigit(r) || r == '.' || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+
+			tokens = append(tokens, token{tokenNumber, string(runes[i:j])})
+			i = j
+		case strings.ContainsRune("<>=!&|", r):
+			j := i + 1
+			if j < len(runes) && strings.ContainsRune("=&|", runes[j]) {
+				j++
+			}
+
+			op := string(runes[i:j])
+
+			switch op {
+			case "<", "<=", ">", ">=", "==", "!=", "!":
+			case "=":
+				op = "=="
+			case "&&":
+				op = "and"
+			case "||":
+				op = "or"
+			default:
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+
+			tokens = append(tokens, token{tokenOperator, op})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+// parser is a recursive descent parser of the tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Returns whether the next token is one of the texts and consumes it.
+func (p *parser) accept(texts ...string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+
+	t := p.tokens[p.pos]
+	if t.kind == tokenNumber {
+		return false
+	}
+
+	for _, text := range texts {
+		if t.text == text {
+			p.pos++
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		left = or{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+
+		left = and{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.accept("not", "!") {
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+
+		return not{operand}, nil
+	}
+
+	if p.accept("(") {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+
+		return expr, nil
+	}
+
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator || p.tokens[p.pos].text == "!" {
+		return nil, fmt.Errorf("expected a comparison after %q", p.tokens[p.pos-1].text)
+	}
+
+	operator := p.tokens[p.pos].text
+	p.pos++
+
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison{left: left, right: right, operator: operator}, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	if p.pos >= len(p.tokens) {
+		return operand{}, fmt.Errorf("unexpected end")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid number %q", t.text)
+		}
+
+		return operand{number: n}, nil
+	case tokenWord:
+		name := t.text
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+
+		if _, ok := Metrics[name]; !ok {
+			return operand{}, fmt.Errorf("unknown metric %q", t.text)
+		}
+
+		return operand{metric: name}, nil
+	}
+
+	return operand{}, fmt.Errorf("unexpected %q", t.text)
+}
@@ -0,0 +1,171 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestParse(t *testing.T) {
+	v := Values{"temp": 18, "rain": 0, "wind": 12, "clouds": math.NaN(), "hour": 17}
+
+	tests := []struct {
+		condition string
+		expected  bool
+	}{
+		{"temp > 15 and rain == 0 and wind < 20", true},
+		{"temperature>15&&precipitation=0", true},
+		{"temp > 20 or wind < 15", true},
+		{"temp > 20 or wind > 15", false},
+		{"not (temp > 20 or wind > 15)", true},
+		{"!(rain == 0)", false},
+		{"temp > 15 and (rain > 0 or wind >= 12)", true},
+		{"15 < temp", true},
+		{"wind < temp", true},
+		{"temp >= -5.5", true},
+		{"hour >= 16 and hour <= 18", true},
+		{"TEMP > 15", true},
+		// Unknown values never match
+		{"clouds < 50", false},
+		{"clouds != 50", false},
+	}
+
+	for _, test := range tests {
+		q, err := Parse(test.condition)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", test.condition, err)
+			continue
+		}
+
+		if m := q.Match(v); m != test.expected {
+			t.Errorf("Expected %q to be %v, but got %v", test.condition, test.expected, m)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, condition := range []string{
+		"",
+		"temp",
+		"temp >",
+		"temp > 15 and",
+		"(temp > 15",
+		"temp > 15)",
+		"snow > 1",
+		"temp => 15",
+		"temp > 15 rain == 0",
+		"temp > 1.2.3",
+		"temp # 1",
+	} {
+		if _, err := Parse(condition); err == nil {
+			t.Errorf("Expected an error for %q", condition)
+		}
+	}
+}
+
+func TestHourValues(t *testing.T) {
+	clouds := 75.0
+	h := weather.Hour{
+		Time:            time.Date(2023, 6, 21, 11, 0, 0, 0, time.UTC),
+		TemperatureMean: 20,
+		Precipitation:   0.4,
+		WindSpeed:       10,
+		CloudCover:      &clouds,
+	}
+
+	v := HourValues(h, 46.95, 7.45, time.UTC)
+
+	if v["temp"] != 20 || v["rain"] != 0.4 || v["wind"] != 10 || v["clouds"] != 75 || v["hour"] != 11 {
+		t.Errorf("Unexpected values %v", v)
+	}
+
+	if !math.IsNaN(v["humidity"]) {
+		t.Errorf("Expected an unknown humidity, but got %v", v["humidity"])
+	}
+
+	if v["sun"] < 60 || v["sun"] > 70 {
+		t.Errorf("Expected the sun at about 65° around noon, but got %v", v["sun"])
+	}
+
+	// The hour of the day is the one in the location, not the one of the time
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if v := HourValues(h, 46.95, 7.45, zurich); v["hour"] != 13 {
+		t.Errorf("Expected the hour 13 in Zurich, but got %v", v["hour"])
+	}
+
+	for name := range Metrics {
+		if _, ok := v[name]; !ok {
+			t.Errorf("Expected a value for the metric %q", name)
+		}
+	}
+}
+
+func TestWindows(t *testing.T) {
+	start := time.Date(2023, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	// Dry from 2 to 5, from 8 to 9 and from 12 on, with a gap in the forecast
+	// after 14
+	rain := []float64{1, 1, 0, 0, 0, 1, 1, 1, 0, 1, 1, 1, 0, 0, 0}
+
+	hours := []weather.Hour{}
+
+	for i, r := range rain {
+		hours = append(hours, weather.Hour{Time: start.Add(time.Duration(i) * time.Hour), Precipitation: r})
+	}
+
+	hours = append(hours, weather.Hour{Time: start.Add(16 * time.Hour)}, weather.Hour{Time: start.Add(17 * time.Hour)})
+
+	q, err := Parse("rain == 0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	windows := q.Windows(hours, 46.95, 7.45, time.UTC, start.Add(30*time.Minute), start.Add(24*time.Hour), 2*time.Hour)
+
+	expected := []Window{
+		{start.Add(2 * time.Hour), start.Add(5 * time.Hour)},
+		{start.Add(12 * time.Hour), start.Add(15 * time.Hour)},
+		{start.Add(16 * time.Hour), start.Add(18 * time.Hour)},
+	}
+
+	if len(windows) != len(expected) {
+		t.Fatalf("Expected %d windows, but got %+v", len(expected), windows)
+	}
+
+	for i, w := range windows {
+		if !w.Start.Equal(expected[i].Start) || !w.End.Equal(expected[i].End) {
+			t.Errorf("Expected the window %+v, but got %+v", expected[i], w)
+		}
+	}
+
+	// Only within the range
+	windows = q.Windows(hours, 46.95, 7.45, time.UTC, start.Add(3*time.Hour), start.Add(14*time.Hour), time.Hour)
+	if len(windows) != 3 || !windows[0].Start.Equal(start.Add(3*time.Hour)) ||
+		!windows[2].End.Equal(start.Add(14*time.Hour)) {
+		t.Errorf("Expected the windows to be cut to the range, but got %+v", windows)
+	}
+}
@@ -0,0 +1,330 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package report contains the typed results sunly prints as JSON.
+package report
+
+import (
+	"time"
+
+	"github.com/darox/sunly/internal/activity"
+	"github.com/darox/sunly/internal/ensemble"
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/query"
+	"github.com/darox/sunly/internal/solar"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/astro"
+	"github.com/darox/sunly/pkg/derived"
+	"github.com/darox/sunly/pkg/swissmetnet"
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+// Current is the current temperature of a location.
+type Current struct {
+	Zip         string             `json:"zip"`
+	Location    string             `json:"location"`
+	Temperature float64            `json:"temperature"`
+	Derived     derived.Quantities `json:"derived"`
+	Freshness   weather.Freshness  `json:"freshness"`
+}
+
+// Builds the current temperature report from the weather data. The wind of the
+// derived quantities is the one forecast for the current hour.
+func NewCurrent(zip string, location string, w *weather.Weather, now time.Time, maxAge time.Duration) Current {
+	wind := 0.0
+
+	for _, h := range w.Hours {
+		if !now.Before(h.Time) && now.Before(h.End()) {
+			wind = h.WindSpeed
+			break
+		}
+	}
+
+	return Current{
+		Zip:         zip,
+		Location:    location,
+		Temperature: w.Current.Temperature,
+		Derived:     derived.Derive(w.Current.Temperature, w.Current.Humidity, wind),
+		Freshness:   w.Freshness(now, maxAge),
+	}
+}
+
+// Forecast is the daily forecast of a location.
+type Forecast struct {
+	Zip      string            `json:"zip"`
+	Location string            `json:"location"`
+	Days     []weather.Day     `json:"days"`
+	Warnings []weather.Warning `json:"warnings"`
+}
+
+// Builds the daily forecast report from the weather data.
+func NewForecast(zip string, location string, w *weather.Weather) Forecast {
+	return Forecast{
+		Zip:      zip,
+		Location: location,
+		Days:     w.Days,
+		Warnings: w.Warnings,
+	}
+}
+
+// Hourly is the hourly forecast of a location.
+type Hourly struct {
+	Zip      string `json:"zip"`
+	Location string `json:"location"`
+	Hours    []Hour `json:"hours"`
+}
+
+// Hour is the forecast of an hour with the quantities derived from it.
+type Hour struct {
+	weather.Hour
+	Derived derived.Quantities `json:"derived"`
+}
+
+// Builds the hourly forecast report from the weather data. Hours before now are
+// left out.
+func NewHourly(zip string, location string, w *weather.Weather, now time.Time) Hourly {
+	hours := []Hour{}
+
+	for _, h := range w.HoursFrom(now) {
+		hours = append(hours, Hour{
+			Hour:    h,
+			Derived: derived.Derive(h.TemperatureMean, h.Humidity, h.WindSpeed),
+		})
+	}
+
+	return Hourly{
+		Zip:      zip,
+		Location: location,
+		Hours:    hours,
+	}
+}
+
+// History is the weather recorded for a location.
+type History struct {
+	Zip          string                `json:"zip"`
+	Location     string                `json:"location"`
+	Observations []history.Observation `json:"observations"`
+}
+
+// Builds the history report from the observations, ordered by time.
+func NewHistory(zip string, obs []history.Observation) History {
+	return History{
+		Zip:          zip,
+		Location:     historyLocation(obs),
+		Observations: obs,
+	}
+}
+
+// HistoryDays is the weather recorded for a location aggregated by day.
+type HistoryDays struct {
+	Zip      string        `json:"zip"`
+	Location string        `json:"location"`
+	Days     []history.Day `json:"days"`
+}
+
+// Builds the daily history report from the observations, aggregated by day in
+// the location of loc.
+func NewHistoryDays(zip string, obs []history.Observation, loc *time.Location) HistoryDays {
+	return HistoryDays{
+		Zip:      zip,
+		Location: historyLocation(obs),
+		Days:     history.Daily(obs, loc),
+	}
+}
+
+// Returns the name of the location of the latest observation.
+func historyLocation(obs []history.Observation) string {
+	if len(obs) == 0 {
+		return ""
+	}
+
+	return obs[len(obs)-1].Location
+}
+
+// Ensemble is the consensus forecast of several sources for a location.
+type Ensemble struct {
+	Zip      string          `json:"zip"`
+	Location string          `json:"location"`
+	Sources  []string        `json:"sources"`
+	Days     []ensemble.Day  `json:"days"`
+	Hours    []ensemble.Hour `json:"hours"`
+	// Errors of the sources that failed, by source.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Builds the ensemble report. Hours before now are left out.
+func NewEnsemble(zip string, location string, e ensemble.Ensemble, errs map[string]string, now time.Time) Ensemble {
+	hours := []ensemble.Hour{}
+
+	for _, h := range e.Hours {
+		if h.Time.Add(time.Hour).After(now) {
+			hours = append(hours, h)
+		}
+	}
+
+	return Ensemble{
+		Zip:      zip,
+		Location: location,
+		Sources:  e.Sources,
+		Days:     e.Days,
+		Hours:    hours,
+		Errors:   errs,
+	}
+}
+
+// Station is the latest measurement of the weather station next to a location.
+type Station struct {
+	Zip         string                  `json:"zip"`
+	Location    string                  `json:"location"`
+	Station     swissmetnet.Nearby      `json:"station"`
+	Measurement swissmetnet.Measurement `json:"measurement"`
+}
+
+// Astro are the times of the sun and the moon at a location.
+type Astro struct {
+	Zip       string     `json:"zip"`
+	Location  string     `json:"location"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Days      []AstroDay `json:"days"`
+}
+
+// AstroDay are the times of the sun and the moon of a day.
+type AstroDay struct {
+	Date string          `json:"date"`
+	Sun  astro.SunTimes  `json:"sun"`
+	Moon astro.MoonTimes `json:"moon"`
+}
+
+// Calculates the times of the days from the day of from in its location.
+func NewAstro(zip string, l weather.Location, from time.Time, days int) Astro {
+	a := Astro{Zip: zip, Location: l.Name, Latitude: l.Latitude, Longitude: l.Longitude, Days: []AstroDay{}}
+
+	for i := 0; i < days; i++ {
+		d := from.AddDate(0, 0, i)
+
+		a.Days = append(a.Days, AstroDay{
+			Date: d.Format("2006-01-02"),
+			Sun:  astro.Sun(d, l.Latitude, l.Longitude),
+			Moon: astro.Moon(d, l.Latitude, l.Longitude),
+		})
+	}
+
+	return a
+}
+
+// Solar is the estimated production of a PV system at a location.
+type Solar struct {
+	Zip       string       `json:"zip"`
+	Location  string       `json:"location"`
+	Panel     solar.Panel  `json:"panel"`
+	Days      []solar.Day  `json:"days"`
+	Hours     []solar.Hour `json:"hours"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// Estimates the production of the forecast hours from the current hour on,
+// summed up to days in the location.
+func NewSolar(zip string, l weather.Location, p solar.Panel, w *weather.Weather, now time.Time,
+	loc *time.Location) Solar {
+	estimate := solar.Estimate(p, w.HoursFrom(now), l.Latitude, l.Longitude)
+
+	return Solar{
+		Zip:       zip,
+		Location:  l.Name,
+		Panel:     p,
+		Days:      solar.Daily(estimate, loc),
+		Hours:     estimate,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// Plan are the best times for an activity at a location.
+type Plan struct {
+	Zip      string `json:"zip"`
+	Location string `json:"location"`
+	Activity string `json:"activity"`
+	activity.Plan
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Plans the activity in the forecast hours from the current hour on, with the
+// days in the location.
+func NewPlan(zip string, l weather.Location, name string, p activity.Profile, w *weather.Weather, now time.Time,
+	loc *time.Location) Plan {
+	return Plan{
+		Zip:       zip,
+		Location:  l.Name,
+		Activity:  name,
+		Plan:      p.Plan(w.HoursFrom(now), l.Latitude, l.Longitude, loc),
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// When are the time windows that meet a condition at a location.
+type When struct {
+	Zip       string         `json:"zip"`
+	Location  string         `json:"location"`
+	Condition string         `json:"condition"`
+	Windows   []query.Window `json:"windows"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// Finds the windows of at least minDuration within the given time from now.
+func NewWhen(zip string, l weather.Location, q *query.Query, w *weather.Weather, now time.Time,
+	loc *time.Location, within time.Duration, minDuration time.Duration) When {
+	return When{
+		Zip:       zip,
+		Location:  l.Name,
+		Condition: q.String(),
+		Windows:   q.Windows(w.Hours, l.Latitude, l.Longitude, loc, now, now.Add(within), minDuration),
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// Location is a place known to the Swiss Post.
+type Location struct {
+	Zip       string  `json:"zip"`
+	Name      string  `json:"name"`
+	Canton    string  `json:"canton"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Builds the list of locations from the location data.
+func NewLocations(ld *swisspost.LocationData) []Location {
+	locations := make([]Location, 0, len(ld.Records))
+
+	for _, r := range ld.Records {
+		l := Location{
+			Zip:    r.Fields.Postleitzahl,
+			Name:   r.Fields.Ortbez18,
+			Canton: r.Fields.Kanton,
+		}
+
+		// The geo point is given as latitude and longitude
+		if len(r.Fields.GeoPoint2D) == 2 {
+			l.Latitude = r.Fields.GeoPoint2D[0]
+			l.Longitude = r.Fields.GeoPoint2D[1]
+		}
+
+		locations = append(locations, l)
+	}
+
+	return locations
+}
@@ -0,0 +1,221 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package rules evaluates threshold rules against the weather of a location.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Metrics a rule can check.
+const (
+	MetricTemperature   = "temperature"
+	MetricPrecipitation = "precipitation"
+	MetricWind          = "wind"
+	MetricWarning       = "warning"
+)
+
+// Window that is checked when a rule doesn't set one.
+const DefaultWithin = 24 * time.Hour
+
+// Rule is a condition on the weather of a location, e.g. a temperature below
+// 0 °C in the next 12 hours.
+type Rule struct {
+	Name string `yaml:"name"`
+	// Zip code of the location, the zip code given on the command line is used
+	// when this is empty.
+	Zip string `yaml:"zip"`
+	// One of temperature (°C), precipitation (mm/h), wind (km/h) or warning
+	// (level).
+	Metric   string  `yaml:"metric"`
+	Operator string  `yaml:"operator"`
+	Value    float64 `yaml:"value"`
+	// How far ahead the forecast is checked.
+	Within time.Duration `yaml:"within"`
+}
+
+// Trigger is a rule whose condition is met.
+type Trigger struct {
+	Name      string    `json:"name"`
+	Zip       string    `json:"zip"`
+	Condition string    `json:"condition"`
+	Time      time.Time `json:"time"`
+	Value     float64   `json:"value"`
+}
+
+var operators = map[string]func(a, b float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+// Short names that can be used for the metrics in a condition.
+var metricAliases = map[string]string{
+	"temp":          MetricTemperature,
+	"temperature":   MetricTemperature,
+	"rain":          MetricPrecipitation,
+	"precipitation": MetricPrecipitation,
+	"wind":          MetricWind,
+	"warning":       MetricWarning,
+}
+
+// Matches conditions like "rain_next_1h > 1" or "temperature < 0".
+var conditionRegexp = regexp.MustCompile(`^\s*([a-z]+)(?:_next_([0-9.]+[a-z]+))?\s*(<=|>=|==|!=|<|>)\s*(-?[0-9.]+)\s*$`)
+
+// Parses a condition like "rain_next_1h > 1" into a rule. The window is given
+// with the _next_ suffix and defaults to DefaultWithin.
+func Parse(condition string) (Rule, error) {
+	m := conditionRegexp.FindStringSubmatch(condition)
+	if m == nil {
+		return Rule{}, fmt.Errorf("invalid condition %q, expected e.g. \"rain_next_1h > 1\"", condition)
+	}
+
+	metric, ok := metricAliases[m[1]]
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid condition %q: unknown metric %q", condition, m[1])
+	}
+
+	r := Rule{Name: condition, Metric: metric, Operator: m[3]}
+
+	if m[2] != "" {
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid condition %q: %w", condition, err)
+		}
+
+		r.Within = d
+	}
+
+	v, err := strconv.ParseFloat(m[4], 64)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+
+	r.Value = v
+
+	return r, r.Validate()
+}
+
+// Returns an error if the metric or the operator of the rule is unknown.
+func (r Rule) Validate() error {
+	switch r.Metric {
+	case MetricTemperature, MetricPrecipitation, MetricWind, MetricWarning:
+	default:
+		return fmt.Errorf("rule %q: unknown metric %q", r.Name, r.Metric)
+	}
+
+	if _, ok := operators[r.Operator]; !ok {
+		return fmt.Errorf("rule %q: unknown operator %q", r.Name, r.Operator)
+	}
+
+	if r.Within < 0 {
+		return fmt.Errorf("rule %q: within must not be negative", r.Name)
+	}
+
+	return nil
+}
+
+// Returns the condition in a readable form, e.g. "temperature < 0 within 12h0m0s".
+func (r Rule) String() string {
+	return fmt.Sprintf("%s %s %g within %s", r.Metric, r.Operator, r.Value, r.within())
+}
+
+func (r Rule) within() time.Duration {
+	if r.Within == 0 {
+		return DefaultWithin
+	}
+
+	return r.Within
+}
+
+// Evaluates the rule against the weather at the given time. It returns the
+// first hour or warning that meets the condition, or nil if none does.
+func (r Rule) Evaluate(zip string, w *weather.Weather, now time.Time) *Trigger {
+	op := operators[r.Operator]
+	if op == nil {
+		return nil
+	}
+
+	end := now.Add(r.within())
+
+	if r.Metric == MetricWarning {
+		for _, wa := range w.Warnings {
+			from := wa.ValidFrom
+
+			// Warnings without an end stay valid
+			if !from.Before(end) || (!wa.ValidTo.IsZero() && !wa.ValidTo.After(now)) {
+				continue
+			}
+
+			if op(float64(wa.Level), r.Value) {
+				if from.Before(now) {
+					from = now
+				}
+
+				return r.trigger(zip, from, float64(wa.Level))
+			}
+		}
+
+		return nil
+	}
+
+	// The hour that is in progress counts as well
+	for _, h := range w.HoursFrom(now) {
+		if !h.Time.Before(end) {
+			continue
+		}
+
+		var v float64
+
+		switch r.Metric {
+		case MetricTemperature:
+			v = h.TemperatureMean
+		case MetricPrecipitation:
+			v = h.Precipitation
+		case MetricWind:
+			v = h.WindSpeed
+		default:
+			return nil
+		}
+
+		if op(v, r.Value) {
+			return r.trigger(zip, h.Time, v)
+		}
+	}
+
+	return nil
+}
+
+func (r Rule) trigger(zip string, t time.Time, v float64) *Trigger {
+	return &Trigger{
+		Name:      r.Name,
+		Zip:       zip,
+		Condition: r.String(),
+		Time:      t,
+		Value:     v,
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+func testWeather() *weather.Weather {
+	start := time.UnixMilli(1683410400000)
+	temperatures := []float64{4.1, 2.7, 0.3, -1.6, -2.0, 1.0}
+	precipitation := []float64{0, 0.4, 2.5, 0, 0, 0}
+	wind := []float64{20, 20, 20, 45, 45, 45}
+
+	w := &weather.Weather{}
+
+	for i := range temperatures {
+		w.Hours = append(w.Hours, weather.Hour{
+			Time:            start.Add(time.Duration(i) * time.Hour),
+			TemperatureMean: temperatures[i],
+			Precipitation:   precipitation[i],
+			WindSpeed:       wind[i],
+		})
+	}
+
+	w.Warnings = []weather.Warning{{Type: "wind", Level: 3, ValidFrom: start.Add(4 * time.Hour)}}
+
+	return w
+}
+
+func TestEvaluate(t *testing.T) {
+	w := testWeather()
+	start := w.Hours[0].Time
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		now      time.Time
+		expected float64
+		hour     int
+	}{
+		{"frost", Rule{Metric: MetricTemperature, Operator: "<", Value: 0}, start, -1.6, 3},
+		{"rain", Rule{Metric: MetricPrecipitation, Operator: ">", Value: 2, Within: time.Hour}, start.Add(90 * time.Minute), 2.5, 2},
+		{"wind", Rule{Metric: MetricWind, Operator: ">", Value: 40}, start, 45, 3},
+		{"warning", Rule{Metric: MetricWarning, Operator: ">=", Value: 3}, start, 3, 4},
+	}
+
+	for _, tt := range tests {
+		trigger := tt.rule.Evaluate("3006", w, tt.now)
+		if trigger == nil {
+			t.Errorf("%s: expected the rule to trigger", tt.name)
+			continue
+		}
+
+		if trigger.Value != tt.expected || !trigger.Time.Equal(start.Add(time.Duration(tt.hour)*time.Hour)) {
+			t.Errorf("%s: expected %.1f at hour %d, but got %.1f at %s", tt.name, tt.expected, tt.hour, trigger.Value, trigger.Time)
+		}
+	}
+}
+
+func TestEvaluateOutsideWindow(t *testing.T) {
+	w := testWeather()
+	start := w.Hours[0].Time
+
+	r := Rule{Metric: MetricTemperature, Operator: "<", Value: 0, Within: 2 * time.Hour}
+	if trigger := r.Evaluate("3006", w, start); trigger != nil {
+		t.Errorf("Expected no trigger within 2h, but got %+v", trigger)
+	}
+
+	r = Rule{Metric: MetricWarning, Operator: ">=", Value: 3, Within: 3 * time.Hour}
+	if trigger := r.Evaluate("3006", w, start); trigger != nil {
+		t.Errorf("Expected the warning to start after the window, but got %+v", trigger)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Rule{Metric: "humidity", Operator: "<"}).Validate(); err == nil {
+		t.Errorf("Expected an error for an unknown metric")
+	}
+
+	if err := (Rule{Metric: MetricWind, Operator: "=>"}).Validate(); err == nil {
+		t.Errorf("Expected an error for an unknown operator")
+	}
+
+	if err := (Rule{Metric: MetricWind, Operator: ">"}).Validate(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse("rain_next_1h > 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if r.Metric != MetricPrecipitation || r.Operator != ">" || r.Value != 1 || r.Within != time.Hour {
+		t.Errorf("Unexpected rule %+v", r)
+	}
+
+	r, err = Parse("temperature<=-2.5")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if r.Metric != MetricTemperature || r.Operator != "<=" || r.Value != -2.5 || r.Within != 0 {
+		t.Errorf("Unexpected rule %+v", r)
+	}
+
+	for _, c := range []string{"humidity > 80", "rain_next_soon > 1", "wind >> 4", "wind > fast"} {
+		if _, err := Parse(c); err == nil {
+			t.Errorf("Expected an error for %q", c)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Most entries the cache keeps, the ones expiring first are dropped beyond.
+const maxEntries = 1000
+
+// cache keeps fetched values in memory for a while and makes sure concurrent
+// requests for the same key only hit the upstream once.
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]entry
+	calls   map[string]*call
+}
+
+type entry struct {
+	value   any
+	expires time.Time
+}
+
+// call is a fetch in flight that other requests can wait for.
+type call struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]entry{},
+		calls:   map[string]*call{},
+	}
+}
+
+// Returns the cached value for the key or fetches it. Errors are not cached.
+func (c *cache) get(ctx context.Context, key string, fetch func(ctx context.Context) (any, error)) (any, error) {
+	c.mu.Lock()
+
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		c.mu.Unlock()
+		return e.value, nil
+	}
+
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[key] = cl
+
+		// The fetch is shared, so it must not be cancelled when the request
+		// that started it goes away
+		go c.fetch(key, cl, fetch)
+	}
+
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *cache) fetch(key string, cl *call, fetch func(ctx context.Context) (any, error)) {
+	cl.value, cl.err = fetch(context.Background())
+
+	c.mu.Lock()
+
+	if cl.err == nil && c.ttl > 0 {
+		c.prune()
+		c.entries[key] = entry{value: cl.value, expires: c.now().Add(c.ttl)}
+	}
+
+	delete(c.calls, key)
+
+	c.mu.Unlock()
+
+	close(cl.done)
+}
+
+// Drops the expired entries and, if the cache is still full, the ones that
+// expire first. The lock must be held.
+func (c *cache) prune() {
+	now := c.now()
+
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	for len(c.entries) >= maxEntries {
+		oldest := ""
+
+		for key, e := range c.entries {
+			if oldest == "" || e.expires.Before(c.entries[oldest].expires) {
+				oldest = key
+			}
+		}
+
+		delete(c.entries, oldest)
+	}
+}
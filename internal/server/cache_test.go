@@ -0,0 +1,73 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCachePrune(t *testing.T) {
+	now := time.Date(2023, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	c := newCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	fetch := func(v string) func(ctx context.Context) (any, error) {
+		return func(ctx context.Context) (any, error) { return v, nil }
+	}
+
+	if _, err := c.get(context.Background(), "old", fetch("old")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// Expired entries are dropped when the next one is stored
+	now = now.Add(2 * time.Minute)
+
+	if _, err := c.get(context.Background(), "new", fetch("new")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, ok := c.entries["old"]; ok || len(c.entries) != 1 {
+		t.Errorf("Expected the expired entry to be dropped, but got %v", c.entries)
+	}
+
+	// The size is bounded
+	for i := 0; i < maxEntries+10; i++ {
+		now = now.Add(time.Millisecond)
+
+		if _, err := c.get(context.Background(), fmt.Sprint(i), fetch("v")); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if len(c.entries) != maxEntries {
+		t.Errorf("Expected %d entries, but got %d", maxEntries, len(c.entries))
+	}
+
+	if _, ok := c.entries[fmt.Sprint(maxEntries+9)]; !ok {
+		t.Errorf("Expected the newest entry to be kept")
+	}
+
+	if _, ok := c.entries["new"]; ok {
+		t.Errorf("Expected the entry expiring first to be dropped")
+	}
+}
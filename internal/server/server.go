@@ -0,0 +1,253 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package server exposes the weather and location data over a small REST API.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+// How long the server waits for running requests when shutting down.
+const shutdownTimeout = 10 * time.Second
+
+var (
+	zipPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+	errUnknownZip = errors.New("unknown zip code")
+)
+
+// Server answers API requests from a cached source.
+type Server struct {
+	source Source
+	cache  *cache
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// Creates a server that caches the data of the source for the given time.
+func New(source Source, ttl time.Duration, maxAge time.Duration) *Server {
+	return &Server{
+		source: source,
+		cache:  newCache(ttl),
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+}
+
+// Returns the HTTP handler with all routes of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/v1/current/", s.handleZip("/v1/current/", s.current))
+	mux.HandleFunc("/v1/forecast/", s.handleZip("/v1/forecast/", s.forecast))
+	mux.HandleFunc("/v1/hourly/", s.handleZip("/v1/hourly/", s.hourly))
+	mux.HandleFunc("/v1/locations", s.handleLocations)
+
+	return mux
+}
+
+// Serves the API on the given address until the context is done and then
+// shuts down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	return Serve(ctx, addr, s.Handler())
+}
+
+// Serves the handler on the given address until the context is done and
+// then waits for running requests before returning.
+func Serve(ctx context.Context, addr string, h http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// Wraps a handler that works on the zip code at the end of the path.
+func (s *Server) handleZip(prefix string, h func(ctx context.Context, zip string) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		zip := strings.TrimPrefix(r.URL.Path, prefix)
+		if !zipPattern.MatchString(zip) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid zip code %q", zip))
+			return
+		}
+
+		v, err := h(r.Context(), zip)
+		if err != nil {
+			writeUpstreamError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func (s *Server) handleLocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing query parameter q"))
+		return
+	}
+
+	ld, err := s.locations(r.Context(), q)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report.NewLocations(ld))
+}
+
+func (s *Server) current(ctx context.Context, zip string) (any, error) {
+	w, name, err := s.lookup(ctx, zip)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.NewCurrent(zip, name, w, s.now(), s.maxAge), nil
+}
+
+func (s *Server) forecast(ctx context.Context, zip string) (any, error) {
+	w, name, err := s.lookup(ctx, zip)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.NewForecast(zip, name, w), nil
+}
+
+func (s *Server) hourly(ctx context.Context, zip string) (any, error) {
+	w, name, err := s.lookup(ctx, zip)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.NewHourly(zip, name, w, s.now()), nil
+}
+
+// Returns the weather and the name of the location for a zip code.
+func (s *Server) lookup(ctx context.Context, zip string) (*weather.Weather, string, error) {
+	ld, err := s.locations(ctx, zip)
+	if err != nil {
+		return nil, "", err
+	}
+
+	l, ok := weather.LocationOf(ld, zip)
+	if !ok {
+		return nil, "", errUnknownZip
+	}
+
+	v, err := s.cache.get(ctx, "weather:"+zip, func(ctx context.Context) (any, error) {
+		return s.source.Weather(ctx, l)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	w, ok := v.(*weather.Weather)
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected cached value %T", v)
+	}
+
+	return w, l.Name, nil
+}
+
+func (s *Server) locations(ctx context.Context, q string) (*swisspost.LocationData, error) {
+	v, err := s.cache.get(ctx, "locations:"+q, func(ctx context.Context) (any, error) {
+		return s.source.Locations(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ld, ok := v.(*swisspost.LocationData)
+	if !ok {
+		return nil, fmt.Errorf("unexpected cached value %T", v)
+	}
+
+	return ld, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	// The status is already sent, so there is nothing left to do on errors
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errUnknownZip):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, err)
+	default:
+		writeError(w, http.StatusBadGateway, err)
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/report"
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/internal/weather/meteoswiss"
+	"github.com/darox/sunly/pkg/swissmeteo"
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+type fakeSource struct {
+	weatherCalls  int32
+	locationCalls int32
+	// Closed to let pending weather requests finish
+	release chan struct{}
+	err     error
+}
+
+func (f *fakeSource) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	atomic.AddInt32(&f.weatherCalls, 1)
+
+	if f.release != nil {
+		<-f.release
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	s := &swissmeteo.Weather{}
+	s.CurrentWeather.Time = time.Now().UnixMilli()
+	s.CurrentWeather.Temperature = 17
+	s.Forecast = []swissmeteo.Day{{DayDate: "2023-05-07", TemperatureMax: 18, TemperatureMin: 11}}
+	s.Graph.Start = time.Now().Truncate(time.Hour).UnixMilli()
+	s.Graph.StartLowResolution = s.Graph.Start
+	s.Graph.TemperatureMean1H = []float64{14.1, 13.7}
+	s.Graph.Precipitation1H = []float64{1.3, 0}
+
+	w := meteoswiss.Convert(s)
+
+	return w, nil
+}
+
+func (f *fakeSource) Locations(ctx context.Context, query string) (*swisspost.LocationData, error) {
+	atomic.AddInt32(&f.locationCalls, 1)
+
+	ld := &swisspost.LocationData{}
+
+	if query == "9999" {
+		return ld, nil
+	}
+
+	err := json.Unmarshal([]byte(`{
+		"nhits": 1,
+		"records": [{"fields": {"postleitzahl": "3006", "ortbez18": "Bern", "kanton": "BE", "geo_point_2d": [46.94, 7.47]}}]
+	}`), ld)
+
+	return ld, err
+}
+
+func get(t *testing.T, h http.Handler, path string, v any) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if v != nil && rec.Code == http.StatusOK {
+		err := json.NewDecoder(rec.Body).Decode(v)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding %s: %s", path, err)
+		}
+	}
+
+	return rec.Code
+}
+
+func TestCurrent(t *testing.T) {
+	src := &fakeSource{}
+	h := New(src, time.Minute, time.Hour).Handler()
+
+	var c report.Current
+	if code := get(t, h, "/v1/current/3006", &c); code != http.StatusOK {
+		t.Fatalf("Expected status 200, but got %d", code)
+	}
+
+	if c.Location != "Bern" || c.Temperature != 17 {
+		t.Errorf("Expected 17 °C in Bern, but got %.1f °C in %s", c.Temperature, c.Location)
+	}
+
+	if c.Derived.FeelsLike != 17 || c.Derived.WindChill != nil {
+		t.Errorf("Expected to feel like 17 °C, but got %+v", c.Derived)
+	}
+
+	if c.Freshness.Stale {
+		t.Errorf("Expected fresh data")
+	}
+
+	// The second request must be answered from the cache
+	get(t, h, "/v1/current/3006", &c)
+
+	if src.weatherCalls != 1 || src.locationCalls != 1 {
+		t.Errorf("Expected one upstream call each, but got %d and %d", src.weatherCalls, src.locationCalls)
+	}
+}
+
+func TestForecastAndHourly(t *testing.T) {
+	h := New(&fakeSource{}, time.Minute, time.Hour).Handler()
+
+	var f report.Forecast
+	if code := get(t, h, "/v1/forecast/3006", &f); code != http.StatusOK {
+		t.Fatalf("Expected status 200, but got %d", code)
+	}
+
+	if len(f.Days) != 1 || f.Days[0].TemperatureMax != 18 {
+		t.Errorf("Unexpected forecast %+v", f.Days)
+	}
+
+	var hr report.Hourly
+	if code := get(t, h, "/v1/hourly/3006", &hr); code != http.StatusOK {
+		t.Fatalf("Expected status 200, but got %d", code)
+	}
+
+	if len(hr.Hours) != 2 || hr.Hours[0].Precipitation != 1.3 {
+		t.Errorf("Unexpected hours %+v", hr.Hours)
+	}
+
+	if hr.Hours[0].Derived.FeelsLike != 14.1 {
+		t.Errorf("Expected the derived quantities of the hours, but got %+v", hr.Hours[0].Derived)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	h := New(&fakeSource{}, time.Minute, time.Hour).Handler()
+
+	var l []report.Location
+	if code := get(t, h, "/v1/locations?q=Bern", &l); code != http.StatusOK {
+		t.Fatalf("Expected status 200, but got %d", code)
+	}
+
+	if len(l) != 1 || l[0].Zip != "3006" || l[0].Canton != "BE" || l[0].Latitude != 46.94 {
+		t.Errorf("Unexpected locations %+v", l)
+	}
+
+	if code := get(t, h, "/v1/locations", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a query, but got %d", code)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	h := New(&fakeSource{err: errors.New("boom")}, time.Minute, time.Hour).Handler()
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/v1/current/abc", http.StatusBadRequest},
+		{"/v1/current/9999", http.StatusNotFound},
+		{"/v1/current/3006", http.StatusBadGateway},
+		{"/healthz", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		if code := get(t, h, tt.path, nil); code != tt.code {
+			t.Errorf("%s: expected status %d, but got %d", tt.path, tt.code, code)
+		}
+	}
+}
+
+func TestCoalescing(t *testing.T) {
+	src := &fakeSource{release: make(chan struct{})}
+	h := New(src, time.Minute, time.Hour).Handler()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			get(t, h, "/v1/current/3006", nil)
+		}()
+	}
+
+	// Give all requests the time to queue up behind the first one
+	time.Sleep(50 * time.Millisecond)
+	close(src.release)
+	wg.Wait()
+
+	if src.weatherCalls != 1 {
+		t.Errorf("Expected one upstream call, but got %d", src.weatherCalls)
+	}
+}
+
+func TestListenAndServeShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- New(&fakeSource{}, time.Minute, time.Hour).ListenAndServe(ctx, "127.0.0.1:0")
+	}()
+
+	cancel()
+
+	select {
+	case err := <-errs:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Server did not shut down")
+	}
+}
+
+func TestServeWaitsForRequests(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	addr := l.Addr().String()
+	l.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- Serve(ctx, addr, h)
+	}()
+
+	codes := make(chan int, 1)
+
+	go func() {
+		for {
+			resp, err := http.Get("http://" + addr)
+			if err != nil {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+
+			resp.Body.Close()
+			codes <- resp.StatusCode
+
+			return
+		}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-errs:
+		t.Fatalf("Expected the server to wait for the request, but it returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	if code := <-codes; code != http.StatusNoContent {
+		t.Errorf("Expected status %d, but got %d", http.StatusNoContent, code)
+	}
+
+	if err := <-errs; err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package server
+
+import (
+	"context"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+// Source fetches the data the server exposes.
+type Source interface {
+	Weather(ctx context.Context, l weather.Location) (*weather.Weather, error)
+	Locations(ctx context.Context, query string) (*swisspost.LocationData, error)
+}
+
+// Upstream fetches the weather from a provider and the locations directly from
+// the Swiss Post.
+type Upstream struct {
+	Provider weather.Provider
+}
+
+func (u Upstream) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	return u.Provider.Weather(ctx, l)
+}
+
+func (Upstream) Locations(ctx context.Context, query string) (*swisspost.LocationData, error) {
+	ld := &swisspost.LocationData{}
+
+	err := ld.GetLocationDataByNameContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return ld, nil
+}
@@ -0,0 +1,243 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package solar estimates the production of a PV system from the position of
+// the sun and the forecast cloudiness.
+//
+// The clear sky irradiance of Haurwitz is reduced by the cloud cover with the
+// formula of Kasten and Czeplak, unless the forecast has the radiation. It is
+// split into direct and diffuse light with the correlation of Erbs and
+// projected onto the panels. It is meant to plan around sunny hours, not to
+// bill kilowatt hours.
+package solar
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/astro"
+)
+
+const (
+	// Irradiance outside the atmosphere in W/m².
+	solarConstant = 1367.0
+	// Irradiance of the standard test conditions the peak power refers to.
+	standardIrradiance = 1000.0
+	// Losses of inverter, wiring and dirt.
+	performanceRatio = 0.86
+	// Loss of power per °C the cells are warmer than 25 °C.
+	temperatureCoefficient = -0.004
+	// Warming of the cells per W/m² of irradiance.
+	cellHeating = 0.03
+	// Share of the light the ground reflects.
+	albedo = 0.2
+	// Cloud cover assumed when the forecast has none.
+	unknownCloudCover = 0.5
+)
+
+// Panel is the PV system.
+type Panel struct {
+	// Peak power in kW.
+	KWp float64 `json:"kwp"`
+	// Degrees from horizontal.
+	Tilt float64 `json:"tilt"`
+	// Degrees clockwise from north the panels face, 180 is south.
+	Azimuth float64 `json:"azimuth"`
+}
+
+// Validates the panel.
+func (p Panel) Validate() error {
+	switch {
+	case p.KWp <= 0:
+		return errors.New("the peak power must be positive")
+	case p.Tilt < 0 || p.Tilt > 90:
+		return errors.New("the tilt must be between 0 and 90 degrees")
+	case p.Azimuth < 0 || p.Azimuth >= 360:
+		return errors.New("the azimuth must be between 0 and 360 degrees")
+	}
+
+	return nil
+}
+
+// Hour is the estimate of an hour.
+type Hour struct {
+	Time time.Time `json:"time"`
+	// Elevation of the sun in the middle of the hour in degrees.
+	Elevation float64 `json:"elevation"`
+	// Cloud cover used from 0 to 1.
+	CloudCover float64 `json:"cloudCover"`
+	// Irradiance on the panels in W/m².
+	Irradiance float64 `json:"irradiance"`
+	// Production in kWh, with clouds and under a clear sky.
+	Energy         float64 `json:"energy"`
+	ClearSkyEnergy float64 `json:"clearSkyEnergy"`
+}
+
+// Day is the estimate of a day.
+type Day struct {
+	Date           string  `json:"date"`
+	Energy         float64 `json:"energy"`
+	ClearSkyEnergy float64 `json:"clearSkyEnergy"`
+	// Hour with the highest production, zero if there is none.
+	Peak time.Time `json:"peak"`
+}
+
+// Estimates the production of the forecast hours at the coordinates.
+func Estimate(p Panel, hours []weather.Hour, lat float64, lon float64) []Hour {
+	result := make([]Hour, 0, len(hours))
+
+	for _, h := range hours {
+		mid := h.Time.Add(30 * time.Minute)
+		pos := astro.SunPosition(mid, lat, lon)
+
+		e := Hour{Time: h.Time, Elevation: pos.Elevation, CloudCover: cloudCover(h)}
+
+		if pos.Elevation > 0 {
+			clear := p.irradiance(pos, ClearSky(pos.Elevation))
+
+			// A forecast of the radiation beats the estimate from the clouds
+			ghi := Cloudy(ClearSky(pos.Elevation), e.CloudCover)
+			if h.Radiation != nil {
+				ghi = *h.Radiation
+			}
+
+			cloudy := p.irradiance(pos, ghi)
+
+			e.Irradiance = round(cloudy)
+			e.Energy = p.energy(cloudy, h.TemperatureMean)
+			e.ClearSkyEnergy = p.energy(clear, h.TemperatureMean)
+		}
+
+		result = append(result, e)
+	}
+
+	return result
+}
+
+// Sums the hours up to days in the location.
+func Daily(hours []Hour, loc *time.Location) []Day {
+	days := []Day{}
+
+	peak := 0.0
+
+	for _, h := range hours {
+		date := h.Time.In(loc).Format("2006-01-02")
+
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, Day{Date: date})
+			peak = 0
+		}
+
+		d := &days[len(days)-1]
+		d.Energy += h.Energy
+		d.ClearSkyEnergy += h.ClearSkyEnergy
+
+		if h.Energy > peak {
+			peak = h.Energy
+			d.Peak = h.Time
+		}
+	}
+
+	for i := range days {
+		days[i].Energy = round(days[i].Energy)
+		days[i].ClearSkyEnergy = round(days[i].ClearSkyEnergy)
+	}
+
+	return days
+}
+
+// Returns the global horizontal irradiance under a clear sky of Haurwitz in
+// W/m² for the elevation of the sun in degrees.
+func ClearSky(elevation float64) float64 {
+	if elevation <= 0 {
+		return 0
+	}
+
+	cz := math.Sin(elevation * math.Pi / 180)
+
+	return 1098 * cz * math.Exp(-0.057/cz)
+}
+
+// Returns the global horizontal irradiance reduced by the cloud cover from 0
+// to 1, after Kasten and Czeplak.
+func Cloudy(ghi float64, cloudCover float64) float64 {
+	return ghi * (1 - 0.75*math.Pow(cloudCover, 3.4))
+}
+
+// Returns the cloud cover of the hour from 0 to 1.
+func cloudCover(h weather.Hour) float64 {
+	if h.CloudCover == nil {
+		return unknownCloudCover
+	}
+
+	return math.Max(0, math.Min(1, *h.CloudCover/100))
+}
+
+// Returns the irradiance on the panels in W/m² from the global horizontal
+// irradiance.
+func (p Panel) irradiance(pos astro.Position, ghi float64) float64 {
+	if ghi <= 0 {
+		return 0
+	}
+
+	rad := math.Pi / 180
+	cz := math.Sin(pos.Elevation * rad)
+
+	// Split into diffuse and direct light with the clearness index
+	kt := math.Min(ghi/(solarConstant*cz), 1)
+
+	var kd float64
+
+	switch {
+	case kt <= 0.22:
+		kd = 1 - 0.09*kt
+	case kt <= 0.8:
+		kd = 0.9511 - 0.1604*kt + 4.388*kt*kt - 16.638*math.Pow(kt, 3) + 12.336*math.Pow(kt, 4)
+	default:
+		kd = 0.165
+	}
+
+	dhi := kd * ghi
+	dni := (ghi - dhi) / cz
+
+	// Angle of incidence of the direct light on the panels
+	zenith := (90 - pos.Elevation) * rad
+	tilt := p.Tilt * rad
+	cosAOI := math.Cos(zenith)*math.Cos(tilt) + math.Sin(zenith)*math.Sin(tilt)*math.Cos((pos.Azimuth-p.Azimuth)*rad)
+
+	direct := dni * math.Max(cosAOI, 0)
+	diffuse := dhi * (1 + math.Cos(tilt)) / 2
+	reflected := ghi * albedo * (1 - math.Cos(tilt)) / 2
+
+	return direct + diffuse + reflected
+}
+
+// Returns the energy in kWh of an hour at the irradiance and air temperature.
+func (p Panel) energy(irradiance float64, temperature float64) float64 {
+	cell := temperature + cellHeating*irradiance
+	derate := 1 + temperatureCoefficient*(cell-25)
+
+	return round(math.Max(0, p.KWp*irradiance/standardIrradiance*performanceRatio*derate))
+}
+
+// Rounds to two decimals.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,158 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Bern
+const (
+	latitude  = 46.95
+	longitude = 7.45
+)
+
+func TestClearSky(t *testing.T) {
+	if v := ClearSky(-1); v != 0 {
+		t.Errorf("Expected no irradiance at night, but got %v", v)
+	}
+
+	// Sun in the zenith
+	if v := ClearSky(90); math.Abs(v-1037.2) > 0.1 {
+		t.Errorf("Expected 1037.2 W/m² with the sun in the zenith, but got %v", v)
+	}
+
+	if ClearSky(30) >= ClearSky(60) {
+		t.Errorf("Expected more irradiance with the sun higher up")
+	}
+}
+
+func TestCloudy(t *testing.T) {
+	if v := Cloudy(800, 0); v != 800 {
+		t.Errorf("Expected no reduction without clouds, but got %v", v)
+	}
+
+	if v := Cloudy(800, 1); v != 200 {
+		t.Errorf("Expected a quarter when overcast, but got %v", v)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, p := range []Panel{{KWp: 0}, {KWp: 5, Tilt: 95}, {KWp: 5, Azimuth: 360}} {
+		if p.Validate() == nil {
+			t.Errorf("Expected %+v to be invalid", p)
+		}
+	}
+
+	if err := (Panel{KWp: 5, Tilt: 30, Azimuth: 180}).Validate(); err != nil {
+		t.Errorf("Expected a valid panel, but got %v", err)
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Zurich")
+
+	clear, overcast := 0.0, 100.0
+
+	hours := []weather.Hour{}
+
+	for i := 0; i < 24; i++ {
+		hours = append(hours, weather.Hour{
+			Time:            time.Date(2023, 6, 21, i, 0, 0, 0, loc),
+			TemperatureMean: 20,
+			CloudCover:      &clear,
+		})
+	}
+
+	south := Panel{KWp: 10, Tilt: 30, Azimuth: 180}
+	result := Estimate(south, hours, latitude, longitude)
+
+	if result[0].Energy != 0 || result[23].Energy != 0 {
+		t.Errorf("Expected no production at night, but got %v and %v", result[0].Energy, result[23].Energy)
+	}
+
+	if result[13].Energy <= result[8].Energy || result[13].Energy <= result[18].Energy {
+		t.Errorf("Expected the most production around noon, but got %+v", result)
+	}
+
+	if result[13].Energy != result[13].ClearSkyEnergy {
+		t.Errorf("Expected the clear sky production without clouds, but got %+v", result[13])
+	}
+
+	days := Daily(result, loc)
+	if len(days) != 1 {
+		t.Fatalf("Expected one day, but got %+v", days)
+	}
+
+	// A 10 kWp system makes between 60 and 80 kWh on a clear summer day
+	if days[0].Energy < 60 || days[0].Energy > 80 {
+		t.Errorf("Expected between 60 and 80 kWh, but got %v", days[0].Energy)
+	}
+
+	if days[0].Peak.Hour() != 13 {
+		t.Errorf("Expected the best hour at 13:00, but got %v", days[0].Peak)
+	}
+
+	// Panels facing west make more in the evening
+	west := Estimate(Panel{KWp: 10, Tilt: 30, Azimuth: 270}, hours, latitude, longitude)
+	if west[18].Energy <= result[18].Energy {
+		t.Errorf("Expected more production of the west panels in the evening, but got %v and %v",
+			west[18].Energy, result[18].Energy)
+	}
+
+	for i := range hours {
+		hours[i].CloudCover = &overcast
+	}
+
+	cloudy := Daily(Estimate(south, hours, latitude, longitude), loc)
+	if cloudy[0].Energy >= days[0].Energy/2 || cloudy[0].ClearSkyEnergy != days[0].ClearSkyEnergy {
+		t.Errorf("Expected much less production when overcast, but got %+v", cloudy[0])
+	}
+
+	// The radiation of the forecast is used instead of the clouds
+	radiation := ClearSky(result[13].Elevation)
+	hours[13].Radiation = &radiation
+
+	r := Estimate(south, hours, latitude, longitude)[13]
+	if r.Energy != r.ClearSkyEnergy {
+		t.Errorf("Expected the clear sky production with its radiation despite the clouds, but got %+v", r)
+	}
+}
+
+func TestCloudCover(t *testing.T) {
+	cover := 40.0
+
+	tests := []struct {
+		hour     weather.Hour
+		expected float64
+	}{
+		{weather.Hour{CloudCover: &cover}, 0.4},
+		{weather.Hour{}, unknownCloudCover},
+	}
+
+	for _, test := range tests {
+		if v := cloudCover(test.hour); v != test.expected {
+			t.Errorf("Expected a cloud cover of %v for %+v, but got %v", test.expected, test.hour, v)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package statefile
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func lock(f *os.File) error {
+	return setLock(f, unix.F_WRLCK)
+}
+
+func unlock(f *os.File) error {
+	return setLock(f, unix.F_UNLCK)
+}
+
+// Sets a lock on the whole file, waiting until it can be taken.
+func setLock(f *os.File, typ int16) error {
+	l := unix.Flock_t{Type: typ}
+
+	for {
+		err := unix.FcntlFlock(f.Fd(), unix.F_SETLKW, &l)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package statefile writes the small state files that several sunly processes
+// share, e.g. a cron job and the daemon.
+package statefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Writes the data to a temporary file next to path and renames it, so readers
+// never see a partly written file. Missing directories are created.
+func Write(path string, b []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(b)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Takes an exclusive lock on path, waiting while another process holds it. The
+// lock is taken on a separate file next to path, as the file itself is
+// replaced by Write. The returned function releases the lock.
+func Lock(path string) (func(), error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the lock of %s: %w", path, err)
+	}
+
+	err = lock(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error locking %s: %w", path, err)
+	}
+
+	return func() {
+		// Closing the file releases the lock as well
+		_ = unlock(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,116 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package statefile
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "sent.json")
+
+	for _, s := range []string{"first", "second"} {
+		err := Write(path, []byte(s))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if string(b) != s {
+			t.Errorf("Expected %q, but got %q", s, b)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, but got %d files", len(entries))
+	}
+}
+
+// Takes the lock of the path in the environment when run as helper process.
+func TestLockHelper(t *testing.T) {
+	path := os.Getenv("SUNLY_LOCK_PATH")
+	if path == "" {
+		t.Skip("only run as helper process")
+	}
+
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	unlock()
+}
+
+func TestLockExcludesOtherProcesses(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		t.Skip("file locks are not tested on " + runtime.GOOS)
+	}
+
+	path := filepath.Join(t.TempDir(), "sent.json")
+
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	c := exec.Command(os.Args[0], "-test.run=^TestLockHelper$")
+	c.Env = append(os.Environ(), "SUNLY_LOCK_PATH="+path)
+
+	err = c.Start()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the other process to wait for the lock, but it finished with %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error of the other process: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the other process to get the lock after it was released")
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package verify compares recorded forecasts with the weather that was
+// observed later on.
+package verify
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/weather"
+)
+
+const (
+	// Maximum error of a temperature forecast that counts as a hit, in °C.
+	TemperatureTolerance = 2.0
+	// Daily precipitation from which a day counts as rainy, in mm.
+	RainThreshold = 1.0
+	// Maximum distance of an observation from a forecast hour.
+	matchWindow = 30 * time.Minute
+	// Observations a day needs to be compared with the daily forecast, fewer
+	// don't give a reliable minimum and maximum.
+	minObservations = 8
+)
+
+// Stats summarizes the errors of the forecasts, which are forecast minus
+// observed.
+type Stats struct {
+	Count int `json:"count"`
+	// Mean error, positive if the forecast was too high.
+	Bias float64 `json:"bias"`
+	// Mean absolute error.
+	MAE float64 `json:"mae"`
+	// Share of forecasts within the tolerance, from 0 to 1.
+	HitRate float64 `json:"hitRate"`
+}
+
+// Lead holds the accuracy of the forecasts for a day ahead of their issue.
+// Day 1 is the day the forecast was issued, or the first 24 hours for the
+// hourly temperature.
+type Lead struct {
+	Days           int   `json:"days"`
+	Hourly         Stats `json:"hourly"`
+	TemperatureMax Stats `json:"temperatureMax"`
+	TemperatureMin Stats `json:"temperatureMin"`
+	Precipitation  Stats `json:"precipitation"`
+}
+
+type accumulator struct {
+	n, hits  int
+	sum, abs float64
+}
+
+func (a *accumulator) add(err float64, hit bool) {
+	a.n++
+	a.sum += err
+	a.abs += math.Abs(err)
+
+	if hit {
+		a.hits++
+	}
+}
+
+func (a *accumulator) stats() Stats {
+	if a.n == 0 {
+		return Stats{}
+	}
+
+	return Stats{
+		Count:   a.n,
+		Bias:    round(a.sum / float64(a.n)),
+		MAE:     round(a.abs / float64(a.n)),
+		HitRate: round(float64(a.hits) / float64(a.n)),
+	}
+}
+
+type leadAccumulators struct {
+	hourly, max, min, rain accumulator
+}
+
+// Compares the snapshots with the observations of the same location. Only days
+// before the day of now are compared with the daily forecasts, as the days
+// after aren't over yet.
+func Verify(snaps []history.Snapshot, obs []history.Observation, now time.Time) []Lead {
+	loc := weather.Zurich
+	leads := map[int]*leadAccumulators{}
+
+	lead := func(days int) *leadAccumulators {
+		if leads[days] == nil {
+			leads[days] = &leadAccumulators{}
+		}
+
+		return leads[days]
+	}
+
+	observedDays := map[string]history.Day{}
+	today := now.In(loc).Format("2006-01-02")
+
+	for _, d := range history.Daily(obs, loc) {
+		if d.Observations >= minObservations && d.Date < today {
+			observedDays[d.Date] = d
+		}
+	}
+
+	for _, s := range snaps {
+		for _, h := range s.Hours {
+			o, ok := closest(obs, h.Time)
+			if !ok {
+				continue
+			}
+
+			err := h.Temperature - o.Temperature
+			days := int(h.Time.Sub(s.IssuedAt)/(24*time.Hour)) + 1
+
+			lead(days).hourly.add(err, math.Abs(err) <= TemperatureTolerance)
+		}
+
+		issued := s.IssuedAt.In(loc)
+		issuedDate := time.Date(issued.Year(), issued.Month(), issued.Day(), 0, 0, 0, 0, time.UTC)
+
+		for _, d := range s.Days {
+			o, ok := observedDays[d.DayDate]
+			if !ok {
+				continue
+			}
+
+			date, err := time.Parse("2006-01-02", d.DayDate)
+			if err != nil || date.Before(issuedDate) {
+				continue
+			}
+
+			l := lead(int(date.Sub(issuedDate)/(24*time.Hour)) + 1)
+
+			maxErr := d.TemperatureMax - o.TemperatureMax
+			l.max.add(maxErr, math.Abs(maxErr) <= TemperatureTolerance)
+
+			minErr := d.TemperatureMin - o.TemperatureMin
+			l.min.add(minErr, math.Abs(minErr) <= TemperatureTolerance)
+
+			l.rain.add(d.Precipitation-o.Precipitation, (d.Precipitation >= RainThreshold) == (o.Precipitation >= RainThreshold))
+		}
+	}
+
+	result := []Lead{}
+
+	for days, l := range leads {
+		result = append(result, Lead{
+			Days:           days,
+			Hourly:         l.hourly.stats(),
+			TemperatureMax: l.max.stats(),
+			TemperatureMin: l.min.stats(),
+			Precipitation:  l.rain.stats(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Days < result[j].Days })
+
+	return result
+}
+
+// Returns the observation closest to t, if there is one within matchWindow.
+// The observations must be ordered by time.
+func closest(obs []history.Observation, t time.Time) (history.Observation, bool) {
+	i := sort.Search(len(obs), func(i int) bool { return !obs[i].Time.Before(t) })
+
+	best, found := history.Observation{}, false
+	bestDistance := matchWindow + 1
+
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(obs) {
+			continue
+		}
+
+		d := obs[j].Time.Sub(t)
+		if d < 0 {
+			d = -d
+		}
+
+		if d <= matchWindow && d < bestDistance {
+			best, found, bestDistance = obs[j], true, d
+		}
+	}
+
+	return best, found
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,101 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package verify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/history"
+	"github.com/darox/sunly/internal/weather"
+)
+
+func TestVerify(t *testing.T) {
+	loc := weather.Zurich
+	at := func(day, hour int) time.Time { return time.Date(2023, 5, day, hour, 0, 0, 0, loc) }
+
+	obs := []history.Observation{}
+
+	// Hourly observations of the 7th from 5 to 19 °C and one on the 8th
+	for h := 0; h < 24; h++ {
+		obs = append(obs, history.Observation{Time: at(7, h), Temperature: 5 + float64(h)*14/23})
+	}
+
+	obs = append(obs, history.Observation{Time: at(8, 8).Add(10 * time.Minute), Temperature: 12})
+
+	snaps := []history.Snapshot{{
+		IssuedAt: at(7, 6),
+		Days: []weather.Day{
+			{DayDate: "2023-05-07", TemperatureMax: 18, TemperatureMin: 5, Precipitation: 2},
+			{DayDate: "2023-05-08", TemperatureMax: 20, TemperatureMin: 8},
+		},
+		Hours: []history.ForecastHour{
+			{Time: at(7, 7), Temperature: obs[7].Temperature - 1},
+			{Time: at(8, 8), Temperature: 15},
+			// No observation close enough
+			{Time: at(8, 12), Temperature: 16},
+		},
+	}}
+
+	leads := Verify(snaps, obs, at(9, 12))
+
+	if len(leads) != 2 {
+		t.Fatalf("Expected 2 leads, but got %+v", leads)
+	}
+
+	day1, day2 := leads[0], leads[1]
+
+	if day1.Days != 1 || day1.Hourly.Count != 1 || day1.Hourly.Bias != -1 || day1.Hourly.HitRate != 1 {
+		t.Errorf("Unexpected hourly stats of day 1 %+v", day1.Hourly)
+	}
+
+	if day1.TemperatureMax.Bias != -1 || day1.TemperatureMin.MAE != 0 || day1.TemperatureMax.HitRate != 1 {
+		t.Errorf("Unexpected daily stats of day 1 %+v %+v", day1.TemperatureMax, day1.TemperatureMin)
+	}
+
+	// Rain was forecast, but none was observed
+	if day1.Precipitation.Count != 1 || day1.Precipitation.HitRate != 0 || day1.Precipitation.Bias != 2 {
+		t.Errorf("Unexpected precipitation stats of day 1 %+v", day1.Precipitation)
+	}
+
+	// The 8th has too few observations for the daily forecast
+	if day2.Days != 2 || day2.Hourly.Count != 1 || day2.Hourly.MAE != 3 || day2.Hourly.HitRate != 0 || day2.TemperatureMax.Count != 0 {
+		t.Errorf("Unexpected stats of day 2 %+v", day2)
+	}
+}
+
+func TestVerifySkipsUnfinishedDays(t *testing.T) {
+	loc := weather.Zurich
+	obs := []history.Observation{}
+
+	for h := 0; h < 12; h++ {
+		obs = append(obs, history.Observation{Time: time.Date(2023, 5, 7, h, 0, 0, 0, loc), Temperature: 10})
+	}
+
+	snaps := []history.Snapshot{{
+		IssuedAt: time.Date(2023, 5, 7, 0, 0, 0, 0, loc),
+		Days:     []weather.Day{{DayDate: "2023-05-07", TemperatureMax: 10}},
+	}}
+
+	leads := Verify(snaps, obs, time.Date(2023, 5, 7, 12, 0, 0, 0, loc))
+
+	if len(leads) != 0 {
+		t.Errorf("Expected no stats for the running day, but got %+v", leads)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package watch redraws the output of a command in place whenever new data is
+// expected.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// MeteoSwiss publishes new data a few minutes after its timestamp.
+	publishDelay = 2 * time.Minute
+
+	clearScreen = "\x1b[H\x1b[2J"
+	highlightOn = "\x1b[7m"
+	resetStyle  = "\x1b[0m"
+)
+
+// Frame is one rendering of the watched data.
+type Frame struct {
+	Text string
+	// Time of the underlying data, used to align the refreshes with the updates
+	// of MeteoSwiss. Zero if unknown.
+	UpdatedAt time.Time
+}
+
+// Fetches and draws frames to out until the context is done. Values that changed
+// since the previous frame are highlighted.
+func Run(ctx context.Context, out io.Writer, interval time.Duration, fetch func(ctx context.Context) (Frame, error)) error {
+	prev := ""
+
+	for {
+		f, err := fetch(ctx)
+		if ctx.Err() != nil {
+			// Cancelled while fetching, e.g. by Ctrl-C
+			return nil
+		}
+
+		now := time.Now()
+
+		var next time.Time
+
+		if err != nil {
+			// Keep showing the last data and try again later
+			next = now.Add(interval)
+			draw(out, prev, fmt.Sprintf("Error: %s, retrying at %s", err, next.Format("15:04")))
+		} else {
+			next = NextRefresh(now, f.UpdatedAt, interval)
+			draw(out, Highlight(prev, f.Text), fmt.Sprintf("Refreshed at %s, next refresh at %s (Ctrl-C to quit)",
+				now.Format("15:04:05"), next.Format("15:04")))
+			prev = f.Text
+		}
+
+		t := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+func draw(out io.Writer, text string, status string) {
+	fmt.Fprint(out, clearScreen)
+
+	if text != "" {
+		fmt.Fprintln(out, text)
+	}
+
+	fmt.Fprintln(out, status)
+}
+
+// Returns the time of the next refresh. It is placed on the grid of the update
+// times of the data, so the refresh happens right after new data got published.
+func NextRefresh(now time.Time, updatedAt time.Time, interval time.Duration) time.Time {
+	if updatedAt.IsZero() {
+		return now.Add(interval)
+	}
+
+	next := updatedAt.Add(interval + publishDelay)
+
+	if !next.After(now) {
+		// Skip the updates we already missed
+		next = next.Add((now.Sub(next)/interval + 1) * interval)
+	}
+
+	return next
+}
+
+// Highlights the cells of the table in cur that differ from prev. Lines that
+// can't be compared cell by cell are highlighted as a whole.
+func Highlight(prev string, cur string) string {
+	if prev == "" {
+		return cur
+	}
+
+	prevLines := strings.Split(prev, "\n")
+	lines := strings.Split(cur, "\n")
+
+	for i, line := range lines {
+		if i >= len(prevLines) || line == prevLines[i] {
+			continue
+		}
+
+		cells := strings.Split(line, "|")
+		prevCells := strings.Split(prevLines[i], "|")
+
+		if len(cells) != len(prevCells) {
+			lines[i] = highlightOn + line + resetStyle
+			continue
+		}
+
+		for j := range cells {
+			if cells[j] != prevCells[j] {
+				cells[j] = highlightOn + cells[j] + resetStyle
+			}
+		}
+
+		lines[i] = strings.Join(cells, "|")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,87 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package watch
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextRefresh(t *testing.T) {
+	updatedAt := time.Date(2023, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		updatedAt time.Time
+		expected  time.Time
+	}{
+		{"next update", updatedAt.Add(3 * time.Minute), updatedAt, updatedAt.Add(12 * time.Minute)},
+		{"missed updates", updatedAt.Add(25 * time.Minute), updatedAt, updatedAt.Add(32 * time.Minute)},
+		{"unknown update time", updatedAt, time.Time{}, updatedAt.Add(10 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		got := NextRefresh(tt.now, tt.updatedAt, 10*time.Minute)
+		if !got.Equal(tt.expected) {
+			t.Errorf("%s: expected %s, but got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	prev := "| Zip | Temp |\n| 3006 | 17.0 |"
+	cur := "| Zip | Temp |\n| 3006 | 18.0 |"
+
+	got := Highlight(prev, cur)
+	expected := "| Zip | Temp |\n| 3006 |" + highlightOn + " 18.0 " + resetStyle + "|"
+
+	if got != expected {
+		t.Errorf("Expected %q, but got %q", expected, got)
+	}
+
+	if Highlight("", cur) != cur {
+		t.Errorf("Expected the first frame not to be highlighted")
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var out bytes.Buffer
+
+	calls := 0
+	err := Run(ctx, &out, time.Hour, func(ctx context.Context) (Frame, error) {
+		calls++
+		// Stop while waiting for the next refresh
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		return Frame{Text: "17.0 °C"}, nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	if calls != 1 || !strings.Contains(out.String(), "17.0 °C") {
+		t.Errorf("Expected one drawn frame, got %d calls and %q", calls, out.String())
+	}
+}
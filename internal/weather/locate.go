@@ -0,0 +1,69 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package weather
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darox/sunly/pkg/swisspost"
+)
+
+// Looks up the location of a Swiss zip code at the Swiss Post.
+func Locate(ctx context.Context, zip string) (Location, error) {
+	ld := swisspost.LocationData{}
+
+	err := ld.GetLocationDataByZipContext(ctx, zip)
+	if err != nil {
+		return Location{}, fmt.Errorf("something went wrong when fetching the location: %w", err)
+	}
+
+	l, ok := LocationOf(&ld, zip)
+	if !ok {
+		return Location{}, fmt.Errorf("the zip code %s is not valid", zip)
+	}
+
+	return l, nil
+}
+
+// Returns the location of the zip code from the location data, or false if
+// the zip code is not valid.
+func LocationOf(ld *swisspost.LocationData, zip string) (Location, bool) {
+	if !ld.IsZipValid(zip) {
+		return Location{}, false
+	}
+
+	for _, r := range ld.Records {
+		if r.Fields.Postleitzahl != zip {
+			continue
+		}
+
+		l := Location{Zip: zip, Name: r.Fields.Ortbez18}
+
+		// The geo point is given as latitude and longitude
+		if len(r.Fields.GeoPoint2D) == 2 {
+			l.Latitude = r.Fields.GeoPoint2D[0]
+			l.Longitude = r.Fields.GeoPoint2D[1]
+		}
+
+		return l, true
+	}
+
+	return Location{Zip: zip, Name: ld.Records[0].Fields.Ortbez18}, true
+}
@@ -0,0 +1,156 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package meteoswiss provides the weather of MeteoSwiss. Importing it
+// registers the provider.
+package meteoswiss
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+// Name the provider is registered under.
+const Name = "meteoswiss"
+
+func init() {
+	weather.Register(Name, func(c weather.Config) (weather.Provider, error) {
+		return Provider{}, nil
+	})
+}
+
+// Cloud cover in % of the conditions of the icons, as MeteoSwiss has no
+// forecast of it.
+var conditionCloudCover = map[swissmeteo.Condition]float64{
+	swissmeteo.ConditionClear:        0,
+	swissmeteo.ConditionPartlyCloudy: 45,
+	swissmeteo.ConditionCloudy:       90,
+	swissmeteo.ConditionFog:          100,
+	swissmeteo.ConditionRain:         90,
+	swissmeteo.ConditionSleet:        95,
+	swissmeteo.ConditionSnow:         95,
+	swissmeteo.ConditionThunderstorm: 85,
+}
+
+// Provider fetches the weather of a zip code from MeteoSwiss.
+type Provider struct{}
+
+func (Provider) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	if l.Zip == "" {
+		return nil, errors.New("MeteoSwiss only provides the weather of Swiss zip codes")
+	}
+
+	w := &swissmeteo.Weather{}
+
+	err := w.GetWeatherData(ctx, l.Zip)
+	if err != nil {
+		return nil, err
+	}
+
+	return Convert(w), nil
+}
+
+// Returns the cloud cover in % of the icon, nil for a missing or unknown icon.
+func cloudCover(icon int) *float64 {
+	if icon == 0 {
+		return nil
+	}
+
+	c, ok := conditionCloudCover[swissmeteo.IconCondition(icon)]
+	if !ok {
+		return nil
+	}
+
+	return &c
+}
+
+// Converts the data of MeteoSwiss into the shared model.
+func Convert(w *swissmeteo.Weather) *weather.Weather {
+	result := &weather.Weather{
+		Provider: Name,
+		Current: weather.Current{
+			Temperature: w.CurrentWeather.Temperature,
+			Icon:        w.CurrentWeather.Icon,
+		},
+		Days:     make([]weather.Day, 0, len(w.Forecast)),
+		Hours:    []weather.Hour{},
+		Warnings: make([]weather.Warning, 0, len(w.Warnings)),
+	}
+
+	// A missing time means there is no current weather
+	if w.CurrentWeather.Time != 0 {
+		result.UpdatedAt = w.UpdatedAt()
+	}
+
+	for _, d := range w.Forecast {
+		result.Days = append(result.Days, weather.Day{
+			DayDate:        d.DayDate,
+			IconDay:        d.IconDay,
+			TemperatureMax: float64(d.TemperatureMax),
+			TemperatureMin: float64(d.TemperatureMin),
+			Precipitation:  d.Precipitation,
+		})
+	}
+
+	// MeteoSwiss has no humidity, the cloud cover is estimated from the icon
+	for _, h := range w.Hours() {
+		result.Hours = append(result.Hours, weather.Hour{
+			Time:             h.Time,
+			Icon:             h.Icon,
+			TemperatureMean:  h.TemperatureMean,
+			TemperatureMin:   h.TemperatureMin,
+			TemperatureMax:   h.TemperatureMax,
+			Precipitation:    h.Precipitation,
+			PrecipitationMin: h.PrecipitationMin,
+			PrecipitationMax: h.PrecipitationMax,
+			WindSpeed:        h.WindSpeed,
+			WindDirection:    h.WindDirection,
+			CloudCover:       cloudCover(h.Icon),
+		})
+	}
+
+	for _, wa := range w.Warnings {
+		c := weather.Warning{
+			Type:      wa.Type(),
+			Level:     wa.WarnLevel,
+			Text:      wa.Text,
+			ValidFrom: time.UnixMilli(wa.ValidFrom),
+			Outlook:   wa.Outlook,
+		}
+
+		if wa.ValidTo != 0 {
+			c.ValidTo = time.UnixMilli(wa.ValidTo)
+		}
+
+		result.Warnings = append(result.Warnings, c)
+	}
+
+	for _, s := range w.Graph.Sunrise {
+		result.Sunrise = append(result.Sunrise, time.UnixMilli(s))
+	}
+
+	for _, s := range w.Graph.Sunset {
+		result.Sunset = append(result.Sunset, time.UnixMilli(s))
+	}
+
+	return result
+}
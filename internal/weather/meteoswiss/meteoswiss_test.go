@@ -0,0 +1,92 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package meteoswiss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+func TestConvert(t *testing.T) {
+	s := &swissmeteo.Weather{}
+	s.CurrentWeather.Time = 1683452400000
+	s.CurrentWeather.Icon = 1
+	s.CurrentWeather.Temperature = 17
+	s.Forecast = []swissmeteo.Day{{DayDate: "2023-05-07", IconDay: 25, TemperatureMax: 18, TemperatureMin: 11, Precipitation: 12.7}}
+	s.Graph.Start = 1683410400000
+	s.Graph.StartLowResolution = 1683410400000
+	s.Graph.TemperatureMean1H = []float64{14.1, 13.7}
+	s.Graph.Precipitation1H = []float64{1.3, 1.9}
+	s.Graph.Sunrise = []int64{1683431460000}
+	s.Graph.Sunset = []int64{1683484980000}
+	s.Warnings = []swissmeteo.Warning{{WarnType: 1, WarnLevel: 3, ValidFrom: 1683410400000}}
+
+	w := Convert(s)
+
+	if w.Provider != Name {
+		t.Errorf("Expected provider to be %s, but got %s", Name, w.Provider)
+	}
+
+	if !w.UpdatedAt.Equal(time.UnixMilli(1683452400000)) {
+		t.Errorf("Expected update time to be the time of the current weather, but got %s", w.UpdatedAt)
+	}
+
+	if len(w.Days) != 1 || w.Days[0].TemperatureMax != 18 || w.Days[0].IconDay != 25 {
+		t.Errorf("Expected the forecast day to be converted, but got %+v", w.Days)
+	}
+
+	if len(w.Hours) != 2 || w.Hours[1].Precipitation != 1.9 {
+		t.Errorf("Expected the hours of the graph, but got %+v", w.Hours)
+	}
+
+	if len(w.Warnings) != 1 || w.Warnings[0].Level != 3 || !w.Warnings[0].ValidTo.IsZero() {
+		t.Errorf("Expected an open ended level 3 warning, but got %+v", w.Warnings)
+	}
+
+	if len(w.Sunrise) != 1 || !w.Sunrise[0].Equal(time.UnixMilli(1683431460000)) {
+		t.Errorf("Expected the sunrise to be converted, but got %v", w.Sunrise)
+	}
+}
+
+func TestConvertWithoutCurrentWeather(t *testing.T) {
+	w := Convert(&swissmeteo.Weather{})
+
+	if !w.UpdatedAt.IsZero() {
+		t.Errorf("Expected no update time, but got %s", w.UpdatedAt)
+	}
+}
+
+func TestCloudCover(t *testing.T) {
+	tests := map[int]float64{1: 0, 5: 90, 101: 0, 17: 90}
+
+	for icon, expected := range tests {
+		c := cloudCover(icon)
+		if c == nil {
+			t.Errorf("Expected a cloud cover of %v for icon %d, but got none", expected, icon)
+		} else if *c != expected {
+			t.Errorf("Expected a cloud cover of %v for icon %d, but got %v", expected, icon, *c)
+		}
+	}
+
+	if c := cloudCover(0); c != nil {
+		t.Errorf("Expected no cloud cover without an icon, but got %v", *c)
+	}
+}
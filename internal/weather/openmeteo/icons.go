@@ -0,0 +1,69 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package openmeteo
+
+// Offset of the night variants of the MeteoSwiss icons.
+const nightIconOffset = 100
+
+// Maps the WMO weather codes of Open-Meteo to the closest MeteoSwiss icons.
+var icons = map[int]int{
+	0:  1,  // clear sky
+	1:  2,  // mainly clear
+	2:  3,  // partly cloudy
+	3:  4,  // overcast
+	45: 28, // fog
+	48: 28, // depositing rime fog
+	51: 14, // light drizzle
+	53: 14, // moderate drizzle
+	55: 17, // dense drizzle
+	56: 15, // light freezing drizzle
+	57: 18, // dense freezing drizzle
+	61: 14, // slight rain
+	63: 17, // moderate rain
+	65: 20, // heavy rain
+	66: 15, // light freezing rain
+	67: 21, // heavy freezing rain
+	71: 16, // slight snow fall
+	73: 19, // moderate snow fall
+	75: 22, // heavy snow fall
+	77: 16, // snow grains
+	80: 6,  // slight rain showers
+	81: 29, // moderate rain showers
+	82: 33, // violent rain showers
+	85: 8,  // slight snow showers
+	86: 34, // heavy snow showers
+	95: 24, // thunderstorm
+	96: 25, // thunderstorm with slight hail
+	99: 25, // thunderstorm with heavy hail
+}
+
+// Returns the MeteoSwiss icon of a WMO weather code, the night variant if it
+// is not day. Unknown codes are shown as very cloudy.
+func Icon(code int, day bool) int {
+	i, ok := icons[code]
+	if !ok {
+		i = 5
+	}
+
+	if !day {
+		i += nightIconOffset
+	}
+
+	return i
+}
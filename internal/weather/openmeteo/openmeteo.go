@@ -0,0 +1,262 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package openmeteo provides the weather of the Open-Meteo forecast API or a
+// compatible service. Importing it registers the provider.
+package openmeteo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Name the provider is registered under.
+const Name = "openmeteo"
+
+// DefaultBaseURL is the address of the public Open-Meteo API.
+const DefaultBaseURL = "https://api.open-meteo.com"
+
+// Number of forecast days requested.
+const forecastDays = 7
+
+func init() {
+	weather.Register(Name, func(c weather.Config) (weather.Provider, error) {
+		return New(c.BaseURL)
+	})
+}
+
+// Provider fetches the weather of coordinates from an Open-Meteo API.
+type Provider struct {
+	baseURL *url.URL
+}
+
+// Creates a provider for the API at the base URL, the public API if it is
+// empty.
+func New(baseURL string) (*Provider, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+
+	return &Provider{baseURL: u}, nil
+}
+
+// Response is the part of the forecast response sunly uses. Times are
+// requested as unix timestamps.
+type Response struct {
+	Timezone         string `json:"timezone"`
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+	Current          struct {
+		Time               int64   `json:"time"`
+		Temperature2M      float64 `json:"temperature_2m"`
+		RelativeHumidity2M float64 `json:"relative_humidity_2m"`
+		WeatherCode        int     `json:"weather_code"`
+		IsDay              int     `json:"is_day"`
+	} `json:"current"`
+	Hourly struct {
+		Time               []int64   `json:"time"`
+		Temperature2M      []float64 `json:"temperature_2m"`
+		RelativeHumidity2M []float64 `json:"relative_humidity_2m"`
+		CloudCover         []float64 `json:"cloud_cover"`
+		Precipitation      []float64 `json:"precipitation"`
+		WeatherCode        []int     `json:"weather_code"`
+		WindSpeed10M       []float64 `json:"wind_speed_10m"`
+		WindDirection10M   []int     `json:"wind_direction_10m"`
+		IsDay              []int     `json:"is_day"`
+		// Mean of the preceding hour.
+		ShortwaveRadiation []float64 `json:"shortwave_radiation"`
+	} `json:"hourly"`
+	Daily struct {
+		Time             []int64   `json:"time"`
+		WeatherCode      []int     `json:"weather_code"`
+		Temperature2MMax []float64 `json:"temperature_2m_max"`
+		Temperature2MMin []float64 `json:"temperature_2m_min"`
+		PrecipitationSum []float64 `json:"precipitation_sum"`
+		Sunrise          []int64   `json:"sunrise"`
+		Sunset           []int64   `json:"sunset"`
+	} `json:"daily"`
+}
+
+func (p *Provider) Weather(ctx context.Context, l weather.Location) (*weather.Weather, error) {
+	if l.Latitude == 0 && l.Longitude == 0 {
+		return nil, fmt.Errorf("the location %s has no coordinates", l.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.forecastURL(l), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching weather data: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting weather data from API: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from weather API: %d", resp.StatusCode)
+	}
+
+	r := &Response{}
+
+	err = json.NewDecoder(resp.Body).Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding weather data: %w", err)
+	}
+
+	return Convert(r), nil
+}
+
+// Returns the URL of the forecast of the location.
+func (p *Provider) forecastURL(l weather.Location) string {
+	q := url.Values{}
+	q.Set("latitude", strconv.FormatFloat(l.Latitude, 'f', 4, 64))
+	q.Set("longitude", strconv.FormatFloat(l.Longitude, 'f', 4, 64))
+	q.Set("current", "temperature_2m,relative_humidity_2m,weather_code,is_day")
+	q.Set("hourly", "temperature_2m,relative_humidity_2m,cloud_cover,precipitation,weather_code,wind_speed_10m,"+
+		"wind_direction_10m,is_day,shortwave_radiation")
+	q.Set("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum,sunrise,sunset")
+	q.Set("timezone", "auto")
+	q.Set("timeformat", "unixtime")
+	q.Set("wind_speed_unit", "kmh")
+	q.Set("forecast_days", strconv.Itoa(forecastDays))
+
+	u := *p.baseURL
+	u.Path += "/v1/forecast"
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// Converts the response into the shared model.
+func Convert(r *Response) *weather.Weather {
+	w := &weather.Weather{
+		Provider: Name,
+		Current: weather.Current{
+			Temperature: r.Current.Temperature2M,
+			Icon:        Icon(r.Current.WeatherCode, r.Current.IsDay != 0),
+			Humidity:    r.Current.RelativeHumidity2M,
+		},
+		Days:     []weather.Day{},
+		Hours:    []weather.Hour{},
+		Warnings: []weather.Warning{},
+	}
+
+	if r.Current.Time != 0 {
+		w.UpdatedAt = time.Unix(r.Current.Time, 0)
+	}
+
+	// The days start at midnight of the location
+	zone := r.location()
+
+	d := r.Daily
+	for i, t := range d.Time {
+		w.Days = append(w.Days, weather.Day{
+			DayDate:        time.Unix(t, 0).In(zone).Format("2006-01-02"),
+			IconDay:        Icon(at(d.WeatherCode, i), true),
+			TemperatureMax: at(d.Temperature2MMax, i),
+			TemperatureMin: at(d.Temperature2MMin, i),
+			Precipitation:  at(d.PrecipitationSum, i),
+		})
+	}
+
+	// Sunrise and sunset are missing in the polar day and night
+	for i := range d.Sunrise {
+		if d.Sunrise[i] == 0 || i >= len(d.Sunset) || d.Sunset[i] == 0 {
+			continue
+		}
+
+		w.Sunrise = append(w.Sunrise, time.Unix(d.Sunrise[i], 0))
+		w.Sunset = append(w.Sunset, time.Unix(d.Sunset[i], 0))
+	}
+
+	h := r.Hourly
+	for i, t := range h.Time {
+		// Hours without an is_day value count as day
+		day := i >= len(h.IsDay) || h.IsDay[i] != 0
+
+		temperature := at(h.Temperature2M, i)
+		precipitation := at(h.Precipitation, i)
+
+		w.Hours = append(w.Hours, weather.Hour{
+			Time:             time.Unix(t, 0),
+			Icon:             Icon(at(h.WeatherCode, i), day),
+			TemperatureMean:  temperature,
+			TemperatureMin:   temperature,
+			TemperatureMax:   temperature,
+			Precipitation:    precipitation,
+			PrecipitationMin: precipitation,
+			PrecipitationMax: precipitation,
+			WindSpeed:        at(h.WindSpeed10M, i),
+			WindDirection:    at(h.WindDirection10M, i),
+			Humidity:         at(h.RelativeHumidity2M, i),
+		})
+
+		if i < len(h.CloudCover) {
+			w.Hours[i].CloudCover = &h.CloudCover[i]
+		}
+
+		// The radiation is the mean of the preceding hour, so the one of the
+		// next time belongs to this hour
+		if i+1 < len(h.ShortwaveRadiation) {
+			w.Hours[i].Radiation = &h.ShortwaveRadiation[i+1]
+		}
+	}
+
+	return w
+}
+
+// Returns the time zone of the location. The UTC offset only holds at the time
+// of the request, so it is used only if the zone can't be loaded.
+func (r *Response) location() *time.Location {
+	if r.Timezone != "" {
+		loc, err := time.LoadLocation(r.Timezone)
+		if err == nil {
+			return loc
+		}
+	}
+
+	return time.FixedZone("", r.UTCOffsetSeconds)
+}
+
+// Returns the value at the index or the zero value if the series is shorter.
+func at[T any](values []T, i int) T {
+	var zero T
+
+	if i < len(values) {
+		return values[i]
+	}
+
+	return zero
+}
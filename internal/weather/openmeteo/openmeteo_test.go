@@ -0,0 +1,232 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package openmeteo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darox/sunly/internal/weather"
+)
+
+// Response of the forecast API for Munich, shortened to two days and hours.
+const testResponse = `{
+	"latitude": 48.14,
+	"longitude": 11.58,
+	"timezone": "Europe/Berlin",
+	"utc_offset_seconds": 7200,
+	"current": {"time": 1683453600, "temperature_2m": 17.3, "relative_humidity_2m": 55, "weather_code": 2, "is_day": 1},
+	"hourly": {
+		"time": [1683410400, 1683414000],
+		"temperature_2m": [11.2, 10.8],
+		"relative_humidity_2m": [71, 78],
+		"cloud_cover": [12, 100],
+		"precipitation": [0.0, 0.6],
+		"weather_code": [0, 61],
+		"wind_speed_10m": [7.2, 11.5],
+		"wind_direction_10m": [250, 270],
+		"is_day": [0, 0],
+		"shortwave_radiation": [0, 35]
+	},
+	"daily": {
+		"time": [1683410400, 1683496800],
+		"weather_code": [95, 3],
+		"temperature_2m_max": [21.4, 19.0],
+		"temperature_2m_min": [9.8, 10.1],
+		"precipitation_sum": [4.2, 0.0],
+		"sunrise": [1683431100, 1683517400],
+		"sunset": [1683484800, 1683571300]
+	}
+}`
+
+func TestWeather(t *testing.T) {
+	var query map[string][]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/forecast" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(testResponse))
+	}))
+	defer srv.Close()
+
+	p, err := New(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %s", err)
+	}
+
+	w, err := p.Weather(context.Background(), weather.Location{Name: "München", Latitude: 48.137, Longitude: 11.575})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %s", err)
+	}
+
+	if query["latitude"][0] != "48.1370" || query["longitude"][0] != "11.5750" {
+		t.Errorf("Expected the coordinates in the query, but got %v", query)
+	}
+
+	if query["timeformat"][0] != "unixtime" {
+		t.Errorf("Expected unix timestamps to be requested, but got %v", query["timeformat"])
+	}
+
+	if w.Provider != Name || w.Current.Temperature != 17.3 || w.Current.Icon != 3 {
+		t.Errorf("Expected the current weather to be converted, but got %+v", w.Current)
+	}
+
+	if w.Current.Humidity != 55 || len(w.Hours) != 2 || w.Hours[1].Humidity != 78 {
+		t.Errorf("Expected the humidity to be converted, but got %v and %+v", w.Current.Humidity, w.Hours)
+	}
+
+	if w.Hours[0].CloudCover == nil || *w.Hours[0].CloudCover != 12 {
+		t.Errorf("Expected the cloud cover to be converted, but got %v", w.Hours[0].CloudCover)
+	}
+
+	// The radiation is the mean of the preceding hour
+	if w.Hours[0].Radiation == nil || *w.Hours[0].Radiation != 35 || w.Hours[1].Radiation != nil {
+		t.Errorf("Expected the radiation of the next time in the first hour, but got %v and %v",
+			w.Hours[0].Radiation, w.Hours[1].Radiation)
+	}
+
+	if !w.UpdatedAt.Equal(time.Unix(1683453600, 0)) {
+		t.Errorf("Expected update time to be the time of the current weather, but got %s", w.UpdatedAt)
+	}
+}
+
+func TestWeatherWithoutCoordinates(t *testing.T) {
+	p, _ := New("http://localhost:1")
+
+	_, err := p.Weather(context.Background(), weather.Location{Zip: "3006"})
+	if err == nil {
+		t.Errorf("Expected an error for a location without coordinates")
+	}
+}
+
+func TestWeatherStatusCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": true, "reason": "Latitude must be in range of -90 to 90°."}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	p, _ := New(srv.URL)
+
+	_, err := p.Weather(context.Background(), weather.Location{Latitude: 48.1, Longitude: 11.6})
+	if err == nil {
+		t.Errorf("Expected an error for status code 400")
+	}
+}
+
+func TestNewInvalidBaseURL(t *testing.T) {
+	_, err := New("localhost")
+	if err == nil {
+		t.Errorf("Expected an error for a base URL without scheme")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	r := &Response{UTCOffsetSeconds: 7200}
+	r.Daily.Time = []int64{1683410400}
+	r.Daily.WeatherCode = []int{95}
+	r.Daily.Temperature2MMax = []float64{21.4}
+	r.Daily.Temperature2MMin = []float64{9.8}
+	r.Daily.PrecipitationSum = []float64{4.2}
+	r.Daily.Sunrise = []int64{1683431100}
+	r.Daily.Sunset = []int64{1683484800}
+	r.Hourly.Time = []int64{1683410400, 1683414000}
+	r.Hourly.Temperature2M = []float64{11.2, 10.8}
+	r.Hourly.Precipitation = []float64{0, 0.6}
+	r.Hourly.WeatherCode = []int{0, 61}
+	r.Hourly.IsDay = []int{0}
+
+	w := Convert(r)
+
+	// Midnight of the 7th in UTC+2 is still the 6th in UTC
+	if len(w.Days) != 1 || w.Days[0].DayDate != "2023-05-07" {
+		t.Fatalf("Expected the day 2023-05-07, but got %+v", w.Days)
+	}
+
+	if w.Days[0].IconDay != 24 || w.Days[0].TemperatureMax != 21.4 || w.Days[0].Precipitation != 4.2 {
+		t.Errorf("Expected the daily values to be converted, but got %+v", w.Days[0])
+	}
+
+	if len(w.Hours) != 2 {
+		t.Fatalf("Expected 2 hours, but got %d", len(w.Hours))
+	}
+
+	if w.Hours[0].Icon != 101 {
+		t.Errorf("Expected the clear night icon, but got %d", w.Hours[0].Icon)
+	}
+
+	// Wind is missing and the hour is day without is_day
+	if w.Hours[1].Icon != 14 || w.Hours[1].Precipitation != 0.6 || w.Hours[1].WindSpeed != 0 {
+		t.Errorf("Expected the light rain hour, but got %+v", w.Hours[1])
+	}
+
+	if len(w.Sunrise) != 1 || !w.Sunset[0].Equal(time.Unix(1683484800, 0)) {
+		t.Errorf("Expected sunrise and sunset to be converted, but got %v and %v", w.Sunrise, w.Sunset)
+	}
+
+	if !w.UpdatedAt.IsZero() {
+		t.Errorf("Expected no update time without current weather, but got %s", w.UpdatedAt)
+	}
+}
+
+func TestConvertDaylightSavingTime(t *testing.T) {
+	// Requested in winter time, the second day starts in summer time
+	r := &Response{Timezone: "Europe/Zurich", UTCOffsetSeconds: 3600}
+	r.Daily.Time = []int64{1711839600, 1711922400}
+
+	w := Convert(r)
+
+	if len(w.Days) != 2 || w.Days[0].DayDate != "2024-03-31" || w.Days[1].DayDate != "2024-04-01" {
+		t.Errorf("Expected the days 2024-03-31 and 2024-04-01, but got %+v", w.Days)
+	}
+
+	// Without a known zone the offset of the request is used
+	r.Timezone = "Unknown/Zone"
+	w = Convert(r)
+
+	if w.Days[0].DayDate != "2024-03-31" {
+		t.Errorf("Expected the first day 2024-03-31, but got %s", w.Days[0].DayDate)
+	}
+}
+
+func TestIcon(t *testing.T) {
+	tests := []struct {
+		code int
+		day  bool
+		want int
+	}{
+		{0, true, 1},
+		{0, false, 101},
+		{45, true, 28},
+		{75, true, 22},
+		{42, true, 5},
+	}
+
+	for _, tt := range tests {
+		if i := Icon(tt.code, tt.day); i != tt.want {
+			t.Errorf("Expected icon of code %d to be %d, but got %d", tt.code, tt.want, i)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package weather
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Name of the provider used when none is selected.
+const DefaultProvider = "meteoswiss"
+
+// Location is a place to get the weather for.
+type Location struct {
+	Zip       string  `json:"zip"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Provider fetches the current weather, the daily and hourly forecast and the
+// warnings of a location.
+type Provider interface {
+	Weather(ctx context.Context, l Location) (*Weather, error)
+}
+
+// Config configures a provider. Which fields are used depends on the
+// provider.
+type Config struct {
+	// Address of the API, for self hosted or compatible services.
+	BaseURL string `yaml:"baseURL"`
+}
+
+// Factory creates a provider from its config.
+type Factory func(c Config) (Provider, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Registers a provider under the name. It panics if the name is taken, as
+// that is a programming error.
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic("weather: provider " + name + " registered twice")
+	}
+
+	factories[name] = f
+}
+
+// Creates the provider registered under the name.
+func New(name string, c Config) (Provider, error) {
+	mu.RLock()
+	f, ok := factories[name]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q, available are %v", name, Providers())
+	}
+
+	return f(c)
+}
+
+// Returns the names of the registered providers.
+func Providers() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for n := range factories {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,81 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package weather
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type fakeProvider struct {
+	baseURL string
+}
+
+func (p fakeProvider) Weather(ctx context.Context, l Location) (*Weather, error) {
+	return &Weather{Provider: "fake", Current: Current{Temperature: 12}}, nil
+}
+
+func TestNew(t *testing.T) {
+	Register("fake", func(c Config) (Provider, error) {
+		return fakeProvider{baseURL: c.BaseURL}, nil
+	})
+
+	p, err := New("fake", Config{BaseURL: "http://localhost:8080"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %s", err)
+	}
+
+	if f, ok := p.(fakeProvider); !ok || f.baseURL != "http://localhost:8080" {
+		t.Errorf("Expected the fake provider with the base URL, but got %#v", p)
+	}
+
+	w, err := p.Weather(context.Background(), Location{Zip: "3006"})
+	if err != nil || w.Current.Temperature != 12 {
+		t.Errorf("Expected the weather of the fake provider, but got %v and %v", w, err)
+	}
+
+	found := false
+	for _, n := range Providers() {
+		found = found || n == "fake"
+	}
+
+	if !found {
+		t.Errorf("Expected the fake provider to be listed, but got %v", Providers())
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	_, err := New("unknown", Config{})
+	if err == nil || !strings.Contains(err.Error(), "unknown provider") {
+		t.Errorf("Expected an unknown provider error, but got %v", err)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	Register("twice", func(c Config) (Provider, error) { return fakeProvider{}, nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering a name twice to panic")
+		}
+	}()
+
+	Register("twice", func(c Config) (Provider, error) { return fakeProvider{}, nil })
+}
@@ -0,0 +1,130 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package weather holds the weather model shared by all providers and the
+// registry of the providers.
+//
+// Icons use the codes of MeteoSwiss, which pkg/swissmeteo describes, so
+// providers with other codes map theirs onto these.
+package weather
+
+import (
+	"time"
+
+	"github.com/darox/sunly/pkg/swissmeteo"
+)
+
+// DefaultMaxAge is the age after which the current weather is considered stale.
+// It allows for providers that update the current weather about once an hour,
+// plus some slack.
+const DefaultMaxAge = swissmeteo.DefaultMaxAge
+
+// Weather is the current weather and the forecast of a location.
+type Weather struct {
+	// Name of the provider the weather is from.
+	Provider string
+	// Time of the last update of the current weather.
+	UpdatedAt time.Time
+	Current   Current
+	Days      []Day
+	Hours     []Hour
+	Warnings  []Warning
+	// Times of sunrise and sunset of the forecast days.
+	Sunrise []time.Time
+	Sunset  []time.Time
+}
+
+// Current is the current weather.
+type Current struct {
+	Temperature float64 `json:"temperature"`
+	Icon        int     `json:"icon"`
+	// Relative humidity in %, zero if the provider has none.
+	Humidity float64 `json:"humidity,omitempty"`
+}
+
+// Day is the forecast of a single day.
+type Day struct {
+	DayDate        string  `json:"dayDate"`
+	IconDay        int     `json:"iconDay"`
+	TemperatureMax float64 `json:"temperatureMax"`
+	TemperatureMin float64 `json:"temperatureMin"`
+	Precipitation  float64 `json:"precipitation"`
+}
+
+// Hour is the forecast of a single hour.
+type Hour struct {
+	Time             time.Time `json:"time"`
+	Icon             int       `json:"icon"`
+	TemperatureMean  float64   `json:"temperatureMean"`
+	TemperatureMin   float64   `json:"temperatureMin"`
+	TemperatureMax   float64   `json:"temperatureMax"`
+	Precipitation    float64   `json:"precipitation"`
+	PrecipitationMin float64   `json:"precipitationMin"`
+	PrecipitationMax float64   `json:"precipitationMax"`
+	WindSpeed        float64   `json:"windSpeed"`
+	WindDirection    int       `json:"windDirection"`
+	// Relative humidity in %, zero if the provider has none.
+	Humidity float64 `json:"humidity,omitempty"`
+	// Cloud cover in %, nil if the provider has none. Providers without a
+	// forecast of it may estimate it from the icon.
+	CloudCover *float64 `json:"cloudCover,omitempty"`
+	// Mean global horizontal irradiance of the hour in W/m², nil if the
+	// provider has none.
+	Radiation *float64 `json:"radiation,omitempty"`
+}
+
+// Returns the end of the hour.
+func (h Hour) End() time.Time {
+	return h.Time.Add(time.Hour)
+}
+
+// Returns the hours that haven't ended at now, starting with the one that is
+// currently running.
+func (w *Weather) HoursFrom(now time.Time) []Hour {
+	hours := []Hour{}
+
+	for _, h := range w.Hours {
+		if h.End().After(now) {
+			hours = append(hours, h)
+		}
+	}
+
+	return hours
+}
+
+// Warning is a weather warning of an authority.
+type Warning struct {
+	// Hazard the warning is about, e.g. wind or thunderstorms.
+	Type string `json:"type"`
+	// Danger level from 1 (minor) to 5 (very high).
+	Level     int       `json:"level"`
+	Text      string    `json:"text"`
+	ValidFrom time.Time `json:"validFrom"`
+	// Zero if the end is not known yet.
+	ValidTo time.Time `json:"validTo"`
+	Outlook bool      `json:"outlook"`
+}
+
+// Freshness describes how old the current weather data is.
+type Freshness = swissmeteo.Freshness
+
+// Evaluates the age of the current weather at the given time. A maxAge of zero
+// or less falls back to DefaultMaxAge.
+func (w *Weather) Freshness(now time.Time, maxAge time.Duration) Freshness {
+	return swissmeteo.NewFreshness(w.UpdatedAt, now, maxAge)
+}
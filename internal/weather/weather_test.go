@@ -0,0 +1,78 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package weather
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreshness(t *testing.T) {
+	updatedAt := time.UnixMilli(1683452400000)
+	w := &Weather{UpdatedAt: updatedAt}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		maxAge    time.Duration
+		wantStale bool
+		wantAge   time.Duration
+	}{
+		{"fresh", updatedAt.Add(20 * time.Minute), time.Hour, false, 20 * time.Minute},
+		{"stale", updatedAt.Add(2 * time.Hour), time.Hour, true, 2 * time.Hour},
+		{"default max age", updatedAt.Add(time.Hour), 0, false, time.Hour},
+		{"future", updatedAt.Add(-time.Minute), time.Hour, false, 0},
+	}
+
+	for _, tt := range tests {
+		f := w.Freshness(tt.now, tt.maxAge)
+
+		if f.Stale != tt.wantStale {
+			t.Errorf("%s: expected stale to be %t, but got %t", tt.name, tt.wantStale, f.Stale)
+		}
+
+		if f.Age() != tt.wantAge {
+			t.Errorf("%s: expected age to be %s, but got %s", tt.name, tt.wantAge, f.Age())
+		}
+	}
+}
+
+func TestFreshnessWithoutData(t *testing.T) {
+	w := &Weather{}
+
+	f := w.Freshness(time.Now(), time.Hour)
+	if !f.Stale {
+		t.Errorf("Expected weather without an update time to be stale")
+	}
+}
+
+func TestHoursFrom(t *testing.T) {
+	start := time.Date(2023, 5, 7, 10, 0, 0, 0, time.UTC)
+	w := &Weather{Hours: []Hour{{Time: start}, {Time: start.Add(time.Hour)}, {Time: start.Add(2 * time.Hour)}}}
+
+	// The hour that is running counts
+	hours := w.HoursFrom(start.Add(90 * time.Minute))
+	if len(hours) != 2 || !hours[0].Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the last two hours, but got %+v", hours)
+	}
+
+	if hours := w.HoursFrom(start.Add(3 * time.Hour)); len(hours) != 0 {
+		t.Errorf("Expected no hours after the forecast, but got %+v", hours)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package weather
+
+import (
+	"time"
+	// Embeds the time zone database, so Zurich is known on every host
+	_ "time/tzdata"
+)
+
+// Zurich is the time zone of Switzerland. The dates of the daily forecast are
+// Swiss dates, so times are grouped into days and shown in it on every host.
+var Zurich = mustLoadLocation("Europe/Zurich")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return loc
+}
@@ -0,0 +1,99 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package astro calculates the position of the sun and the moon and the times
+// of sunrise, twilight and moonrise for any date and place, without any API.
+//
+// The formulas are the ones of https://www.aa.quae.nl/en/reken/zonpositie.html
+// and https://www.aa.quae.nl/en/reken/hemelpositie.html, which are accurate to
+// about a minute for the sun and a few minutes for the moon.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	rad = math.Pi / 180
+	// Julian days of the unix epoch and of J2000.
+	j1970 = 2440588.0
+	j2000 = 2451545.0
+	// Obliquity of the earth.
+	obliquity = rad * 23.4397
+)
+
+// Returns the days since J2000 of the time.
+func toDays(t time.Time) float64 {
+	return float64(t.UnixMilli())/float64(24*time.Hour/time.Millisecond) - 0.5 + j1970 - j2000
+}
+
+// Returns the time of a Julian day, the zero time if it is not a number.
+func fromJulian(j float64) time.Time {
+	if math.IsNaN(j) {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(int64(math.Round((j + 0.5 - j1970) * float64(24*time.Hour/time.Millisecond))))
+}
+
+func rightAscension(l float64, b float64) float64 {
+	return math.Atan2(math.Sin(l)*math.Cos(obliquity)-math.Tan(b)*math.Sin(obliquity), math.Cos(l))
+}
+
+func declination(l float64, b float64) float64 {
+	return math.Asin(math.Sin(b)*math.Cos(obliquity) + math.Cos(b)*math.Sin(obliquity)*math.Sin(l))
+}
+
+func azimuth(h float64, phi float64, dec float64) float64 {
+	return math.Atan2(math.Sin(h), math.Cos(h)*math.Sin(phi)-math.Tan(dec)*math.Cos(phi))
+}
+
+func altitude(h float64, phi float64, dec float64) float64 {
+	return math.Asin(math.Sin(phi)*math.Sin(dec) + math.Cos(phi)*math.Cos(dec)*math.Cos(h))
+}
+
+func siderealTime(d float64, lw float64) float64 {
+	return rad*(280.16+360.9856235*d) - lw
+}
+
+// Returns the refraction of the atmosphere at the altitude.
+func refraction(h float64) float64 {
+	if h < 0 {
+		h = 0
+	}
+
+	return 0.0002967 / math.Tan(h+0.00312536/(h+0.08901179))
+}
+
+// Position is the position of a body in the sky.
+type Position struct {
+	// Degrees above the horizon, negative below it.
+	Elevation float64 `json:"elevation"`
+	// Degrees clockwise from north.
+	Azimuth float64 `json:"azimuth"`
+}
+
+// Converts the angles in radians to a position in degrees. The azimuth of the
+// formulas is measured from south.
+func newPosition(alt float64, az float64) Position {
+	return Position{
+		Elevation: alt / rad,
+		Azimuth:   math.Mod(az/rad+180, 360),
+	}
+}
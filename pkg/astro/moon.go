@@ -0,0 +1,203 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Distance of the sun from the earth in km.
+const sunDistance = 149598000.0
+
+// Names of the phases of the moon.
+const (
+	NewMoon        = "new moon"
+	WaxingCrescent = "waxing crescent"
+	FirstQuarter   = "first quarter"
+	WaxingGibbous  = "waxing gibbous"
+	FullMoon       = "full moon"
+	WaningGibbous  = "waning gibbous"
+	LastQuarter    = "last quarter"
+	WaningCrescent = "waning crescent"
+)
+
+// MoonTimes are the times of the moon of a day. The moon doesn't rise or set
+// on every day, those times are zero then.
+type MoonTimes struct {
+	Moonrise time.Time `json:"moonrise"`
+	Moonset  time.Time `json:"moonset"`
+	// Illuminated part of the moon from 0 to 1.
+	Illumination float64 `json:"illumination"`
+	// Phase from 0 to 1, 0 is new moon, 0.25 first quarter, 0.5 full moon and
+	// 0.75 last quarter.
+	Phase     float64 `json:"phase"`
+	PhaseName string  `json:"phaseName"`
+}
+
+// Returns the declination, the right ascension and the distance in km of the
+// moon.
+func moonCoords(d float64) (float64, float64, float64) {
+	// Ecliptic longitude, mean anomaly and mean distance
+	l := rad * (218.316 + 13.176396*d)
+	m := rad * (134.963 + 13.064993*d)
+	f := rad * (93.272 + 13.229350*d)
+
+	lon := l + rad*6.289*math.Sin(m)
+	lat := rad * 5.128 * math.Sin(f)
+	dist := 385001 - 20905*math.Cos(m)
+
+	return declination(lon, lat), rightAscension(lon, lat), dist
+}
+
+// Returns the position of the moon at the time and place, corrected for the
+// refraction of the atmosphere.
+func MoonPosition(t time.Time, lat float64, lon float64) Position {
+	lw := rad * -lon
+	phi := rad * lat
+	d := toDays(t)
+
+	dec, ra, _ := moonCoords(d)
+	h := siderealTime(d, lw) - ra
+	alt := altitude(h, phi, dec)
+
+	return newPosition(alt+refraction(alt), azimuth(h, phi, dec))
+}
+
+// Returns the illuminated part and the phase of the moon at the time.
+func MoonIllumination(t time.Time) (float64, float64) {
+	d := toDays(t)
+
+	sdec, sra := sunCoords(d)
+	mdec, mra, mdist := moonCoords(d)
+
+	// Elongation and phase angle
+	phi := math.Acos(math.Sin(sdec)*math.Sin(mdec) + math.Cos(sdec)*math.Cos(mdec)*math.Cos(sra-mra))
+	inc := math.Atan2(sunDistance*math.Sin(phi), mdist-sunDistance*math.Cos(phi))
+	angle := math.Atan2(math.Cos(sdec)*math.Sin(sra-mra),
+		math.Sin(sdec)*math.Cos(mdec)-math.Cos(sdec)*math.Sin(mdec)*math.Cos(sra-mra))
+
+	sign := 1.0
+	if angle < 0 {
+		sign = -1
+	}
+
+	return (1 + math.Cos(inc)) / 2, 0.5 + 0.5*inc*sign/math.Pi
+}
+
+// Returns the name of the phase.
+func PhaseName(phase float64) string {
+	names := []string{NewMoon, WaxingCrescent, FirstQuarter, WaxingGibbous, FullMoon, WaningGibbous, LastQuarter,
+		WaningCrescent}
+
+	// Each name covers an eighth centered on its phase
+	return names[int(math.Floor(phase*8+0.5))%8]
+}
+
+// Returns the times of the moon on the day of t in its location. The
+// illumination and the phase are the ones at noon.
+func Moon(t time.Time, lat float64, lon float64) MoonTimes {
+	y, mo, day := t.Date()
+	start := time.Date(y, mo, day, 0, 0, 0, 0, t.Location())
+	// Days with a change of daylight saving time have 23 or 25 hours
+	end := time.Date(y, mo, day+1, 0, 0, 0, 0, t.Location())
+	length := end.Sub(start).Hours()
+
+	at := func(hours float64) time.Time {
+		return start.Add(time.Duration(hours * float64(time.Hour)))
+	}
+
+	// Altitude of the center of the moon at rise and set, in radians
+	hc := 0.133 * rad
+	alt := func(hours float64) float64 {
+		return MoonPosition(at(hours), lat, lon).Elevation*rad - hc
+	}
+
+	m := MoonTimes{}
+
+	// Sets the time of the root if it is still on this day, the zero time
+	// can't be mistaken for midnight
+	found := func(event *time.Time, hours float64) {
+		if e := at(hours).Round(time.Second); e.Before(end) {
+			*event = e
+		}
+	}
+
+	h0 := alt(0)
+
+	// Fit a parabola through every three hours and look for its roots, up to
+	// the next midnight
+	for i := 1.0; i-1 < length; i += 2 {
+		h1 := alt(i)
+		h2 := alt(i + 1)
+
+		a := (h0+h2)/2 - h1
+		b := (h2 - h0) / 2
+		xe := -b / (2 * a)
+		ye := (a*xe+b)*xe + h1
+		disc := b*b - 4*a*h1
+
+		roots := 0
+
+		var x1, x2 float64
+
+		if disc >= 0 {
+			dx := math.Sqrt(disc) / (math.Abs(a) * 2)
+			x1 = xe - dx
+			x2 = xe + dx
+
+			if math.Abs(x1) <= 1 {
+				roots++
+			}
+
+			if math.Abs(x2) <= 1 {
+				roots++
+			}
+
+			if x1 < -1 {
+				x1 = x2
+			}
+		}
+
+		switch {
+		case roots == 1 && h0 < 0:
+			found(&m.Moonrise, i+x1)
+		case roots == 1:
+			found(&m.Moonset, i+x1)
+		case roots == 2 && ye < 0:
+			found(&m.Moonrise, i+x2)
+			found(&m.Moonset, i+x1)
+		case roots == 2:
+			found(&m.Moonrise, i+x1)
+			found(&m.Moonset, i+x2)
+		}
+
+		if !m.Moonrise.IsZero() && !m.Moonset.IsZero() {
+			break
+		}
+
+		h0 = h2
+	}
+
+	noon := time.Date(y, mo, day, 12, 0, 0, 0, t.Location())
+	m.Illumination, m.Phase = MoonIllumination(noon)
+	m.PhaseName = PhaseName(m.Phase)
+
+	return m
+}
@@ -0,0 +1,105 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package astro
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMoonIllumination(t *testing.T) {
+	tests := []struct {
+		time         time.Time
+		illumination float64
+		name         string
+	}{
+		{time.Date(2023, 5, 5, 17, 34, 0, 0, time.UTC), 1, FullMoon},
+		{time.Date(2023, 5, 19, 15, 53, 0, 0, time.UTC), 0, NewMoon},
+		{time.Date(2023, 5, 27, 15, 22, 0, 0, time.UTC), 0.5, FirstQuarter},
+		{time.Date(2023, 5, 12, 14, 28, 0, 0, time.UTC), 0.5, LastQuarter},
+	}
+
+	for _, tt := range tests {
+		i, phase := MoonIllumination(tt.time)
+
+		if math.Abs(i-tt.illumination) > 0.02 {
+			t.Errorf("Expected illumination of %s to be %.2f, but got %.2f", tt.name, tt.illumination, i)
+		}
+
+		if n := PhaseName(phase); n != tt.name {
+			t.Errorf("Expected phase %.2f to be %s, but got %s", phase, tt.name, n)
+		}
+	}
+}
+
+func TestMoon(t *testing.T) {
+	loc := zurich(t)
+
+	m := Moon(time.Date(2023, 5, 5, 0, 0, 0, 0, loc), bernLatitude, bernLongitude)
+
+	if m.PhaseName != FullMoon {
+		t.Errorf("Expected full moon, but got %s", m.PhaseName)
+	}
+
+	// The full moon rises around sunset and sets around sunrise
+	expectClock(t, "moonrise", m.Moonrise, time.Date(2023, 5, 5, 20, 35, 0, 0, loc), 15*time.Minute)
+	expectClock(t, "moonset", m.Moonset, time.Date(2023, 5, 5, 5, 55, 0, 0, loc), 15*time.Minute)
+
+	for _, rs := range []time.Time{m.Moonrise, m.Moonset} {
+		p := MoonPosition(rs, bernLatitude, bernLongitude)
+
+		if math.Abs(p.Elevation-0.133) > 0.2 {
+			t.Errorf("Expected the moon on the horizon at %s, but got %.2f°", rs.Format("15:04"), p.Elevation)
+		}
+	}
+}
+
+func TestMoonWithinDay(t *testing.T) {
+	loc := zurich(t)
+
+	// Every day of 40 years, including the ones with 23 and 25 hours
+	for d := time.Date(2000, 1, 1, 0, 0, 0, 0, loc); d.Year() < 2040; d = d.AddDate(0, 0, 1) {
+		m := Moon(d, bernLatitude, bernLongitude)
+		end := d.AddDate(0, 0, 1)
+
+		for _, rs := range []time.Time{m.Moonrise, m.Moonset} {
+			if !rs.IsZero() && (rs.Before(d) || !rs.Before(end)) {
+				t.Errorf("Expected the moon times of %s within the day, but got %s", d.Format("2006-01-02"), rs)
+			}
+		}
+	}
+}
+
+func TestPhaseName(t *testing.T) {
+	tests := map[float64]string{
+		0:    NewMoon,
+		0.97: NewMoon,
+		0.1:  WaxingCrescent,
+		0.5:  FullMoon,
+		0.7:  LastQuarter,
+		0.85: WaningCrescent,
+	}
+
+	for phase, name := range tests {
+		if n := PhaseName(phase); n != name {
+			t.Errorf("Expected phase %.2f to be %s, but got %s", phase, name, n)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// Elevations of the sun in degrees that start and end the phases of the day.
+const (
+	// Upper edge of the sun on the horizon, with refraction.
+	SunriseElevation      = -0.833
+	CivilElevation        = -6.0
+	NauticalElevation     = -12.0
+	AstronomicalElevation = -18.0
+	// The golden hour lasts from -4° to 6°, the blue hour from -6° to -4°.
+	GoldenHourElevation = 6.0
+	BlueHourElevation   = -4.0
+)
+
+// Correction of the Julian cycle.
+const j0 = 0.0009
+
+// Period is a span of time, both zero if the sun doesn't get there that day.
+// If the sun doesn't leave it during the night or the day, it starts or ends
+// at solar midnight or noon.
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// SunTimes are the times of the sun of a day. Times the sun doesn't reach on
+// that day, like the night in the summer far north, are zero.
+type SunTimes struct {
+	SolarNoon time.Time `json:"solarNoon"`
+	// Elevation of the sun at solar noon in degrees.
+	NoonElevation     float64   `json:"noonElevation"`
+	Sunrise           time.Time `json:"sunrise"`
+	Sunset            time.Time `json:"sunset"`
+	CivilDawn         time.Time `json:"civilDawn"`
+	CivilDusk         time.Time `json:"civilDusk"`
+	NauticalDawn      time.Time `json:"nauticalDawn"`
+	NauticalDusk      time.Time `json:"nauticalDusk"`
+	AstronomicalDawn  time.Time `json:"astronomicalDawn"`
+	AstronomicalDusk  time.Time `json:"astronomicalDusk"`
+	BlueHourMorning   Period    `json:"blueHourMorning"`
+	GoldenHourMorning Period    `json:"goldenHourMorning"`
+	GoldenHourEvening Period    `json:"goldenHourEvening"`
+	BlueHourEvening   Period    `json:"blueHourEvening"`
+}
+
+func solarMeanAnomaly(d float64) float64 {
+	return rad * (357.5291 + 0.98560028*d)
+}
+
+func eclipticLongitude(m float64) float64 {
+	// Equation of center and perihelion of the earth
+	c := rad * (1.9148*math.Sin(m) + 0.02*math.Sin(2*m) + 0.0003*math.Sin(3*m))
+	p := rad * 102.9372
+
+	return m + c + p + math.Pi
+}
+
+// Returns the declination and the right ascension of the sun.
+func sunCoords(d float64) (float64, float64) {
+	l := eclipticLongitude(solarMeanAnomaly(d))
+
+	return declination(l, 0), rightAscension(l, 0)
+}
+
+// Returns the position of the sun at the time and place.
+func SunPosition(t time.Time, lat float64, lon float64) Position {
+	lw := rad * -lon
+	phi := rad * lat
+	d := toDays(t)
+
+	dec, ra := sunCoords(d)
+	h := siderealTime(d, lw) - ra
+
+	return newPosition(altitude(h, phi, dec), azimuth(h, phi, dec))
+}
+
+// Returns the times of the sun on the day of t in its location.
+func Sun(t time.Time, lat float64, lon float64) SunTimes {
+	// Start from noon to get the transit of this day
+	y, m, day := t.Date()
+	noon := time.Date(y, m, day, 12, 0, 0, 0, t.Location())
+
+	lw := rad * -lon
+	phi := rad * lat
+	d := toDays(noon)
+
+	n := math.Round(d - j0 - lw/(2*math.Pi))
+	ds := j0 + lw/(2*math.Pi) + n
+
+	ma := solarMeanAnomaly(ds)
+	l := eclipticLongitude(ma)
+	dec := declination(l, 0)
+
+	transit := func(ha float64) float64 {
+		a := j0 + (ha+lw)/(2*math.Pi) + n
+
+		return j2000 + a + 0.0053*math.Sin(ma) - 0.0069*math.Sin(2*l)
+	}
+
+	jnoon := transit(0)
+
+	// Returns the times the sun rises to and sets below the elevation
+	times := func(elevation float64) (time.Time, time.Time) {
+		ha := math.Acos((math.Sin(elevation*rad) - math.Sin(phi)*math.Sin(dec)) / (math.Cos(phi) * math.Cos(dec)))
+		set := transit(ha)
+
+		return in(fromJulian(jnoon-(set-jnoon)), t.Location()), in(fromJulian(set), t.Location())
+	}
+
+	s := SunTimes{
+		SolarNoon:     in(fromJulian(jnoon), t.Location()),
+		NoonElevation: SunPosition(fromJulian(jnoon), lat, lon).Elevation,
+	}
+
+	s.Sunrise, s.Sunset = times(SunriseElevation)
+	s.CivilDawn, s.CivilDusk = times(CivilElevation)
+	s.NauticalDawn, s.NauticalDusk = times(NauticalElevation)
+	s.AstronomicalDawn, s.AstronomicalDusk = times(AstronomicalElevation)
+
+	// Returns the periods in the morning and the evening when the sun is
+	// between the elevations. If the sun stays above low, they start and end
+	// at solar midnight, if it stays below high, they meet at solar noon.
+	phase := func(low float64, high float64) (Period, Period) {
+		if s.NoonElevation < low {
+			return Period{}, Period{}
+		}
+
+		lowStart, lowEnd := times(low)
+		if lowStart.IsZero() {
+			lowStart = in(fromJulian(jnoon-0.5), t.Location())
+			lowEnd = in(fromJulian(jnoon+0.5), t.Location())
+		}
+
+		highStart, highEnd := times(high)
+		if highStart.IsZero() {
+			if s.NoonElevation >= high {
+				return Period{}, Period{}
+			}
+
+			highStart, highEnd = s.SolarNoon, s.SolarNoon
+		}
+
+		return Period{Start: lowStart, End: highStart}, Period{Start: highEnd, End: lowEnd}
+	}
+
+	s.BlueHourMorning, s.BlueHourEvening = phase(CivilElevation, BlueHourElevation)
+	s.GoldenHourMorning, s.GoldenHourEvening = phase(BlueHourElevation, GoldenHourElevation)
+
+	return s
+}
+
+// Returns the time in the location, keeping the zero time as is.
+func in(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	return t.In(loc)
+}
@@ -0,0 +1,142 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package astro
+
+import (
+	"math"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// Coordinates of Bern.
+const (
+	bernLatitude  = 46.95
+	bernLongitude = 7.45
+)
+
+func zurich(t *testing.T) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return loc
+}
+
+// Checks that the time is within the tolerance of the expected clock time.
+func expectClock(t *testing.T, name string, got time.Time, expected time.Time, tolerance time.Duration) {
+	t.Helper()
+
+	if d := got.Sub(expected); d > tolerance || d < -tolerance {
+		t.Errorf("Expected %s to be %s, but got %s", name, expected.Format("15:04"), got.Format("15:04:05"))
+	}
+}
+
+func TestSun(t *testing.T) {
+	loc := zurich(t)
+	at := func(h int, m int) time.Time { return time.Date(2023, 6, 21, h, m, 0, 0, loc) }
+
+	s := Sun(time.Date(2023, 6, 21, 8, 0, 0, 0, loc), bernLatitude, bernLongitude)
+
+	// Times of timeanddate.com for Bern on the summer solstice
+	expectClock(t, "sunrise", s.Sunrise, at(5, 35), 2*time.Minute)
+	expectClock(t, "sunset", s.Sunset, at(21, 29), 2*time.Minute)
+	expectClock(t, "solar noon", s.SolarNoon, at(13, 32), 2*time.Minute)
+	expectClock(t, "civil dawn", s.CivilDawn, at(4, 56), 2*time.Minute)
+	expectClock(t, "nautical dusk", s.NauticalDusk, at(23, 4), 2*time.Minute)
+
+	if math.Abs(s.NoonElevation-66.5) > 0.2 {
+		t.Errorf("Expected noon elevation to be 66.5°, but got %.1f°", s.NoonElevation)
+	}
+
+	if !s.BlueHourMorning.Start.Equal(s.CivilDawn) || !s.BlueHourMorning.End.Equal(s.GoldenHourMorning.Start) {
+		t.Errorf("Expected the blue hour to last from civil dawn to the golden hour, but got %+v", s.BlueHourMorning)
+	}
+
+	if !s.GoldenHourEvening.End.After(s.Sunset) || !s.GoldenHourEvening.Start.Before(s.Sunset) {
+		t.Errorf("Expected the golden hour to span the sunset, but got %+v", s.GoldenHourEvening)
+	}
+}
+
+func TestSunWithoutNight(t *testing.T) {
+	// The sun stays above -18° in Kiel around the summer solstice
+	s := Sun(time.Date(2023, 6, 21, 12, 0, 0, 0, zurich(t)), 54.32, 10.14)
+
+	if !s.AstronomicalDawn.IsZero() || !s.AstronomicalDusk.IsZero() {
+		t.Errorf("Expected no astronomical twilight, but got %s and %s", s.AstronomicalDawn, s.AstronomicalDusk)
+	}
+
+	if s.Sunrise.IsZero() || s.NauticalDusk.IsZero() {
+		t.Errorf("Expected sunrise and nautical dusk, but got %+v", s)
+	}
+}
+
+func TestSunOneSidedHours(t *testing.T) {
+	loc := zurich(t)
+
+	// At midnight in summer the sun stays between -4° and 6° in Tromsø, the
+	// golden hour lasts through the night
+	s := Sun(time.Date(2023, 6, 21, 12, 0, 0, 0, loc), 69.65, 18.96)
+
+	if !s.BlueHourMorning.Start.IsZero() || !s.BlueHourEvening.Start.IsZero() {
+		t.Errorf("Expected no blue hour, but got %+v and %+v", s.BlueHourMorning, s.BlueHourEvening)
+	}
+
+	if d := s.GoldenHourEvening.End.Sub(s.GoldenHourMorning.Start); d < 23*time.Hour || d > 25*time.Hour {
+		t.Errorf("Expected the golden hours to start and end at solar midnight, but got %+v and %+v",
+			s.GoldenHourMorning, s.GoldenHourEvening)
+	}
+
+	if !s.GoldenHourMorning.End.Before(s.SolarNoon) || !s.GoldenHourEvening.Start.After(s.SolarNoon) {
+		t.Errorf("Expected the sun above 6° around noon, but got %+v and %+v", s.GoldenHourMorning, s.GoldenHourEvening)
+	}
+
+	// In winter the sun stays below 6°, the golden hours meet at noon
+	s = Sun(time.Date(2023, 12, 21, 12, 0, 0, 0, loc), 69.65, 18.96)
+
+	if !s.GoldenHourMorning.End.Equal(s.SolarNoon) || !s.GoldenHourEvening.Start.Equal(s.SolarNoon) {
+		t.Errorf("Expected the golden hours to meet at solar noon, but got %+v and %+v",
+			s.GoldenHourMorning, s.GoldenHourEvening)
+	}
+
+	if s.BlueHourMorning.Start.IsZero() || !s.BlueHourMorning.End.Equal(s.GoldenHourMorning.Start) {
+		t.Errorf("Expected the blue hour before the golden hour, but got %+v", s.BlueHourMorning)
+	}
+}
+
+func TestSunPosition(t *testing.T) {
+	loc := zurich(t)
+	s := Sun(time.Date(2023, 12, 21, 12, 0, 0, 0, loc), bernLatitude, bernLongitude)
+
+	p := SunPosition(s.SolarNoon, bernLatitude, bernLongitude)
+
+	// At noon the sun is in the south, at 90° - latitude - 23.44°
+	if math.Abs(p.Azimuth-180) > 0.5 || math.Abs(p.Elevation-19.6) > 0.3 {
+		t.Errorf("Expected the sun in the south at 19.6°, but got %+v", p)
+	}
+
+	// The sun rises slowly in winter, a minute is about 0.15°
+	sunrise := SunPosition(s.Sunrise, bernLatitude, bernLongitude)
+	if math.Abs(sunrise.Elevation-SunriseElevation) > 0.3 {
+		t.Errorf("Expected the sun at %.3f° at sunrise, but got %.3f°", SunriseElevation, sunrise.Elevation)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package derived calculates quantities like the wind chill, the heat index and
+// the dew point from the temperature, the relative humidity and the wind
+// speed. Temperatures are in °C, humidities in % and wind speeds in km/h.
+package derived
+
+import "math"
+
+const (
+	// Highest temperature and lowest wind speed the wind chill is defined for.
+	windChillMaxTemperature = 10.0
+	windChillMinWindSpeed   = 4.8
+	// Lowest temperature the heat index is defined for, 80 °F.
+	heatIndexMinTemperature = 26.7
+)
+
+// Quantities are the quantities derived from the weather at a time. The ones
+// that can't be calculated are nil.
+type Quantities struct {
+	// Temperature as felt by people, the wind chill when it is cold and windy,
+	// the heat index when it is hot and the temperature otherwise.
+	FeelsLike           float64  `json:"feelsLike"`
+	WindChill           *float64 `json:"windChill,omitempty"`
+	HeatIndex           *float64 `json:"heatIndex,omitempty"`
+	Humidex             *float64 `json:"humidex,omitempty"`
+	DewPoint            *float64 `json:"dewPoint,omitempty"`
+	ApparentTemperature *float64 `json:"apparentTemperature,omitempty"`
+}
+
+// Derives all quantities. A humidity of zero or less is treated as unknown,
+// which leaves out the quantities that need it.
+func Derive(temperature float64, humidity float64, windSpeed float64) Quantities {
+	q := Quantities{FeelsLike: round(temperature)}
+
+	if wc, ok := WindChill(temperature, windSpeed); ok {
+		q.WindChill = ptr(wc)
+		q.FeelsLike = round(wc)
+	}
+
+	if humidity <= 0 {
+		return q
+	}
+
+	dp := DewPoint(temperature, humidity)
+
+	q.DewPoint = ptr(dp)
+	q.Humidex = ptr(Humidex(temperature, dp))
+	q.ApparentTemperature = ptr(ApparentTemperature(temperature, humidity, windSpeed))
+
+	if hi, ok := HeatIndex(temperature, humidity); ok {
+		q.HeatIndex = ptr(hi)
+		q.FeelsLike = round(hi)
+	}
+
+	return q
+}
+
+// Returns the wind chill of the formula used in Canada and the US since 2001.
+// It is only defined up to 10 °C and from 4.8 km/h, false otherwise.
+func WindChill(temperature float64, windSpeed float64) (float64, bool) {
+	if temperature > windChillMaxTemperature || windSpeed < windChillMinWindSpeed {
+		return temperature, false
+	}
+
+	v := math.Pow(windSpeed, 0.16)
+
+	return 13.12 + 0.6215*temperature - 11.37*v + 0.3965*temperature*v, true
+}
+
+// Returns the heat index of the US National Weather Service. It is only
+// defined from 26.7 °C, false otherwise.
+func HeatIndex(temperature float64, humidity float64) (float64, bool) {
+	if temperature < heatIndexMinTemperature {
+		return temperature, false
+	}
+
+	t := temperature*9/5 + 32
+	rh := humidity
+
+	// Regression of Rothfusz
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh - 0.22475541*t*rh - 0.00683783*t*t -
+		0.05481717*rh*rh + 0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	// Adjustments for dry and for very humid air
+	switch {
+	case rh < 13 && t <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t <= 87:
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+
+	return (hi - 32) * 5 / 9, true
+}
+
+// Returns the dew point of the Magnus formula.
+func DewPoint(temperature float64, humidity float64) float64 {
+	const a, b = 17.625, 243.04
+
+	g := math.Log(humidity/100) + a*temperature/(b+temperature)
+
+	return b * g / (a - g)
+}
+
+// Returns the humidex of Environment Canada from the temperature and the dew
+// point.
+func Humidex(temperature float64, dewPoint float64) float64 {
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dewPoint)))
+
+	return temperature + 0.5555*(e-10)
+}
+
+// Returns the apparent temperature of Steadman as used by the Australian
+// Bureau of Meteorology, without the effect of the sun.
+func ApparentTemperature(temperature float64, humidity float64, windSpeed float64) float64 {
+	// Water vapour pressure in hPa
+	e := humidity / 100 * 6.105 * math.Exp(17.27*temperature/(237.7+temperature))
+
+	return temperature + 0.33*e - 0.70*windSpeed/3.6 - 4.00
+}
+
+// Rounds to one decimal, the precision of the inputs.
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func ptr(v float64) *float64 {
+	r := round(v)
+
+	return &r
+}
@@ -0,0 +1,125 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package derived
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWindChill(t *testing.T) {
+	tests := []struct {
+		temperature float64
+		windSpeed   float64
+		want        float64
+		ok          bool
+	}{
+		// Values of the table of Environment Canada
+		{-10, 20, -17.9, true},
+		{-2, 30, -9.1, true},
+		{0, 5, -1.6, true},
+		{15, 30, 15, false},
+		{-5, 2, -5, false},
+	}
+
+	for _, tt := range tests {
+		wc, ok := WindChill(tt.temperature, tt.windSpeed)
+
+		if ok != tt.ok || math.Abs(wc-tt.want) > 0.1 {
+			t.Errorf("Expected wind chill of %.0f °C at %.0f km/h to be %.1f (%t), but got %.1f (%t)",
+				tt.temperature, tt.windSpeed, tt.want, tt.ok, wc, ok)
+		}
+	}
+}
+
+func TestHeatIndex(t *testing.T) {
+	tests := []struct {
+		temperature float64
+		humidity    float64
+		want        float64
+		ok          bool
+	}{
+		// 90 °F at 60 % is 100 °F in the table of the NWS
+		{32.2, 60, 37.8, true},
+		// 86 °F at 90 % is 105 °F
+		{30, 90, 40.6, true},
+		{20, 80, 20, false},
+	}
+
+	for _, tt := range tests {
+		hi, ok := HeatIndex(tt.temperature, tt.humidity)
+
+		if ok != tt.ok || math.Abs(hi-tt.want) > 0.6 {
+			t.Errorf("Expected heat index of %.1f °C at %.0f %% to be %.1f (%t), but got %.1f (%t)",
+				tt.temperature, tt.humidity, tt.want, tt.ok, hi, ok)
+		}
+	}
+}
+
+func TestDewPoint(t *testing.T) {
+	if dp := DewPoint(20, 50); math.Abs(dp-9.3) > 0.1 {
+		t.Errorf("Expected dew point of 20 °C at 50 %% to be 9.3 °C, but got %.1f", dp)
+	}
+
+	if dp := DewPoint(15, 100); math.Abs(dp-15) > 0.01 {
+		t.Errorf("Expected dew point at 100 %% to be the temperature, but got %.2f", dp)
+	}
+}
+
+func TestHumidex(t *testing.T) {
+	// 30 °C with a dew point of 15 °C is 34 in the table of Environment Canada
+	if h := Humidex(30, 15); math.Abs(h-34) > 0.5 {
+		t.Errorf("Expected humidex to be 34, but got %.1f", h)
+	}
+}
+
+func TestApparentTemperature(t *testing.T) {
+	// 25 °C, 50 % and 3 m/s
+	if at := ApparentTemperature(25, 50, 10.8); math.Abs(at-24.1) > 0.1 {
+		t.Errorf("Expected apparent temperature to be 24.1 °C, but got %.1f", at)
+	}
+}
+
+func TestDerive(t *testing.T) {
+	cold := Derive(-2, 0, 30)
+
+	if cold.FeelsLike != -9.1 || cold.WindChill == nil {
+		t.Errorf("Expected to feel like -9.1 °C from the wind chill, but got %+v", cold)
+	}
+
+	if cold.DewPoint != nil || cold.HeatIndex != nil || cold.Humidex != nil {
+		t.Errorf("Expected no humidity based quantities without humidity, but got %+v", cold)
+	}
+
+	hot := Derive(32.2, 60, 5)
+
+	if hot.HeatIndex == nil || hot.FeelsLike != *hot.HeatIndex || hot.WindChill != nil {
+		t.Errorf("Expected to feel like the heat index, but got %+v", hot)
+	}
+
+	if hot.DewPoint == nil || hot.Humidex == nil || hot.ApparentTemperature == nil {
+		t.Errorf("Expected the humidity based quantities, but got %+v", hot)
+	}
+
+	mild := Derive(18, 50, 10)
+
+	if mild.FeelsLike != 18 {
+		t.Errorf("Expected mild weather to feel like the temperature, but got %.1f", mild.FeelsLike)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+import "time"
+
+// DefaultMaxAge is the age after which the current weather is considered stale.
+// MeteoSwiss refreshes the current weather at least once an hour, so anything
+// older than that plus some slack is not current anymore.
+const DefaultMaxAge = 90 * time.Minute
+
+// Freshness describes how old the current weather data is.
+type Freshness struct {
+	UpdatedAt     time.Time `json:"updatedAt"`
+	AgeSeconds    int64     `json:"ageSeconds"`
+	MaxAgeSeconds int64     `json:"maxAgeSeconds"`
+	Stale         bool      `json:"stale"`
+}
+
+// Returns the time of the last update of the current weather.
+func (w *Weather) UpdatedAt() time.Time {
+	// The API returns the time in milliseconds since epoch
+	return time.UnixMilli(w.CurrentWeather.Time)
+}
+
+// Evaluates the age of the current weather at the given time. A maxAge of zero
+// or less falls back to DefaultMaxAge.
+func (w *Weather) Freshness(now time.Time, maxAge time.Duration) Freshness {
+	f := NewFreshness(w.UpdatedAt(), now, maxAge)
+
+	// A missing time means there is no current weather
+	f.Stale = f.Stale || w.CurrentWeather.Time == 0
+
+	return f
+}
+
+// Evaluates the age of weather updated at the given time. A zero time is
+// always stale, a maxAge of zero or less falls back to DefaultMaxAge.
+func NewFreshness(updatedAt time.Time, now time.Time, maxAge time.Duration) Freshness {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	age := now.Sub(updatedAt)
+
+	// Data from the future is treated as brand new
+	if age < 0 {
+		age = 0
+	}
+
+	return Freshness{
+		UpdatedAt:     updatedAt,
+		AgeSeconds:    int64(age / time.Second),
+		MaxAgeSeconds: int64(maxAge / time.Second),
+		Stale:         updatedAt.IsZero() || age > maxAge,
+	}
+}
+
+// Returns the age as a duration.
+func (f Freshness) Age() time.Duration {
+	return time.Duration(f.AgeSeconds) * time.Second
+}
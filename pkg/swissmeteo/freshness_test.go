@@ -0,0 +1,76 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreshness(t *testing.T) {
+	w := &Weather{}
+	w.CurrentWeather.Time = 1683452400000
+
+	updatedAt := time.UnixMilli(1683452400000)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		maxAge    time.Duration
+		wantStale bool
+		wantAge   time.Duration
+	}{
+		{"fresh", updatedAt.Add(20 * time.Minute), time.Hour, false, 20 * time.Minute},
+		{"stale", updatedAt.Add(2 * time.Hour), time.Hour, true, 2 * time.Hour},
+		{"default max age", updatedAt.Add(time.Hour), 0, false, time.Hour},
+		{"future", updatedAt.Add(-time.Minute), time.Hour, false, 0},
+	}
+
+	for _, tt := range tests {
+		f := w.Freshness(tt.now, tt.maxAge)
+
+		if f.Stale != tt.wantStale {
+			t.Errorf("%s: expected stale to be %t, but got %t", tt.name, tt.wantStale, f.Stale)
+		}
+
+		if f.Age() != tt.wantAge {
+			t.Errorf("%s: expected age to be %s, but got %s", tt.name, tt.wantAge, f.Age())
+		}
+
+		if !f.UpdatedAt.Equal(updatedAt) {
+			t.Errorf("%s: expected updatedAt to be %s, but got %s", tt.name, updatedAt, f.UpdatedAt)
+		}
+	}
+}
+
+func TestFreshnessWithoutData(t *testing.T) {
+	w := &Weather{}
+
+	f := w.Freshness(time.Now(), time.Hour)
+	if !f.Stale {
+		t.Errorf("Expected weather without a timestamp to be stale")
+	}
+}
+
+func TestNewFreshnessWithoutUpdate(t *testing.T) {
+	f := NewFreshness(time.Time{}, time.Now(), time.Hour)
+	if !f.Stale {
+		t.Errorf("Expected weather without an update time to be stale")
+	}
+}
@@ -32,11 +32,17 @@ const (
 
 // Gets the weather data from the API and decodes it into the Weather struct.
 func (w *Weather) getWeatherData(zip string) error {
+	return w.GetWeatherData(context.Background(), zip)
+}
+
+// Gets the weather data from the API and decodes it into the Weather struct. The
+// request is cancelled when the given context is done.
+func (w *Weather) GetWeatherData(ctx context.Context, zip string) error {
 	// The meteoswiss API only accepts a zip code with a tailing 00
 	z := fmt.Sprintf("%s00", zip)
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 
 	defer cancel()
 
@@ -60,6 +66,10 @@ func (w *Weather) getWeatherData(zip string) error {
 	// Close the body when we're done with it
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from weather API: %d", resp.StatusCode)
+	}
+
 	// Decode the JSON respons
 	err = json.NewDecoder(resp.Body).Decode(&w)
 	if err != nil {
@@ -80,6 +90,16 @@ func (w *Weather) GetCurrentTemperature(zip string) (temperature float64, update
 	return w.CurrentWeather.Temperature, w.CurrentWeather.Time, nil
 }
 
+// Day is the forecast of a single day.
+type Day struct {
+	DayDate        string  `json:"dayDate"`
+	IconDay        int     `json:"iconDay"`
+	IconDayV2      int     `json:"iconDayV2"`
+	TemperatureMax int     `json:"temperatureMax"`
+	TemperatureMin int     `json:"temperatureMin"`
+	Precipitation  float64 `json:"precipitation"`
+}
+
 type Weather struct {
 	CurrentWeather struct {
 		Time        int64   `json:"time"`
@@ -87,16 +107,9 @@ type Weather struct {
 		IconV2      int     `json:"iconV2"`
 		Temperature float64 `json:"temperature"`
 	} `json:"currentWeather"`
-	Forecast []struct {
-		DayDate        string  `json:"dayDate"`
-		IconDay        int     `json:"iconDay"`
-		IconDayV2      int     `json:"iconDayV2"`
-		TemperatureMax int     `json:"temperatureMax"`
-		TemperatureMin int     `json:"temperatureMin"`
-		Precipitation  float64 `json:"precipitation"`
-	} `json:"forecast"`
-	Warnings         []any `json:"warnings"`
-	WarningsOverview []any `json:"warningsOverview"`
+	Forecast         []Day     `json:"forecast"`
+	Warnings         []Warning `json:"warnings"`
+	WarningsOverview []any     `json:"warningsOverview"`
 	Graph            struct {
 		Start               int64     `json:"start"`
 		StartLowResolution  int64     `json:"startLowResolution"`
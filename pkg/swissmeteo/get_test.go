@@ -1499,6 +1499,10 @@ func TestGetWeatherData(t *testing.T) {
 		t.Errorf("Expected time to be %d, but got %d", expectedTime, w.CurrentWeather.Time)
 	}
 
+	if len(w.Warnings) != 1 || w.Warnings[0].WarnLevel != 2 || w.Warnings[0].Type() != "rain" {
+		t.Errorf("Expected a rain warning of level 2, but got %+v", w.Warnings)
+	}
+
 }
 
 type mockTransport struct {
@@ -0,0 +1,107 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+import (
+	"math"
+	"time"
+)
+
+// Hour holds the graph values of a single hour.
+type Hour struct {
+	Time             time.Time `json:"time"`
+	Icon             int       `json:"icon"`
+	TemperatureMean  float64   `json:"temperatureMean"`
+	TemperatureMin   float64   `json:"temperatureMin"`
+	TemperatureMax   float64   `json:"temperatureMax"`
+	Precipitation    float64   `json:"precipitation"`
+	PrecipitationMin float64   `json:"precipitationMin"`
+	PrecipitationMax float64   `json:"precipitationMax"`
+	WindSpeed        float64   `json:"windSpeed"`
+	WindDirection    int       `json:"windDirection"`
+}
+
+// Returns the graph as one entry per hour.
+//
+// The graph mixes series of different resolutions: the temperature is hourly
+// from Start, the precipitation is in 10 minute steps from Start and hourly
+// from StartLowResolution, and the wind and icons are in 3 hour steps. This
+// lines them all up on the hours of the temperature series.
+func (w *Weather) Hours() []Hour {
+	g := w.Graph
+
+	start := time.UnixMilli(g.Start)
+	lowResolution := time.UnixMilli(g.StartLowResolution)
+
+	hours := make([]Hour, 0, len(g.TemperatureMean1H))
+
+	for i := range g.TemperatureMean1H {
+		h := Hour{
+			Time:            start.Add(time.Duration(i) * time.Hour),
+			TemperatureMean: g.TemperatureMean1H[i],
+			TemperatureMin:  valueAt(g.TemperatureMin1H, i),
+			TemperatureMax:  valueAt(g.TemperatureMax1H, i),
+			WindSpeed:       valueAt(g.WindSpeed3H, i/3),
+		}
+
+		if i/3 < len(g.WindDirection3H) {
+			h.WindDirection = g.WindDirection3H[i/3]
+		}
+
+		if i/3 < len(g.WeatherIcon3H) {
+			h.Icon = g.WeatherIcon3H[i/3]
+		}
+
+		if h.Time.Before(lowResolution) {
+			// Sum up the six 10 minute values of this hour
+			for j := i * 6; j < (i+1)*6; j++ {
+				h.Precipitation += valueAt(g.Precipitation10M, j)
+				h.PrecipitationMin += valueAt(g.PrecipitationMin10M, j)
+				h.PrecipitationMax += valueAt(g.PrecipitationMax10M, j)
+			}
+
+			h.Precipitation = round(h.Precipitation)
+			h.PrecipitationMin = round(h.PrecipitationMin)
+			h.PrecipitationMax = round(h.PrecipitationMax)
+		} else {
+			j := int(h.Time.Sub(lowResolution) / time.Hour)
+			h.Precipitation = valueAt(g.Precipitation1H, j)
+			h.PrecipitationMin = valueAt(g.PrecipitationMin1H, j)
+			h.PrecipitationMax = valueAt(g.PrecipitationMax1H, j)
+		}
+
+		hours = append(hours, h)
+	}
+
+	return hours
+}
+
+// Returns the value at the given index or zero if the series is too short.
+func valueAt(s []float64, i int) float64 {
+	if i < 0 || i >= len(s) {
+		return 0
+	}
+
+	return s[i]
+}
+
+// Rounds sums of precipitation values to the precision of the API.
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
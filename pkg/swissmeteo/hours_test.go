@@ -0,0 +1,64 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHours(t *testing.T) {
+	w := &Weather{}
+	w.Graph.Start = 1683410400000
+	w.Graph.StartLowResolution = w.Graph.Start + 2*3600*1000
+	w.Graph.TemperatureMean1H = []float64{14.1, 13.7, 13.3, 12.6}
+	w.Graph.TemperatureMin1H = []float64{14.0, 13.5, 13.1, 12.2}
+	w.Graph.TemperatureMax1H = []float64{14.2, 13.9, 13.5, 12.9}
+	w.Graph.Precipitation10M = []float64{0.1, 0.1, 0.1, 0.2, 0, 0, 0.5, 0, 0, 0, 0, 0}
+	w.Graph.Precipitation1H = []float64{1.1, 0.3}
+	w.Graph.WindSpeed3H = []float64{10.6, 8.1}
+	w.Graph.WindDirection3H = []int{83, 120}
+
+	hours := w.Hours()
+
+	if len(hours) != 4 {
+		t.Fatalf("Expected 4 hours, but got %d", len(hours))
+	}
+
+	expectedTime := time.UnixMilli(1683410400000).Add(3 * time.Hour)
+	if !hours[3].Time.Equal(expectedTime) {
+		t.Errorf("Expected time to be %s, but got %s", expectedTime, hours[3].Time)
+	}
+
+	expectedPrecipitation := []float64{0.5, 0.5, 1.1, 0.3}
+	for i, p := range expectedPrecipitation {
+		if hours[i].Precipitation != p {
+			t.Errorf("Expected precipitation of hour %d to be %.1f, but got %.1f", i, p, hours[i].Precipitation)
+		}
+	}
+
+	if hours[2].WindSpeed != 10.6 || hours[3].WindSpeed != 8.1 {
+		t.Errorf("Expected wind speed to follow the 3 hour steps, but got %.1f and %.1f",
+			hours[2].WindSpeed, hours[3].WindSpeed)
+	}
+
+	if hours[1].TemperatureMax != 13.9 {
+		t.Errorf("Expected maximum temperature to be 13.9, but got %.1f", hours[1].TemperatureMax)
+	}
+}
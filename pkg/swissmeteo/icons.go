@@ -0,0 +1,142 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+// Condition groups the weather icons of MeteoSwiss.
+type Condition string
+
+const (
+	ConditionClear        Condition = "clear"
+	ConditionPartlyCloudy Condition = "partly-cloudy"
+	ConditionCloudy       Condition = "cloudy"
+	ConditionFog          Condition = "fog"
+	ConditionRain         Condition = "rain"
+	ConditionSleet        Condition = "sleet"
+	ConditionSnow         Condition = "snow"
+	ConditionThunderstorm Condition = "thunderstorm"
+)
+
+// Offset of the night variants of the icons.
+const nightIconOffset = 100
+
+type icon struct {
+	description string
+	condition   Condition
+}
+
+// The icons as documented by MeteoSwiss.
+var icons = map[int]icon{
+	1:  {"sunny", ConditionClear},
+	2:  {"mostly sunny, some clouds", ConditionPartlyCloudy},
+	3:  {"partly sunny, thick passing clouds", ConditionPartlyCloudy},
+	4:  {"overcast", ConditionCloudy},
+	5:  {"very cloudy", ConditionCloudy},
+	6:  {"sunny intervals, isolated showers", ConditionRain},
+	7:  {"sunny intervals, isolated sleet", ConditionSleet},
+	8:  {"sunny intervals, snow showers", ConditionSnow},
+	9:  {"overcast, some rain showers", ConditionRain},
+	10: {"overcast, some sleet", ConditionSleet},
+	11: {"overcast, some snow showers", ConditionSnow},
+	12: {"sunny intervals, chance of thunderstorms", ConditionThunderstorm},
+	13: {"sunny intervals, possibility of thunderstorms", ConditionThunderstorm},
+	14: {"very cloudy, light rain", ConditionRain},
+	15: {"very cloudy, light sleet", ConditionSleet},
+	16: {"very cloudy, light snow showers", ConditionSnow},
+	17: {"very cloudy, intermittent rain", ConditionRain},
+	18: {"very cloudy, intermittent sleet", ConditionSleet},
+	19: {"very cloudy, intermittent snow", ConditionSnow},
+	20: {"very overcast with rain", ConditionRain},
+	21: {"very overcast with frequent sleet", ConditionSleet},
+	22: {"very overcast with heavy snow", ConditionSnow},
+	23: {"very overcast, slight chance of storms", ConditionThunderstorm},
+	24: {"very overcast with storms", ConditionThunderstorm},
+	25: {"very cloudy, very stormy", ConditionThunderstorm},
+	26: {"high clouds", ConditionPartlyCloudy},
+	27: {"stratus", ConditionCloudy},
+	28: {"fog", ConditionFog},
+	29: {"sunny intervals, scattered showers", ConditionRain},
+	30: {"sunny intervals, scattered snow showers", ConditionSnow},
+	31: {"sunny intervals, scattered sleet", ConditionSleet},
+	32: {"sunny intervals, some showers", ConditionRain},
+	33: {"short sunny intervals, frequent rain", ConditionRain},
+	34: {"short sunny intervals, frequent snowfalls", ConditionSnow},
+	35: {"overcast and dry", ConditionCloudy},
+}
+
+// Returns whether the icon is one of the night variants.
+func IsNightIcon(i int) bool {
+	return i > nightIconOffset
+}
+
+// Returns a short description of the weather icon.
+func IconDescription(i int) string {
+	if IsNightIcon(i) {
+		// The night variant of sunny is a clear sky
+		if i-nightIconOffset == 1 {
+			return "clear"
+		}
+
+		i -= nightIconOffset
+	}
+
+	if ic, ok := icons[i]; ok {
+		return ic.description
+	}
+
+	return "unknown"
+}
+
+// Returns the condition shown by the weather icon.
+func IconCondition(i int) Condition {
+	if IsNightIcon(i) {
+		i -= nightIconOffset
+	}
+
+	if ic, ok := icons[i]; ok {
+		return ic.condition
+	}
+
+	return ConditionCloudy
+}
+
+// Returns a symbol for the condition shown by the weather icon.
+func IconSymbol(i int) string {
+	switch IconCondition(i) {
+	case ConditionClear:
+		if IsNightIcon(i) {
+			return "☾"
+		}
+
+		return "☀"
+	case ConditionPartlyCloudy:
+		return "⛅"
+	case ConditionCloudy:
+		return "☁"
+	case ConditionFog:
+		return "≡"
+	case ConditionRain:
+		return "☂"
+	case ConditionSleet, ConditionSnow:
+		return "❄"
+	case ConditionThunderstorm:
+		return "⚡"
+	}
+
+	return "?"
+}
@@ -0,0 +1,50 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+import "testing"
+
+func TestIcons(t *testing.T) {
+	tests := []struct {
+		icon        int
+		description string
+		condition   Condition
+		symbol      string
+	}{
+		{1, "sunny", ConditionClear, "☀"},
+		{101, "clear", ConditionClear, "☾"},
+		{126, "high clouds", ConditionPartlyCloudy, "⛅"},
+		{17, "very cloudy, intermittent rain", ConditionRain, "☂"},
+		{0, "unknown", ConditionCloudy, "☁"},
+	}
+
+	for _, tt := range tests {
+		if got := IconDescription(tt.icon); got != tt.description {
+			t.Errorf("Expected description of icon %d to be %q, but got %q", tt.icon, tt.description, got)
+		}
+
+		if got := IconCondition(tt.icon); got != tt.condition {
+			t.Errorf("Expected condition of icon %d to be %q, but got %q", tt.icon, tt.condition, got)
+		}
+
+		if got := IconSymbol(tt.icon); got != tt.symbol {
+			t.Errorf("Expected symbol of icon %d to be %q, but got %q", tt.icon, tt.symbol, got)
+		}
+	}
+}
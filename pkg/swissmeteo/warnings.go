@@ -0,0 +1,60 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmeteo
+
+// Warning is a weather warning issued by MeteoSwiss.
+type Warning struct {
+	WarnType  int    `json:"warnType"`
+	WarnLevel int    `json:"warnLevel"`
+	Text      string `json:"text"`
+	ValidFrom int64  `json:"validFrom"`
+	ValidTo   int64  `json:"validTo"`
+	Ordering  string `json:"ordering"`
+	HTMLText  string `json:"htmlText"`
+	Links     []struct {
+		URL  string `json:"url"`
+		Text string `json:"text"`
+	} `json:"links"`
+	Outlook bool `json:"outlook"`
+}
+
+// The hazards MeteoSwiss issues warnings for, indexed by the warning type.
+var warningTypes = []string{
+	"wind",
+	"thunderstorms",
+	"rain",
+	"snow",
+	"slippery roads",
+	"frost",
+	"thaw",
+	"heat wave",
+	"avalanches",
+	"earthquakes",
+	"forest fires",
+	"flood",
+}
+
+// Returns the name of the hazard the warning is about.
+func (w Warning) Type() string {
+	if w.WarnType < 0 || w.WarnType >= len(warningTypes) {
+		return "unknown"
+	}
+
+	return warningTypes[w.WarnType]
+}
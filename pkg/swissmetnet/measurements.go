@@ -0,0 +1,130 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmetnet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Measurement holds the 10 minute values of a station. Values the station
+// doesn't measure or that are missing are nil.
+type Measurement struct {
+	// Abbreviation of the station, e.g. BER.
+	Station string    `json:"station"`
+	Time    time.Time `json:"time"`
+	// Air temperature 2 m above ground in °C.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// Relative humidity 2 m above ground in %.
+	Humidity *float64 `json:"humidity,omitempty"`
+	// Dew point 2 m above ground in °C.
+	DewPoint *float64 `json:"dewPoint,omitempty"`
+	// Pressure at station level (QFE) in hPa.
+	Pressure *float64 `json:"pressure,omitempty"`
+	// Pressure reduced to sea level (QFF) in hPa.
+	PressureSeaLevel *float64 `json:"pressureSeaLevel,omitempty"`
+	// Mean wind speed of 10 minutes in km/h.
+	WindSpeed *float64 `json:"windSpeed,omitempty"`
+	// Mean wind direction of 10 minutes in degrees.
+	WindDirection *float64 `json:"windDirection,omitempty"`
+	// Strongest gust of 10 minutes in km/h.
+	WindGust *float64 `json:"windGust,omitempty"`
+	// Precipitation of 10 minutes in mm.
+	Precipitation *float64 `json:"precipitation,omitempty"`
+	// Sunshine of 10 minutes in minutes.
+	Sunshine *float64 `json:"sunshine,omitempty"`
+	// Global radiation in W/m².
+	Radiation *float64 `json:"radiation,omitempty"`
+}
+
+// Gets the current measurements of all stations.
+func GetMeasurements(ctx context.Context) ([]Measurement, error) {
+	b, err := get(ctx, measurementsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMeasurements(bytes.NewReader(b))
+}
+
+// Parses the CSV of the current measurements.
+func ParseMeasurements(r io.Reader) ([]Measurement, error) {
+	rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements := make([]Measurement, 0, len(rows))
+
+	for _, row := range rows {
+		station := column(row, "Station/Location", "station_abbr")
+		if station == "" {
+			continue
+		}
+
+		// Times are in UTC
+		t, err := time.Parse("200601021504", column(row, "Date", "reference_timestamp"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid time of station %s: %w", station, err)
+		}
+
+		measurements = append(measurements, Measurement{
+			Station:          station,
+			Time:             t,
+			Temperature:      value(row, "tre200s0"),
+			Humidity:         value(row, "ure200s0"),
+			DewPoint:         value(row, "tde200s0"),
+			Pressure:         value(row, "prestas0"),
+			PressureSeaLevel: value(row, "pp0qffs0"),
+			WindSpeed:        value(row, "fu3010z0"),
+			WindDirection:    value(row, "dkl010z0"),
+			WindGust:         value(row, "fu3010z1"),
+			Precipitation:    value(row, "rre150z0"),
+			Sunshine:         value(row, "sre000z0"),
+			Radiation:        value(row, "gre000z0"),
+		})
+	}
+
+	return measurements, nil
+}
+
+// Returns the measurement of the station, or false if there is none.
+func Find(measurements []Measurement, station string) (Measurement, bool) {
+	for _, m := range measurements {
+		if m.Station == station {
+			return m, true
+		}
+	}
+
+	return Measurement{}, false
+}
+
+// Returns the value of the column, nil if it is missing or marked with a dash.
+func value(row map[string]string, name string) *float64 {
+	v, err := strconv.ParseFloat(row[name], 64)
+	if err != nil {
+		return nil
+	}
+
+	return &v
+}
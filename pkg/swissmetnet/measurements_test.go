@@ -0,0 +1,77 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmetnet
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMeasurements(t *testing.T) {
+	f, err := os.Open("testdata/VQHA80.csv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer f.Close()
+
+	measurements, err := ParseMeasurements(f)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(measurements) != 3 {
+		t.Fatalf("Expected 3 measurements, but got %d", len(measurements))
+	}
+
+	m, ok := Find(measurements, "BER")
+	if !ok {
+		t.Fatalf("Expected a measurement of BER")
+	}
+
+	expectedTime := time.Date(2023, 5, 7, 12, 50, 0, 0, time.UTC)
+	if !m.Time.Equal(expectedTime) {
+		t.Errorf("Expected time to be %s, but got %s", expectedTime, m.Time)
+	}
+
+	if *m.Temperature != 17.3 || *m.Humidity != 58.4 || *m.WindGust != 27.4 || *m.Pressure != 954.2 {
+		t.Errorf("Unexpected values %+v", m)
+	}
+
+	if *m.Sunshine != 10 || *m.Radiation != 612 {
+		t.Errorf("Expected 10 minutes of sunshine and 612 W/m², but got %.0f and %.0f", *m.Sunshine, *m.Radiation)
+	}
+
+	gve, _ := Find(measurements, "GVE")
+	if gve.Temperature != nil || gve.Humidity != nil {
+		t.Errorf("Expected missing values to be nil, but got %+v", gve)
+	}
+
+	if _, ok := Find(measurements, "XYZ"); ok {
+		t.Errorf("Expected no measurement of an unknown station")
+	}
+}
+
+func TestParseMeasurementsInvalidTime(t *testing.T) {
+	_, err := ParseMeasurements(strings.NewReader("Station/Location;Date;tre200s0\nBER;yesterday;17.3\n"))
+	if err == nil {
+		t.Errorf("Expected an error for an invalid time")
+	}
+}
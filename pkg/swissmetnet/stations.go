@@ -0,0 +1,119 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmetnet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Mean radius of the earth in km.
+const earthRadius = 6371.0
+
+// Station is an automatic weather station.
+type Station struct {
+	Name string `json:"name"`
+	// Abbreviation of the station, e.g. BER.
+	Abbr      string  `json:"abbr"`
+	Canton    string  `json:"canton"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// Height above sea level in m.
+	Altitude float64 `json:"altitude"`
+}
+
+// Gets the metadata of all stations.
+func GetStations(ctx context.Context) ([]Station, error) {
+	b, err := get(ctx, stationsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseStations(bytes.NewReader(b))
+}
+
+// Parses the CSV of the station metadata. Rows without coordinates, like the
+// notes at the end of the file, are skipped.
+func ParseStations(r io.Reader) ([]Station, error) {
+	rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	stations := make([]Station, 0, len(rows))
+
+	for _, row := range rows {
+		lat, errLat := strconv.ParseFloat(column(row, "Latitude", "station_coordinates_wgs84_lat"), 64)
+		lon, errLon := strconv.ParseFloat(column(row, "Longitude", "station_coordinates_wgs84_lon"), 64)
+
+		if errLat != nil || errLon != nil {
+			continue
+		}
+
+		altitude, _ := strconv.ParseFloat(column(row, "Station height m. a. sea level", "station_height_masl"), 64)
+
+		stations = append(stations, Station{
+			Name:      column(row, "Station", "station_name"),
+			Abbr:      column(row, "Abbr.", "station_abbr"),
+			Canton:    column(row, "Canton", "station_canton"),
+			Latitude:  lat,
+			Longitude: lon,
+			Altitude:  altitude,
+		})
+	}
+
+	return stations, nil
+}
+
+// Nearby is a station and its distance from a place.
+type Nearby struct {
+	Station
+	// Distance in km.
+	Distance float64 `json:"distance"`
+}
+
+// Returns the stations ordered by their distance from the coordinates, the
+// nearest first.
+func Nearest(stations []Station, lat float64, lon float64) []Nearby {
+	nearby := make([]Nearby, 0, len(stations))
+
+	for _, s := range stations {
+		nearby = append(nearby, Nearby{Station: s, Distance: Distance(lat, lon, s.Latitude, s.Longitude)})
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].Distance < nearby[j].Distance })
+
+	return nearby
+}
+
+// Returns the great circle distance between two coordinates in km.
+func Distance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,79 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package swissmetnet
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestParseStations(t *testing.T) {
+	f, err := os.Open("testdata/stations.csv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer f.Close()
+
+	stations, err := ParseStations(f)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(stations) != 3 {
+		t.Fatalf("Expected 3 stations, but got %d", len(stations))
+	}
+
+	// The file is Latin-1 encoded
+	if stations[1].Name != "Genève / Cointrin" || stations[1].Abbr != "GVE" || stations[1].Canton != "GE" {
+		t.Errorf("Unexpected station %+v", stations[1])
+	}
+
+	if stations[0].Latitude != 46.990744 || stations[0].Longitude != 7.464061 || stations[0].Altitude != 553 {
+		t.Errorf("Unexpected coordinates %+v", stations[0])
+	}
+}
+
+func TestNearest(t *testing.T) {
+	stations := []Station{
+		{Abbr: "GVE", Latitude: 46.247519, Longitude: 6.127742},
+		{Abbr: "SMA", Latitude: 47.377925, Longitude: 8.565742},
+		{Abbr: "BER", Latitude: 46.990744, Longitude: 7.464061},
+	}
+
+	// Geo point of 3006 Bern
+	nearby := Nearest(stations, 46.9461, 7.4693)
+
+	if nearby[0].Abbr != "BER" || nearby[2].Abbr != "GVE" {
+		t.Errorf("Expected BER to be nearest and GVE furthest, but got %+v", nearby)
+	}
+
+	if math.Abs(nearby[0].Distance-5) > 0.5 {
+		t.Errorf("Expected BER to be about 5 km away, but got %.1f km", nearby[0].Distance)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// Bern to Zürich as the crow flies
+	d := Distance(46.9480, 7.4474, 47.3769, 8.5417)
+
+	if math.Abs(d-95.5) > 1 {
+		t.Errorf("Expected about 95 km, but got %.1f km", d)
+	}
+}
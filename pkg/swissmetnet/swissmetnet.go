@@ -0,0 +1,128 @@
+/*
+Sunly
+Copyright (C) 2023 Dario Mader
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package swissmetnet reads the open data of the automatic weather stations of
+// MeteoSwiss, the SwissMetNet.
+package swissmetnet
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Current 10 minute values of all stations.
+	measurementsURL = "https://data.geo.admin.ch/ch.meteoschweiz.messwerte-aktuell/VQHA80.csv"
+	// Metadata of the stations.
+	stationsURL = "https://data.geo.admin.ch/ch.meteoschweiz.messnetz-automatisch/" +
+		"ch.meteoschweiz.messnetz-automatisch_en.csv"
+)
+
+// Gets the file at the URL. The request is cancelled when the given context is
+// done.
+func get(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching station data: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting station data from API: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from station API: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Reads the semicolon separated file into rows keyed by the column names of
+// the header.
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some of the files are Latin-1 encoded
+	if !utf8.Valid(b) {
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+
+		b = []byte(string(runes))
+	}
+
+	// Some of the files start with a byte order mark
+	cr := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(b), "\ufeff")))
+	cr.Comma = ';'
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV")
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+
+	for _, rec := range records[1:] {
+		row := map[string]string{}
+
+		for i, v := range rec {
+			if i < len(header) {
+				row[strings.TrimSpace(header[i])] = strings.TrimSpace(v)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// Returns the value of the first of the columns the row has.
+func column(row map[string]string, names ...string) string {
+	for _, n := range names {
+		if v, ok := row[n]; ok {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -32,14 +33,20 @@ const apiURL = "https://swisspost.opendatasoft.com/api/records/1.0/search/?datas
 	"verzeichnis_v2&&rows=50&facet=postleitzahl&facet=ortbez18&q=%s"
 
 func (l *LocationData) GetLocationDataByZip(zip string) (err error) {
+	return l.GetLocationDataByZipContext(context.Background(), zip)
+}
+
+// Same as GetLocationDataByZip, but the request is cancelled when the given
+// context is done.
+func (l *LocationData) GetLocationDataByZipContext(ctx context.Context, zip string) (err error) {
 	// Get the location data from the API
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(apiURL, zip), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(apiURL, url.QueryEscape(zip)), nil)
 
 	if err != nil {
 		err = fmt.Errorf("error fetching location: %w", err)
@@ -68,14 +75,20 @@ func (l *LocationData) GetLocationDataByZip(zip string) (err error) {
 }
 
 func (l *LocationData) GetLocationDataByName(name string) (err error) {
+	return l.GetLocationDataByNameContext(context.Background(), name)
+}
+
+// Same as GetLocationDataByName, but the request is cancelled when the given
+// context is done.
+func (l *LocationData) GetLocationDataByNameContext(ctx context.Context, name string) (err error) {
 	// Get the location data from the API
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(apiURL, name), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(apiURL, url.QueryEscape(name)), nil)
 
 	if err != nil {
 		err = fmt.Errorf("error fetching location: %w", err)